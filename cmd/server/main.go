@@ -13,12 +13,16 @@ import (
 	"github.com/caspianex/exchange-backend/internal/service"
 	"github.com/caspianex/exchange-backend/pkg/auth"
 	"github.com/caspianex/exchange-backend/pkg/cache"
+	"github.com/caspianex/exchange-backend/pkg/chain"
 	"github.com/caspianex/exchange-backend/pkg/config"
 	"github.com/caspianex/exchange-backend/pkg/database"
 	"github.com/caspianex/exchange-backend/pkg/email"
 	"github.com/caspianex/exchange-backend/pkg/logger"
+	"github.com/caspianex/exchange-backend/pkg/ratelimit"
+	"github.com/caspianex/exchange-backend/pkg/storage"
 	"github.com/caspianex/exchange-backend/pkg/worker"
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -41,10 +45,38 @@ func main() {
 	defer db.Close()
 	log.Info("Connected to database")
 
-	// Initialize in-memory cache
-	memCache := cache.NewMemoryCache(cache.NoExpiration, cache.NoExpiration) // No TTL
+	// Connect to Redis only when the cache or the rate limiter is configured to share data through it
+	var redisClient *redis.Client
+	if cfg.App.CacheBackend == "redis" || cfg.App.RateLimitBackend == "redis" {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:     cfg.GetRedisAddr(),
+			Password: cfg.Redis.Password,
+		})
+		defer redisClient.Close()
 
-	cacheService := cache.NewCacheService(memCache, log)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = redisClient.Ping(ctx).Err()
+		cancel()
+		if err != nil {
+			log.Error("Failed to connect to Redis", "error", err)
+			os.Exit(1)
+		}
+		log.Info("Connected to Redis")
+	}
+
+	// Initialize the cache, shared through Redis when several servers run
+	var cacheStore cache.Cache
+	switch cfg.App.CacheBackend {
+	case "memory":
+		cacheStore = cache.NewMemoryCache(cache.NoExpiration, cache.NoExpiration) // No TTL
+	case "redis":
+		cacheStore = cache.NewRedisCache(redisClient, "cache:")
+	default:
+		log.Error("Failed to initialize cache", "error", fmt.Errorf("unknown cache backend %q", cfg.App.CacheBackend))
+		os.Exit(1)
+	}
+
+	cacheService := cache.NewCacheService(cacheStore, log)
 
 	log.Info("Initialized cache service with write workers")
 
@@ -58,8 +90,25 @@ func main() {
 	}
 	cancel()
 
+	var jwtKeys *auth.KeySet
+	if cfg.JWT.KeysFile != "" {
+		jwtKeys, err = auth.LoadKeySet(cfg.JWT.KeysFile)
+		if err != nil {
+			log.Error("Failed to load JWT signing keys", "error", err)
+			os.Exit(1)
+		}
+		key, err := jwtKeys.Signing(time.Now())
+		if err != nil {
+			log.Error("Failed to load JWT signing keys", "error", err)
+			os.Exit(1)
+		}
+		log.Info("JWT signing key loaded", "kid", key.ID)
+	}
+
 	jwtManager := auth.NewJWTManager(
 		cfg.JWT.Secret,
+		cfg.JWT.Issuer,
+		jwtKeys,
 		cfg.JWT.AccessTokenExpiry,
 		cfg.JWT.RefreshTokenExpiry,
 	)
@@ -72,19 +121,97 @@ func main() {
 		cfg.Email.SMTPFrom,
 	)
 
+	apiKeyEncryptionKey := cfg.App.APIKeyEncryptionKey
+	if apiKeyEncryptionKey == "" {
+		apiKeyEncryptionKey = "api-key-encryption:" + cfg.JWT.Secret
+	}
+	apiKeySecrets, err := auth.NewSecretBox(apiKeyEncryptionKey)
+	if err != nil {
+		log.Error("Failed to initialize API key encryption", "error", err)
+		os.Exit(1)
+	}
+
+	rateLimitAlgorithm, err := ratelimit.ParseAlgorithm(cfg.App.RateLimitAlgorithm)
+	if err != nil {
+		log.Error("Failed to initialize rate limiting", "error", err)
+		os.Exit(1)
+	}
+
+	// Initialize rate limit counters, shared through Redis when several servers run
+	var rateLimitStore ratelimit.Store
+	switch cfg.App.RateLimitBackend {
+	case "memory":
+		rateLimitStore = ratelimit.NewMemoryStore()
+	case "redis":
+		rateLimitStore = ratelimit.NewRedisStore(redisClient, "ratelimit:")
+	default:
+		err = fmt.Errorf("unknown rate limit backend %q", cfg.App.RateLimitBackend)
+	}
+	if err != nil {
+		log.Error("Failed to initialize rate limiting", "error", err)
+		os.Exit(1)
+	}
+
+	// Initialize blob storage for uploaded documents
+	var blobStorage storage.BlobStorage
+	switch cfg.Storage.Driver {
+	case "local":
+		blobStorage, err = storage.NewLocalStorage(cfg.Storage.LocalPath)
+	default:
+		err = fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+	if err != nil {
+		log.Error("Failed to initialize storage", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize repositories with cache service
 	userRepo := repository.NewUserRepository(db, cacheService)
 	walletRepo := repository.NewWalletRepository(db, cacheService)
 	exchangeRepo := repository.NewCurrencyExchangeRepository(db, cacheService)
 	txRepo := repository.NewTransactionRepository(db)
 	exchangeRateRepo := repository.NewExchangeRateRepository(db, cacheService)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	depositAddressRepo := repository.NewDepositAddressRepository(db)
+	chainCursorRepo := repository.NewChainCursorRepository(db)
+	feeRuleRepo := repository.NewFeeRuleRepository(db)
+	quoteRepo := repository.NewExchangeQuoteRepository(db)
+	recurringExchangeRepo := repository.NewRecurringExchangeRepository(db)
+	kycRepo := repository.NewKYCRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db)
+	securityEventRepo := repository.NewSecurityEventRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	roleRepo := repository.NewRoleRepository(db, cacheService)
+	userAdminActionRepo := repository.NewUserAdminActionRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	loginFailureRepo := repository.NewLoginFailureRepository(db)
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, walletRepo, jwtManager, emailService, cfg.App.BcryptCost, log)
-	userService := service.NewUserService(userRepo, walletRepo)
-	walletService := service.NewWalletService(walletRepo, txRepo)
-	exchangeService := service.NewCurrencyExchangeService(exchangeRepo, walletRepo, userRepo, emailService)
-	exchangeRatesService := service.NewExchangeRatesService(exchangeRateRepo, log)
+	auditService := service.NewAuditService(auditLogRepo)
+	twoFactorService := service.NewTwoFactorService(db, userRepo, recoveryCodeRepo, cfg.App.TOTPIssuer)
+	sessionService := service.NewSessionService(db, userRepo, auditService)
+	roleService := service.NewRoleService(db, roleRepo, userRepo, userAdminActionRepo, auditService)
+	securityEventService := service.NewSecurityEventService(securityEventRepo)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo, twoFactorService, apiKeySecrets)
+	loginThrottle := service.NewLoginThrottle(db, loginFailureRepo, service.LoginLockoutPolicy{
+		MaxFailures:     cfg.App.LoginMaxFailures,
+		IPMaxFailures:   cfg.App.LoginIPMaxFailures,
+		LockoutDuration: cfg.App.LoginLockoutDuration,
+		FailureWindow:   cfg.App.LoginFailureWindow,
+	}, log)
+	authService := service.NewAuthService(db, userRepo, walletRepo, userTokenRepo, securityEventRepo, jwtManager, emailService, twoFactorService, loginThrottle, cfg.App.BcryptCost, cfg.App.FrontendURL, cfg.App.EmailVerificationTTL, cfg.App.PasswordResetTTL, log)
+	userService := service.NewUserService(db, userRepo, walletRepo, userAdminActionRepo, loginFailureRepo, roleService, auditService)
+	ledgerService := service.NewLedgerService(ledgerRepo)
+	feeService := service.NewFeeService(db, feeRuleRepo, walletRepo, userRepo, auditService)
+	kycService := service.NewKYCService(db, kycRepo, userRepo, walletRepo, txRepo, exchangeRepo, blobStorage, auditService, cfg.App.KYCMaxDocumentSize, log)
+	addressProvider := service.NewPoolAddressProvider(depositAddressRepo)
+	walletService := service.NewWalletService(db, walletRepo, txRepo, depositAddressRepo, addressProvider, ledgerService, feeService, kycService, twoFactorService, auditService)
+	exchangeService := service.NewCurrencyExchangeService(db, exchangeRepo, quoteRepo, walletRepo, userRepo, ledgerService, feeService, kycService, emailService, cfg.App.ExchangeQuoteTTL)
+	recurringExchangeService := service.NewRecurringExchangeService(db, recurringExchangeRepo, walletRepo, exchangeRepo, exchangeService, cfg.Worker.RecurringMaxFailures)
+	exchangeRatesService := service.NewExchangeRatesService(db, exchangeRateRepo, auditService, log)
+	chainDepositService := service.NewChainDepositService(db, walletRepo, txRepo, depositAddressRepo, chainCursorRepo, ledgerService)
 
 	wsService := NewWebSocketService(exchangeRatesService, log, cfg.WebSocket.AllowedOrigins, cfg.WebSocket.ReadBufferSize, cfg.WebSocket.WriteBufferSize)
 
@@ -96,7 +223,28 @@ func main() {
 		MaxRetries:     cfg.Worker.RateUpdateRetries,
 		RetryBackoff:   cfg.Worker.RateRetryBackoff,
 	}*/
-	rateUpdater := worker.NewRateUpdater(rateUpdaterConfig, exchangeRatesService, log)
+	rateUpdater := worker.NewRateUpdater(rateUpdaterConfig, exchangeRatesService, exchangeService, log)
+
+	// Initialize deposit watcher. Only in-memory chains exist so far; real node clients plug in here.
+	var chainClients []chain.ChainClient
+	mockChains := make(map[string]*chain.MemoryChain)
+	if cfg.Worker.ChainMock {
+		for network := range cfg.Worker.ChainConfirmations {
+			memoryChain := chain.NewMemoryChain(network)
+			mockChains[network] = memoryChain
+			chainClients = append(chainClients, memoryChain)
+		}
+		log.Warn("Chain watcher is using in-memory mock chains", "networks", len(mockChains))
+	}
+	chainWatcherConfig := worker.DefaultChainWatcherConfig()
+	chainWatcherConfig.PollInterval = cfg.Worker.ChainPollInterval
+	chainWatcherConfig.Confirmations = cfg.Worker.ChainConfirmations
+	chainWatcher := worker.NewChainWatcher(chainWatcherConfig, chainClients, chainDepositService, log)
+
+	// Initialize recurring exchange scheduler
+	recurringSchedulerConfig := worker.DefaultRecurringExchangeSchedulerConfig()
+	recurringSchedulerConfig.PollInterval = cfg.Worker.RecurringPollInterval
+	recurringScheduler := worker.NewRecurringExchangeScheduler(recurringSchedulerConfig, recurringExchangeService, log)
 
 	router := setupRouter(
 		cfg,
@@ -107,8 +255,25 @@ func main() {
 		userService,
 		walletService,
 		exchangeService,
+		recurringExchangeService,
 		exchangeRatesService,
+		ledgerService,
+		feeService,
+		kycService,
+		twoFactorService,
+		sessionService,
+		securityEventService,
+		apiKeyService,
+		roleService,
+		auditService,
+		rateLimitStore,
+		rateLimitAlgorithm,
+		idempotencyRepo,
+		cacheService,
+		mockChains,
 		rateUpdater,
+		chainWatcher,
+		recurringScheduler,
 	)
 
 	// Start background workers
@@ -116,6 +281,8 @@ func main() {
 	defer backgroundCancel()
 
 	rateUpdater.Start(backgroundCtx)
+	chainWatcher.Start(backgroundCtx)
+	recurringScheduler.Start(backgroundCtx)
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
@@ -148,6 +315,8 @@ func main() {
 		// Stop background workers first
 		log.Info("Stopping background workers")
 		rateUpdater.Stop()
+		chainWatcher.Stop()
+		recurringScheduler.Stop()
 
 		log.Info("Shutting down server")
 		if err := server.Shutdown(ctx); err != nil {
@@ -2,17 +2,23 @@ package main
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/caspianex/exchange-backend/internal/api/admin"
 	"github.com/caspianex/exchange-backend/internal/api/client"
 	"github.com/caspianex/exchange-backend/internal/api/health"
 	"github.com/caspianex/exchange-backend/internal/api/middleware"
+	"github.com/caspianex/exchange-backend/internal/api/wellknown"
+	"github.com/caspianex/exchange-backend/internal/domain"
 	"github.com/caspianex/exchange-backend/internal/service"
 	"github.com/caspianex/exchange-backend/pkg/auth"
+	"github.com/caspianex/exchange-backend/pkg/chain"
 	"github.com/caspianex/exchange-backend/pkg/config"
 	"github.com/caspianex/exchange-backend/pkg/logger"
+	"github.com/caspianex/exchange-backend/pkg/ratelimit"
 	"github.com/caspianex/exchange-backend/pkg/worker"
 	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
 )
 
 func setupRouter(
@@ -24,21 +30,41 @@ func setupRouter(
 	userService *service.UserService,
 	walletService *service.WalletService,
 	exchangeService *service.CurrencyExchangeService,
+	recurringExchangeService *service.RecurringExchangeService,
 	exchangeRateService *service.ExchangeRatesService,
+	ledgerService *service.LedgerService,
+	feeService *service.FeeService,
+	kycService *service.KYCService,
+	twoFactorService *service.TwoFactorService,
+	sessionService *service.SessionService,
+	securityEventService *service.SecurityEventService,
+	apiKeyService *service.APIKeyService,
+	roleService *service.RoleService,
+	auditService *service.AuditService,
+	rateLimitStore ratelimit.Store,
+	rateLimitAlgorithm ratelimit.Algorithm,
+	idempotencyStore middleware.IdempotencyStore,
+	sessionRevocations middleware.SessionRevocationList,
+	mockChains map[string]*chain.MemoryChain,
 	rateUpdater *worker.RateUpdater,
+	chainWatcher *worker.ChainWatcher,
+	recurringScheduler *worker.RecurringExchangeScheduler,
 ) http.Handler {
 	r := chi.NewRouter()
 
 	r.Get("/ws", wsService.handler)
 
 	// Health check endpoints
-	healthHandler := health.NewHealthHandler(rateUpdater)
+	healthHandler := health.NewHealthHandler(rateUpdater, chainWatcher, recurringScheduler)
 	r.Get("/health", healthHandler.Health)
 	r.Get("/health/detailed", healthHandler.HealthDetailed)
 	r.Get("/health/live", healthHandler.Live)
 	r.Get("/health/ready", healthHandler.Ready)
 
-	r.Mount("/api/v1", apiV1(cfg, log, jwtManager, authService, userService, walletService, exchangeService, exchangeRateService))
+	jwksHandler := wellknown.NewJWKSHandler(jwtManager)
+	r.Get("/.well-known/jwks.json", jwksHandler.JWKS)
+
+	r.Mount("/api/v1", apiV1(cfg, log, jwtManager, authService, userService, walletService, exchangeService, recurringExchangeService, exchangeRateService, ledgerService, feeService, kycService, twoFactorService, sessionService, securityEventService, apiKeyService, roleService, auditService, rateLimitStore, rateLimitAlgorithm, idempotencyStore, sessionRevocations, mockChains))
 
 	return r
 }
@@ -51,68 +77,210 @@ func apiV1(
 	userService *service.UserService,
 	walletService *service.WalletService,
 	exchangeService *service.CurrencyExchangeService,
+	recurringExchangeService *service.RecurringExchangeService,
 	exchangeRateService *service.ExchangeRatesService,
+	ledgerService *service.LedgerService,
+	feeService *service.FeeService,
+	kycService *service.KYCService,
+	twoFactorService *service.TwoFactorService,
+	sessionService *service.SessionService,
+	securityEventService *service.SecurityEventService,
+	apiKeyService *service.APIKeyService,
+	roleService *service.RoleService,
+	auditService *service.AuditService,
+	rateLimitStore ratelimit.Store,
+	rateLimitAlgorithm ratelimit.Algorithm,
+	idempotencyStore middleware.IdempotencyStore,
+	sessionRevocations middleware.SessionRevocationList,
+	mockChains map[string]*chain.MemoryChain,
 ) chi.Router {
 	r := chi.NewRouter()
 
-	authMiddleware := middleware.NewAuthMiddleware(jwtManager)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, sessionRevocations, roleService)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(apiKeyService)
+	idempotency := middleware.Idempotency(idempotencyStore, cfg.App.IdempotencyKeyTTL, log)
+
+	rateLimit := func(name string, requests int, window time.Duration, key middleware.RateLimitKeyFunc) func(http.Handler) http.Handler {
+		return middleware.RateLimit(rateLimitStore, middleware.RateLimitPolicy{
+			Name:  name,
+			Limit: ratelimit.Limit{Requests: requests, Window: window, Algorithm: rateLimitAlgorithm},
+			Key:   key,
+		}, log)
+	}
+	authLimit := rateLimit("auth", cfg.App.RateLimitAuthRequests, cfg.App.RateLimitAuthWindow, middleware.RateLimitByIP)
+	tradeLimit := rateLimit("trade", cfg.App.RateLimitTradeRequests, cfg.App.RateLimitTradeWindow, middleware.RateLimitByUser)
 
 	// 🔹 All middlewares are defined BEFORE routes on this subrouter
 	r.Use(middleware.Recovery)
+	if cfg.App.TrustProxyHeaders {
+		r.Use(chimiddleware.RealIP)
+	}
+	r.Use(middleware.RequestID)
 	r.Use(middleware.Logger(log))
 	r.Use(middleware.CORS(cfg.App.CORSAllowedOrigins))
+	r.Use(rateLimit("api", cfg.App.RateLimitRequests, cfg.App.RateLimitWindow, middleware.RateLimitByIP))
 
 	// Public authentication endpoints
 	authHandler := client.NewAuthHandler(authService)
-	r.Post("/auth/register", authHandler.Register)
-	r.Post("/auth/login", authHandler.Login)
+	r.With(authLimit).Post("/auth/register", authHandler.Register)
+	r.With(authLimit).Post("/auth/login", authHandler.Login)
+	r.With(authLimit).Post("/auth/login/2fa", authHandler.LoginMFA)
 	r.Post("/auth/refresh", authHandler.RefreshToken)
 	r.Post("/auth/logout", authHandler.Logout)
+	r.With(authLimit).Post("/auth/verify-email", authHandler.VerifyEmail)
+	r.With(authLimit).Post("/auth/resend-verification", authHandler.ResendVerification)
+	r.With(authLimit).Post("/auth/forgot-password", authHandler.ForgotPassword)
+	r.With(authLimit).Post("/auth/reset-password", authHandler.ResetPassword)
 
 	// Public exchange rates endpoint
 	exchangePairHandler := client.NewExchangePairHandler(exchangeRateService)
 	r.Get("/exchange-rates", exchangePairHandler.GetActiveRates)
 
-	// Protected client endpoints
+	// Client endpoints open to API keys as well as bearer tokens. Each route names the
+	// scope a key needs; bearer tokens are not limited by scopes.
 	r.Group(func(r chi.Router) {
-		r.Use(authMiddleware.Authenticate)
+		r.Use(middleware.APIKeyOrBearer(apiKeyMiddleware.Authenticate, authMiddleware.Authenticate))
+
+		read := middleware.RequireAPIKeyScope(domain.APIKeyScopeRead)
+		trade := middleware.RequireAPIKeyScope(domain.APIKeyScopeTrade)
+		withdraw := middleware.RequireAPIKeyScope(domain.APIKeyScopeWithdraw)
 
 		walletHandler := client.NewWalletHandler(walletService)
-		r.Get("/wallet/currencies", walletHandler.GetAllCurrencies)
-		r.Get("/wallets", walletHandler.GetWallets)
-		r.Post("/wallets/deposit", walletHandler.Deposit)
-		r.Post("/wallets/withdraw", walletHandler.Withdraw)
-		r.Get("/transactions", walletHandler.GetTransactions)
+		r.With(read).Get("/wallet/currencies", walletHandler.GetAllCurrencies)
+		r.With(read).Get("/wallets", walletHandler.GetWallets)
+		r.With(read).Get("/wallets/{currency}/deposit-address", walletHandler.GetDepositAddress)
+		r.With(withdraw, tradeLimit, idempotency).Post("/wallets/withdraw", walletHandler.Withdraw)
+		r.With(read).Get("/transactions", walletHandler.GetTransactions)
 
 		exchangeHandler := client.NewExchangeHandler(exchangeService)
-		r.Post("/exchanges", exchangeHandler.CreateExchange)
-		r.Get("/exchanges", exchangeHandler.GetExchanges)
-		r.Get("/exchanges/{id}", exchangeHandler.GetExchange)
-		r.Delete("/exchanges/{id}", exchangeHandler.CancelExchange)
+		r.With(trade, tradeLimit, idempotency).Post("/exchanges", exchangeHandler.CreateExchange)
+		r.With(trade, tradeLimit).Post("/exchanges/quotes", exchangeHandler.CreateQuote)
+		r.With(read).Get("/exchanges", exchangeHandler.GetExchanges)
+		r.With(read).Get("/exchanges/{id}", exchangeHandler.GetExchange)
+		r.With(trade).Delete("/exchanges/{id}", exchangeHandler.CancelExchange)
+
+		recurringExchangeHandler := client.NewRecurringExchangeHandler(recurringExchangeService)
+		r.With(read).Get("/recurring-exchanges", recurringExchangeHandler.List)
+		r.With(trade).Post("/recurring-exchanges", recurringExchangeHandler.Create)
+		r.With(read).Get("/recurring-exchanges/{id}", recurringExchangeHandler.Get)
+		r.With(trade).Put("/recurring-exchanges/{id}", recurringExchangeHandler.Update)
+		r.With(trade).Delete("/recurring-exchanges/{id}", recurringExchangeHandler.Cancel)
+		r.With(trade).Post("/recurring-exchanges/{id}/pause", recurringExchangeHandler.Pause)
+		r.With(trade).Post("/recurring-exchanges/{id}/resume", recurringExchangeHandler.Resume)
+		r.With(read).Get("/recurring-exchanges/{id}/runs", recurringExchangeHandler.GetRuns)
 	})
 
-	// Admin endpoints
+	// Account endpoints, only for signed-in users
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware.Authenticate)
+
+		kycHandler := client.NewKYCHandler(kycService)
+		r.Get("/kyc", kycHandler.GetStatus)
+		r.Post("/kyc/documents", kycHandler.UploadDocument)
+		r.Delete("/kyc/documents/{id}", kycHandler.DeleteDocument)
+		r.Post("/kyc/applications", kycHandler.SubmitApplication)
+
+		twoFactorHandler := client.NewTwoFactorHandler(twoFactorService)
+		r.Get("/2fa", twoFactorHandler.GetStatus)
+		r.Post("/2fa/setup", twoFactorHandler.Setup)
+		r.Post("/2fa/enable", twoFactorHandler.Enable)
+		r.Post("/2fa/disable", twoFactorHandler.Disable)
+		r.Post("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+
+		sessionHandler := client.NewSessionHandler(sessionService)
+		r.Get("/sessions", sessionHandler.List)
+		r.Delete("/sessions", sessionHandler.RevokeAll)
+		r.Delete("/sessions/{id}", sessionHandler.Revoke)
+
+		apiKeyHandler := client.NewAPIKeyHandler(apiKeyService)
+		r.Get("/api-keys", apiKeyHandler.List)
+		r.Post("/api-keys", apiKeyHandler.Create)
+		r.Delete("/api-keys/{id}", apiKeyHandler.Revoke)
+	})
+
+	// Admin endpoints. Each route declares the permission it needs.
 	r.Route("/admin", func(r chi.Router) {
 		r.Use(authMiddleware.Authenticate)
-		r.Use(authMiddleware.RequireRole("admin"))
+		can := authMiddleware.RequirePermission
 
 		userHandler := admin.NewUserHandler(userService)
-		r.Get("/users", userHandler.ListUsers)
-		r.Get("/users/{id}", userHandler.GetUser)
+		r.With(can(domain.PermissionUsersRead)).Get("/users", userHandler.ListUsers)
+		r.With(can(domain.PermissionUsersRead)).Get("/users/{id}", userHandler.GetUser)
+		r.With(can(domain.PermissionUsersWrite)).Put("/users/{id}", userHandler.UpdateUser)
+		r.With(can(domain.PermissionUsersWrite)).Put("/users/{id}/fee-tier", userHandler.SetFeeTier)
+		r.With(can(domain.PermissionUsersWrite)).Post("/users/{id}/deactivate", userHandler.DeactivateUser)
+		r.With(can(domain.PermissionUsersWrite)).Post("/users/{id}/activate", userHandler.ActivateUser)
+		r.With(can(domain.PermissionUsersWrite)).Post("/users/{id}/verify", userHandler.MarkVerified)
+		r.With(can(domain.PermissionUsersWrite)).Post("/users/{id}/logout", userHandler.ForceLogout)
+		r.With(can(domain.PermissionUsersWrite)).Post("/users/{id}/unlock", userHandler.UnlockUser)
+		r.With(can(domain.PermissionUsersRead)).Get("/users/{id}/actions", userHandler.ListActions)
+
+		roleHandler := admin.NewRoleHandler(roleService)
+		r.With(can(domain.PermissionRolesManage)).Get("/permissions", roleHandler.ListPermissions)
+		r.With(can(domain.PermissionRolesManage)).Get("/roles", roleHandler.ListRoles)
+		r.With(can(domain.PermissionRolesManage)).Post("/roles", roleHandler.CreateRole)
+		r.With(can(domain.PermissionRolesManage)).Put("/roles/{name}", roleHandler.UpdateRole)
+		r.With(can(domain.PermissionRolesManage)).Delete("/roles/{name}", roleHandler.DeleteRole)
+		r.With(can(domain.PermissionRolesManage)).Put("/users/{id}/role", roleHandler.AssignRole)
+
+		sessionHandler := admin.NewSessionHandler(sessionService)
+		r.With(can(domain.PermissionUsersRead)).Get("/users/{id}/sessions", sessionHandler.ListUserSessions)
+		r.With(can(domain.PermissionUsersWrite)).Delete("/users/{id}/sessions", sessionHandler.RevokeUserSessions)
+		r.With(can(domain.PermissionUsersWrite)).Delete("/users/{id}/sessions/{sessionId}", sessionHandler.RevokeUserSession)
+
+		securityEventHandler := admin.NewSecurityEventHandler(securityEventService)
+		r.With(can(domain.PermissionSecurityEventsRead)).Get("/security-events", securityEventHandler.ListEvents)
+
+		auditLogHandler := admin.NewAuditLogHandler(auditService)
+		r.With(can(domain.PermissionAuditLogRead)).Get("/audit-log", auditLogHandler.ListEntries)
+		r.With(can(domain.PermissionAuditLogRead)).Get("/audit-log/verify", auditLogHandler.VerifyChain)
 
 		exchangeHandler := admin.NewExchangeHandler(exchangeService)
-		r.Get("/exchanges", exchangeHandler.ListExchanges)
-		r.Get("/exchanges/{id}", exchangeHandler.GetExchange)
+		r.With(can(domain.PermissionExchangesRead)).Get("/exchanges", exchangeHandler.ListExchanges)
+		r.With(can(domain.PermissionExchangesRead)).Get("/exchanges/{id}", exchangeHandler.GetExchange)
+		r.With(can(domain.PermissionExchangesRead)).Get("/exchange-quotes/stats", exchangeHandler.GetQuoteStats)
 
 		rateHandler := admin.NewExchangeRatesHandler(exchangeRateService)
-		r.Get("/exchange-rates", rateHandler.GetAllRates)
-		r.Get("/exchange-rates/{id}", rateHandler.GetRate)
-		r.Post("/exchange-rates", rateHandler.CreateRate)
-		r.Put("/exchange-rates/{id}", rateHandler.UpdateRate)
-		r.Delete("/exchange-rates/{id}", rateHandler.DeleteRate)
+		r.With(can(domain.PermissionExchangeRatesRead)).Get("/exchange-rates", rateHandler.GetAllRates)
+		r.With(can(domain.PermissionExchangeRatesRead)).Get("/exchange-rates/{id}", rateHandler.GetRate)
+		r.With(can(domain.PermissionExchangeRatesWrite)).Post("/exchange-rates", rateHandler.CreateRate)
+		r.With(can(domain.PermissionExchangeRatesWrite)).Put("/exchange-rates/{id}", rateHandler.UpdateRate)
+		r.With(can(domain.PermissionExchangeRatesWrite)).Delete("/exchange-rates/{id}", rateHandler.DeleteRate)
 
 		walletHandler := admin.NewWalletHandler(walletService)
-		r.Post("/wallets/deposit", walletHandler.ManualDeposit)
+		r.With(can(domain.PermissionDepositsCreate), idempotency).Post("/wallets/deposit", walletHandler.ManualDeposit)
+		r.With(can(domain.PermissionDepositAddressesImport)).Post("/deposit-addresses", walletHandler.ImportDepositAddresses)
+		r.With(can(domain.PermissionWithdrawalsRead)).Get("/withdrawals", walletHandler.ListWithdrawals)
+		r.With(can(domain.PermissionWithdrawalsManage)).Post("/withdrawals/{id}/approve", walletHandler.ApproveWithdrawal)
+		r.With(can(domain.PermissionWithdrawalsManage)).Post("/withdrawals/{id}/reject", walletHandler.RejectWithdrawal)
+		r.With(can(domain.PermissionWithdrawalsManage)).Post("/withdrawals/{id}/mark-sent", walletHandler.MarkWithdrawalSent)
+
+		feeRuleHandler := admin.NewFeeRuleHandler(feeService)
+		r.With(can(domain.PermissionFeeRulesRead)).Get("/fee-rules", feeRuleHandler.ListRules)
+		r.With(can(domain.PermissionFeeRulesRead)).Get("/fee-rules/{id}", feeRuleHandler.GetRule)
+		r.With(can(domain.PermissionFeeRulesWrite)).Post("/fee-rules", feeRuleHandler.CreateRule)
+		r.With(can(domain.PermissionFeeRulesWrite)).Put("/fee-rules/{id}", feeRuleHandler.UpdateRule)
+		r.With(can(domain.PermissionFeeRulesWrite)).Delete("/fee-rules/{id}", feeRuleHandler.DeleteRule)
+
+		kycHandler := admin.NewKYCHandler(kycService)
+		r.With(can(domain.PermissionKYCRead)).Get("/kyc/applications", kycHandler.ListApplications)
+		r.With(can(domain.PermissionKYCRead)).Get("/kyc/applications/{id}", kycHandler.GetApplication)
+		r.With(can(domain.PermissionKYCReview)).Post("/kyc/applications/{id}/approve", kycHandler.ApproveApplication)
+		r.With(can(domain.PermissionKYCReview)).Post("/kyc/applications/{id}/reject", kycHandler.RejectApplication)
+		r.With(can(domain.PermissionKYCRead)).Get("/kyc/documents/{id}/file", kycHandler.GetDocumentFile)
+		r.With(can(domain.PermissionKYCRead)).Get("/kyc/limits", kycHandler.ListLimits)
+		r.With(can(domain.PermissionKYCLimitsWrite)).Put("/kyc/limits", kycHandler.SetLimit)
+
+		ledgerHandler := admin.NewLedgerHandler(ledgerService)
+		r.With(can(domain.PermissionLedgerRead)).Get("/ledger/reconciliation", ledgerHandler.Reconcile)
+		r.With(can(domain.PermissionLedgerRead)).Get("/ledger/journals/{id}", ledgerHandler.GetJournal)
+		r.With(can(domain.PermissionLedgerRead)).Get("/ledger/wallets/{id}/postings", ledgerHandler.GetWalletPostings)
+
+		if len(mockChains) > 0 {
+			chainMockHandler := admin.NewChainMockHandler(mockChains)
+			r.With(can(domain.PermissionChainMock)).Post("/chain-mock/{network}/transfers", chainMockHandler.Send)
+			r.With(can(domain.PermissionChainMock)).Post("/chain-mock/{network}/mine", chainMockHandler.Mine)
+		}
 	})
 
 	return r
@@ -0,0 +1,47 @@
+package queries
+
+const (
+	APIKeyCreateQuery = `
+		INSERT INTO api_keys (user_id, name, key_id, secret_encrypted, scopes, allowed_ips)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+`
+
+	APIKeyGetByKeyIDQuery = `SELECT * FROM api_keys WHERE key_id = $1`
+
+	APIKeyListByUserQuery = `
+		SELECT * FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC, id DESC
+`
+
+	APIKeyCountActiveByUserQuery = `
+		SELECT COUNT(*) FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL
+`
+
+	APIKeyRevokeQuery = `
+		UPDATE api_keys SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+`
+
+	APIKeyTouchQuery = `
+		UPDATE api_keys SET last_used_at = NOW(), last_used_ip = $2
+		WHERE id = $1
+`
+
+	// APIKeyNonceUseQuery records a nonce, or takes over an expired one.
+	// No row is affected when the nonce was already used.
+	APIKeyNonceUseQuery = `
+		INSERT INTO api_key_nonces (api_key_id, nonce, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (api_key_id, nonce) DO UPDATE
+		SET expires_at = EXCLUDED.expires_at
+		WHERE api_key_nonces.expires_at < NOW()
+`
+
+	APIKeyNonceDeleteExpiredQuery = `
+		DELETE FROM api_key_nonces
+		WHERE api_key_id = $1 AND expires_at < NOW() AND nonce <> $2
+`
+)
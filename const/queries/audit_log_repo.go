@@ -0,0 +1,21 @@
+package queries
+
+const (
+	// AuditLogLockQuery serializes appends so each entry chains to the last committed one
+	AuditLogLockQuery = `SELECT pg_advisory_xact_lock(hashtext('audit_log'))`
+
+	AuditLogLastHashQuery = `SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`
+
+	AuditLogCreateQuery = `
+		INSERT INTO audit_log (actor_id, action, target_type, target_id, before_state, after_state,
+			ip_address, request_id, prev_hash, hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+`
+
+	AuditLogListBaseQuery = `SELECT * FROM audit_log`
+
+	AuditLogCountBaseQuery = `SELECT COUNT(*) FROM audit_log`
+
+	AuditLogListAfterQuery = `SELECT * FROM audit_log WHERE id > $1 ORDER BY id ASC LIMIT $2`
+)
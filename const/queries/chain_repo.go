@@ -0,0 +1,12 @@
+package queries
+
+const (
+	ChainCursorGetQuery = `SELECT last_block FROM chain_cursors WHERE network = $1`
+
+	ChainCursorSetQuery = `
+		INSERT INTO chain_cursors (network, last_block)
+		VALUES ($1, $2)
+		ON CONFLICT (network) DO UPDATE
+		SET last_block = EXCLUDED.last_block, updated_at = NOW()
+`
+)
@@ -0,0 +1,43 @@
+package queries
+
+const (
+	DepositAddressGetByUserAndCurrencyQuery = `
+		SELECT * FROM deposit_addresses
+		WHERE user_id = $1 AND currency_id = $2
+`
+
+	DepositAddressGetByAddressQuery = `
+		SELECT * FROM deposit_addresses
+		WHERE network = $1 AND address = $2
+`
+
+	// Concurrent allocations skip rows another transaction is handing out
+	DepositAddressGetAllocatedByNetworkQuery = `
+		SELECT address FROM deposit_addresses
+		WHERE network = $1 AND user_id IS NOT NULL
+`
+
+	DepositAddressAllocateFromPoolQuery = `
+		UPDATE deposit_addresses
+		SET user_id = $1, allocated_at = NOW()
+		WHERE id = (
+			SELECT id FROM deposit_addresses
+			WHERE currency_id = $2 AND user_id IS NULL
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+`
+
+	DepositAddressAddToPoolQuery = `
+		INSERT INTO deposit_addresses (currency_id, network, address)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (network, address) DO NOTHING
+`
+
+	DepositAddressCountFreeQuery = `
+		SELECT COUNT(*) FROM deposit_addresses
+		WHERE currency_id = $1 AND user_id IS NULL
+`
+)
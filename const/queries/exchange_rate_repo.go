@@ -4,14 +4,14 @@ const (
 	ExchangeRateWithCurrenciesQuery = `
 		SELECT
 			er.id, er.from_currency_id, er.to_currency_id, er.rate, er.fee, er.is_active,
-			er.created_at, er.updated_at,
+			er.min_amount, er.max_amount, er.daily_limit, er.amount_precision, er.created_at, er.updated_at,
 			bc.id as "from_currency.id", bc.code as "from_currency.code",
 			bc.name as "from_currency.name", bc.symbol as "from_currency.symbol",
-			bc.is_active as "from_currency.is_active", bc.is_crypto as "from_currency.is_crypto",
+			bc.is_active as "from_currency.is_active", bc.is_crypto as "from_currency.is_crypto", bc.decimals as "from_currency.decimals",
 			bc.created_at as "from_currency.created_at", bc.updated_at as "from_currency.updated_at",
 			qc.id as "to_currency.id", qc.code as "to_currency.code",
 			qc.name as "to_currency.name", qc.symbol as "to_currency.symbol",
-			qc.is_active as "to_currency.is_active", qc.is_crypto as "to_currency.is_crypto",
+			qc.is_active as "to_currency.is_active", qc.is_crypto as "to_currency.is_crypto", qc.decimals as "to_currency.decimals",
 			qc.created_at as "to_currency.created_at", qc.updated_at as "to_currency.updated_at"
 		FROM exchange_rates er
 		JOIN currencies bc ON er.from_currency_id = bc.id
@@ -20,8 +20,11 @@ const (
 `
 
 	ExchangeRateCreateQuery = `
-		INSERT INTO exchange_rates (from_currency_id, to_currency_id, rate, fee, is_active)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO exchange_rates (
+			from_currency_id, to_currency_id, rate, fee, is_active,
+			min_amount, max_amount, daily_limit, amount_precision
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at, updated_at
 `
 
@@ -32,14 +35,14 @@ const (
 	ExchangeRateGetActiveQuery = `
 		SELECT
 			ep.id, ep.from_currency_id, ep.to_currency_id, ep.rate, ep.fee, ep.is_active,
-			ep.created_at, ep.updated_at,
+			ep.min_amount, ep.max_amount, ep.daily_limit, ep.amount_precision, ep.created_at, ep.updated_at,
 			bc.id as "from_currency.id", bc.code as "from_currency.code",
 			bc.name as "from_currency.name", bc.symbol as "from_currency.symbol",
-			bc.is_active as "from_currency.is_active", bc.is_crypto as "from_currency.is_crypto",
+			bc.is_active as "from_currency.is_active", bc.is_crypto as "from_currency.is_crypto", bc.decimals as "from_currency.decimals",
 			bc.created_at as "from_currency.created_at", bc.updated_at as "from_currency.updated_at",
 			qc.id as "to_currency.id", qc.code as "to_currency.code",
 			qc.name as "to_currency.name", qc.symbol as "to_currency.symbol",
-			qc.is_active as "to_currency.is_active", qc.is_crypto as "to_currency.is_crypto",
+			qc.is_active as "to_currency.is_active", qc.is_crypto as "to_currency.is_crypto", qc.decimals as "to_currency.decimals",
 			qc.created_at as "to_currency.created_at", qc.updated_at as "to_currency.updated_at"
 		FROM exchange_rates ep
 		JOIN currencies bc ON ep.from_currency_id = bc.id
@@ -50,8 +53,9 @@ const (
 
 	ExchangeRateUpdateQuery = `
 		UPDATE exchange_rates
-		SET fee = $1, is_active = $2, updated_at = NOW()
-		WHERE id = $3
+		SET fee = $1, is_active = $2, min_amount = $3, max_amount = $4, daily_limit = $5,
+		    amount_precision = $6, updated_at = NOW()
+		WHERE id = $7
 		RETURNING updated_at
 `
 
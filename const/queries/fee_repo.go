@@ -0,0 +1,33 @@
+package queries
+
+const (
+	FeeRuleCreateQuery = `
+		INSERT INTO fee_rules (
+			name, operation, currency_id, network, from_currency_id, to_currency_id, user_tier,
+			min_amount, max_amount, type, percent, flat_amount, min_fee, max_fee, tiers, priority, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING id, is_active, created_at
+`
+
+	FeeRuleGetByIDQuery = `SELECT * FROM fee_rules WHERE id = $1`
+
+	FeeRuleLockByIDQuery = `SELECT * FROM fee_rules WHERE id = $1 FOR UPDATE`
+
+	FeeRuleGetActiveByOperationQuery = `
+		SELECT * FROM fee_rules
+		WHERE operation = $1 AND is_active = true
+`
+
+	FeeRuleRetireQuery = `
+		UPDATE fee_rules
+		SET is_active = false, replaced_by = $1, retired_at = NOW()
+		WHERE id = $2
+		RETURNING retired_at
+`
+
+	// Base queries for queryBuilder
+	FeeRuleListBaseQuery = `SELECT * FROM fee_rules`
+
+	FeeRuleCountBaseQuery = `SELECT COUNT(*) FROM fee_rules`
+)
@@ -0,0 +1,32 @@
+package queries
+
+const (
+	// IdempotencyKeyAcquireQuery claims a key, or takes over an expired one.
+	// No row is returned when the key is held by a live request.
+	IdempotencyKeyAcquireQuery = `
+		INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, idempotency_key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+			response_status = NULL,
+			response_body = NULL,
+			created_at = NOW(),
+			completed_at = NULL,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < NOW()
+		RETURNING id, created_at
+`
+
+	IdempotencyKeyGetByUserAndKeyQuery = `
+		SELECT * FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2
+`
+
+	IdempotencyKeyCompleteQuery = `
+		UPDATE idempotency_keys
+		SET response_status = $1, response_body = $2, completed_at = NOW()
+		WHERE id = $3
+`
+
+	IdempotencyKeyDeleteQuery = `DELETE FROM idempotency_keys WHERE id = $1`
+)
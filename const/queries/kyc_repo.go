@@ -0,0 +1,89 @@
+package queries
+
+const (
+	KYCApplicationCreateQuery = `
+		INSERT INTO kyc_applications (user_id, level, status)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+`
+
+	KYCApplicationGetByIDQuery = `SELECT * FROM kyc_applications WHERE id = $1`
+
+	KYCApplicationLockByIDQuery = `SELECT * FROM kyc_applications WHERE id = $1 FOR UPDATE`
+
+	KYCApplicationGetLatestByUserQuery = `
+		SELECT * FROM kyc_applications
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+`
+
+	// Oldest first, so the review queue is worked in submission order
+	KYCApplicationListQuery = `
+		SELECT * FROM kyc_applications
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at
+		LIMIT $2 OFFSET $3
+`
+
+	KYCApplicationCountQuery = `SELECT COUNT(*) FROM kyc_applications WHERE ($1 = '' OR status = $1)`
+
+	KYCApplicationReviewQuery = `
+		UPDATE kyc_applications
+		SET status = $1, reason = $2, reviewed_by = $3, reviewed_at = NOW(), updated_at = NOW()
+		WHERE id = $4
+		RETURNING reviewed_at, updated_at
+`
+
+	KYCDocumentCreateQuery = `
+		INSERT INTO kyc_documents (user_id, type, storage_key, file_name, content_type, size_bytes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+`
+
+	KYCDocumentGetByIDQuery = `SELECT * FROM kyc_documents WHERE id = $1`
+
+	KYCDocumentGetUnsubmittedQuery = `
+		SELECT * FROM kyc_documents
+		WHERE user_id = $1 AND application_id IS NULL
+		ORDER BY created_at
+`
+
+	KYCDocumentGetByApplicationQuery = `SELECT * FROM kyc_documents WHERE application_id = $1 ORDER BY created_at`
+
+	KYCDocumentAttachQuery = `
+		UPDATE kyc_documents
+		SET application_id = $1
+		WHERE user_id = $2 AND application_id IS NULL
+`
+
+	KYCDocumentDeleteUnsubmittedQuery = `
+		DELETE FROM kyc_documents
+		WHERE id = $1 AND user_id = $2 AND application_id IS NULL
+		RETURNING storage_key
+`
+
+	kycLimitSelect = `
+		SELECT l.*, c.code AS currency_code
+		FROM kyc_limits l
+		JOIN currencies c ON c.id = l.currency_id
+`
+
+	KYCLimitListQuery = kycLimitSelect + `ORDER BY l.level, c.code`
+
+	KYCLimitGetByLevelQuery = kycLimitSelect + `WHERE l.level = $1 ORDER BY c.code`
+
+	KYCLimitGetQuery = kycLimitSelect + `WHERE l.level = $1 AND l.currency_id = $2`
+
+	KYCLimitLockQuery = kycLimitSelect + `WHERE l.level = $1 AND l.currency_id = $2 FOR UPDATE OF l`
+
+	KYCLimitUpsertQuery = `
+		INSERT INTO kyc_limits (level, currency_id, daily_withdrawal_limit, daily_exchange_limit)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (level, currency_id) DO UPDATE
+		SET daily_withdrawal_limit = EXCLUDED.daily_withdrawal_limit,
+		    daily_exchange_limit = EXCLUDED.daily_exchange_limit,
+		    updated_at = NOW()
+		RETURNING updated_at
+`
+)
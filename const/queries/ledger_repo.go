@@ -0,0 +1,40 @@
+package queries
+
+const (
+	LedgerJournalCreateQuery = `
+		INSERT INTO ledger_journals (uid, type, reference_type, reference_id, description)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+`
+
+	LedgerPostingCreateQuery = `
+		INSERT INTO ledger_postings (journal_id, account, wallet_id, currency_id, amount)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+`
+
+	LedgerJournalGetByIDQuery = `SELECT * FROM ledger_journals WHERE id = $1`
+
+	LedgerPostingsGetByJournalQuery = `SELECT * FROM ledger_postings WHERE journal_id = $1 ORDER BY id`
+
+	LedgerPostingsGetByWalletQuery = `
+		SELECT * FROM ledger_postings
+		WHERE wallet_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+`
+
+	LedgerAccountBalanceQuery = `SELECT COALESCE(SUM(amount), 0) FROM ledger_postings WHERE account = $1`
+
+	LedgerReconcileWalletsQuery = `
+		SELECT w.id as wallet_id, w.user_id, w.currency_id, w.balance, w.locked,
+		       COALESCE(SUM(p.amount) FILTER (WHERE p.account = 'wallet:' || w.id), 0) as ledger_balance,
+		       COALESCE(SUM(p.amount) FILTER (WHERE p.account = 'wallet:' || w.id || ':locked'), 0) as ledger_locked
+		FROM wallets w
+		LEFT JOIN ledger_postings p ON p.wallet_id = w.id
+		GROUP BY w.id
+		HAVING w.balance <> COALESCE(SUM(p.amount) FILTER (WHERE p.account = 'wallet:' || w.id), 0)
+		    OR w.locked <> COALESCE(SUM(p.amount) FILTER (WHERE p.account = 'wallet:' || w.id || ':locked'), 0)
+		ORDER BY w.id
+`
+)
@@ -0,0 +1,64 @@
+package queries
+
+const (
+	// LoginFailureEnsureQuery adds an empty run so the row exists to be locked
+	LoginFailureEnsureQuery = `
+		INSERT INTO login_failures (scope, subject, failures, last_failure_at)
+		VALUES ($1, $2, 0, $3)
+		ON CONFLICT (scope, subject) DO NOTHING
+`
+
+	LoginFailureGetForUpdateQuery = `
+		SELECT * FROM login_failures
+		WHERE scope = $1 AND subject = $2
+		FOR UPDATE
+`
+
+	LoginFailureUpdateQuery = `
+		UPDATE login_failures SET failures = $3, last_failure_at = $4, locked_until = $5
+		WHERE scope = $1 AND subject = $2
+`
+
+	// LoginFailureLockIfReachedQuery locks the subject once its run reached $3 failures and starts
+	// the count over, so another run of failures is needed to lock again once the lock ends
+	LoginFailureLockIfReachedQuery = `
+		UPDATE login_failures SET locked_until = $4, failures = 0
+		WHERE scope = $1 AND subject = $2 AND failures >= $3
+		RETURNING failures
+`
+
+	// LoginFailureReleaseQuery gives back an attempt that was reserved but did not fail
+	LoginFailureReleaseQuery = `
+		UPDATE login_failures SET failures = GREATEST(failures - 1, 0)
+		WHERE scope = $1 AND subject = $2
+`
+
+	// LoginFailureRecordQuery counts a failure. A run whose last failure is older than $4 starts over,
+	// dropping any lock it had.
+	LoginFailureRecordQuery = `
+		INSERT INTO login_failures (scope, subject, failures, last_failure_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (scope, subject) DO UPDATE SET
+			failures = CASE WHEN login_failures.last_failure_at < $4 THEN 1 ELSE login_failures.failures + 1 END,
+			locked_until = CASE WHEN login_failures.last_failure_at < $4 THEN NULL ELSE login_failures.locked_until END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING *
+`
+
+	// LoginFailureLockQuery starts the count over, so another run of failures is needed to lock again
+	// once the lock ends
+	LoginFailureLockQuery = `
+		UPDATE login_failures SET locked_until = $3, failures = 0
+		WHERE scope = $1 AND subject = $2
+`
+
+	LoginFailureDeleteQuery = `
+		DELETE FROM login_failures
+		WHERE scope = $1 AND subject = $2
+`
+
+	LoginFailureDeleteStaleQuery = `
+		DELETE FROM login_failures
+		WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until < NOW())
+`
+)
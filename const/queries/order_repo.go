@@ -4,22 +4,22 @@ const (
 	CurrencyExchangeCreateQuery = `
 		INSERT INTO currency_exchanges (
 			uid, user_id, from_currency_id, to_currency_id, from_amount, to_amount,
-			to_amount_with_fee, exchange_rate, fee, status
+			to_amount_with_fee, exchange_rate, fee, fee_amount, fee_rule_id, target_rate, expires_at, status
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id, created_at, updated_at
 `
 
 	CurrencyExchangeGetByIDQuery = `
 		SELECT c.id, c.uid, c.user_id, '' as email, c.from_currency_id,
 		       c.to_currency_id, c.from_amount, c.to_amount, c.to_amount_with_fee,
-		       c.exchange_rate, c.fee, c.status, c.created_at, c.updated_at,
+		       c.exchange_rate, c.fee, c.fee_amount, c.fee_rule_id, c.target_rate, c.expires_at, c.status, c.created_at, c.updated_at,
 		       fc.id as "from_currency.id", fc.code as "from_currency.code",
 		       fc.name as "from_currency.name", fc.symbol as "from_currency.symbol",
-		       fc.is_active as "from_currency.is_active", fc.is_crypto as "from_currency.is_crypto",
+		       fc.is_active as "from_currency.is_active", fc.is_crypto as "from_currency.is_crypto", fc.decimals as "from_currency.decimals",
 		       tc.id as "to_currency.id", tc.code as "to_currency.code",
 		       tc.name as "to_currency.name", tc.symbol as "to_currency.symbol",
-		       tc.is_active as "to_currency.is_active", tc.is_crypto as "to_currency.is_crypto"
+		       tc.is_active as "to_currency.is_active", tc.is_crypto as "to_currency.is_crypto", tc.decimals as "to_currency.decimals"
 		FROM currency_exchanges c
 		LEFT JOIN currencies fc ON fc.id = c.from_currency_id
 		LEFT JOIN currencies tc ON tc.id = c.to_currency_id
@@ -28,13 +28,13 @@ const (
 	CurrencyExchangeGetByUIDQuery = `
 		SELECT c.id, c.uid, c.user_id, '' as email, c.from_currency_id,
 		       c.to_currency_id, c.from_amount, c.to_amount, c.to_amount_with_fee,
-		       c.exchange_rate, c.fee, c.status, c.created_at, c.updated_at,
+		       c.exchange_rate, c.fee, c.fee_amount, c.fee_rule_id, c.target_rate, c.expires_at, c.status, c.created_at, c.updated_at,
 		       fc.id as "from_currency.id", fc.code as "from_currency.code",
 		       fc.name as "from_currency.name", fc.symbol as "from_currency.symbol",
-		       fc.is_active as "from_currency.is_active", fc.is_crypto as "from_currency.is_crypto",
+		       fc.is_active as "from_currency.is_active", fc.is_crypto as "from_currency.is_crypto", fc.decimals as "from_currency.decimals",
 		       tc.id as "to_currency.id", tc.code as "to_currency.code",
 		       tc.name as "to_currency.name", tc.symbol as "to_currency.symbol",
-		       tc.is_active as "to_currency.is_active", tc.is_crypto as "to_currency.is_crypto"
+		       tc.is_active as "to_currency.is_active", tc.is_crypto as "to_currency.is_crypto", tc.decimals as "to_currency.decimals"
 		FROM currency_exchanges c
 		LEFT JOIN currencies fc ON fc.id = c.from_currency_id
 		LEFT JOIN currencies tc ON tc.id = c.to_currency_id
@@ -43,13 +43,13 @@ const (
 	CurrencyExchangeGetUserExchangesQuery = `
 		SELECT c.id, c.uid, c.user_id, '' as email, c.from_currency_id,
 		       c.to_currency_id, c.from_amount, c.to_amount, c.to_amount_with_fee,
-		       c.exchange_rate, c.fee, c.status, c.created_at, c.updated_at,
+		       c.exchange_rate, c.fee, c.fee_amount, c.fee_rule_id, c.target_rate, c.expires_at, c.status, c.created_at, c.updated_at,
 		       fc.id as "from_currency.id", fc.code as "from_currency.code",
 		       fc.name as "from_currency.name", fc.symbol as "from_currency.symbol",
-		       fc.is_active as "from_currency.is_active", fc.is_crypto as "from_currency.is_crypto",
+		       fc.is_active as "from_currency.is_active", fc.is_crypto as "from_currency.is_crypto", fc.decimals as "from_currency.decimals",
 		       tc.id as "to_currency.id", tc.code as "to_currency.code",
 		       tc.name as "to_currency.name", tc.symbol as "to_currency.symbol",
-		       tc.is_active as "to_currency.is_active", tc.is_crypto as "to_currency.is_crypto"
+		       tc.is_active as "to_currency.is_active", tc.is_crypto as "to_currency.is_crypto", tc.decimals as "to_currency.decimals"
 		FROM currency_exchanges c
 		LEFT JOIN currencies fc ON fc.id = c.from_currency_id
 		LEFT JOIN currencies tc ON tc.id = c.to_currency_id
@@ -68,13 +68,13 @@ const (
 	CurrencyExchangeGetAllBaseQuery = `
 		SELECT c.id, c.uid, c.user_id, u.email, c.from_currency_id,
 		       c.to_currency_id, c.from_amount, c.to_amount, c.to_amount_with_fee,
-		       c.exchange_rate, c.fee, c.status, c.created_at, c.updated_at,
+		       c.exchange_rate, c.fee, c.fee_amount, c.fee_rule_id, c.target_rate, c.expires_at, c.status, c.created_at, c.updated_at,
 		       fc.id as "from_currency.id", fc.code as "from_currency.code",
 		       fc.name as "from_currency.name", fc.symbol as "from_currency.symbol",
-		       fc.is_active as "from_currency.is_active", fc.is_crypto as "from_currency.is_crypto",
+		       fc.is_active as "from_currency.is_active", fc.is_crypto as "from_currency.is_crypto", fc.decimals as "from_currency.decimals",
 		       tc.id as "to_currency.id", tc.code as "to_currency.code",
 		       tc.name as "to_currency.name", tc.symbol as "to_currency.symbol",
-		       tc.is_active as "to_currency.is_active", tc.is_crypto as "to_currency.is_crypto"
+		       tc.is_active as "to_currency.is_active", tc.is_crypto as "to_currency.is_crypto", tc.decimals as "to_currency.decimals"
 		FROM currency_exchanges c
 		LEFT JOIN users u ON u.id = c.user_id
 		LEFT JOIN currencies fc ON fc.id = c.from_currency_id
@@ -94,11 +94,64 @@ const (
 		RETURNING updated_at
 `
 
+	CurrencyExchangeLockByIDQuery = `SELECT * FROM currency_exchanges WHERE id = $1 FOR UPDATE`
+
+	CurrencyExchangeUpdateExecutionQuery = `
+		UPDATE currency_exchanges
+		SET to_amount = $1, to_amount_with_fee = $2, exchange_rate = $3, fee = $4,
+		    fee_amount = $5, fee_rule_id = $6, status = $7, updated_at = NOW()
+		WHERE id = $8
+		RETURNING updated_at
+`
+
+	// Pending, unexpired limit exchanges whose pair rate has reached the target, after id $1.
+	// A deactivated pair keeps its exchanges pending until they expire. The matched rate and
+	// fee are returned so execution prices at what was matched.
+	CurrencyExchangeGetMatchedLimitQuery = `
+		SELECT e.*, r.rate AS market_rate, r.fee AS market_fee
+		FROM currency_exchanges e
+		JOIN exchange_rates r ON r.from_currency_id = e.from_currency_id
+		    AND r.to_currency_id = e.to_currency_id AND r.is_active = true
+		WHERE e.status = 'pending' AND e.target_rate IS NOT NULL
+		  AND (e.expires_at IS NULL OR e.expires_at > NOW())
+		  AND r.rate >= e.target_rate
+		  AND e.id > $1
+		ORDER BY e.id
+		LIMIT $2
+`
+
+	// Exchanges locked by a cancel or an execution are skipped and picked up by a later pass
+	CurrencyExchangeExpireLimitQuery = `
+		UPDATE currency_exchanges
+		SET status = 'expired', updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM currency_exchanges
+			WHERE status = 'pending' AND target_rate IS NOT NULL AND expires_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+`
+
 	CurrencyExchangeGetExchangeRateQuery = `
 		SELECT * FROM exchange_rates
 		WHERE from_currency_id = $1 AND to_currency_id = $2 AND is_active = true
 		LIMIT 1
 `
 
+	// Pending limit exchanges count towards the cap since their funds are already held
+	CurrencyExchangeGetDailyVolumeQuery = `
+		SELECT COALESCE(SUM(from_amount), 0) FROM currency_exchanges
+		WHERE user_id = $1 AND from_currency_id = $2 AND to_currency_id = $3
+		  AND status IN ('pending', 'completed') AND created_at >= date_trunc('day', NOW())
+`
+
+	CurrencyExchangeGetDailyVolumeByCurrencyQuery = `
+		SELECT COALESCE(SUM(from_amount), 0) FROM currency_exchanges
+		WHERE user_id = $1 AND from_currency_id = $2
+		  AND status IN ('pending', 'completed') AND created_at >= date_trunc('day', NOW())
+`
+
 	CurrencyExchangeCountByStatusQuery = `SELECT COUNT(*) as cnt FROM currency_exchanges WHERE status = $1`
 )
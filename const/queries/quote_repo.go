@@ -0,0 +1,34 @@
+package queries
+
+const (
+	ExchangeQuoteCreateQuery = `
+		INSERT INTO exchange_quotes (
+			uid, user_id, from_currency_id, to_currency_id, from_amount, to_amount,
+			to_amount_with_fee, exchange_rate, fee, fee_amount, fee_rule_id, expires_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at
+`
+
+	ExchangeQuoteLockByUIDQuery = `SELECT * FROM exchange_quotes WHERE uid = $1 FOR UPDATE`
+
+	ExchangeQuoteMarkExecutedQuery = `
+		UPDATE exchange_quotes
+		SET exchange_id = $1, executed_at = NOW()
+		WHERE id = $2
+		RETURNING executed_at
+`
+
+	ExchangeQuoteConversionStatsQuery = `
+		SELECT fc.code AS from_currency_code, tc.code AS to_currency_code,
+		       COUNT(*) AS quotes,
+		       COUNT(q.exchange_id) AS executed,
+		       COUNT(*) FILTER (WHERE q.exchange_id IS NULL AND q.expires_at <= NOW()) AS expired
+		FROM exchange_quotes q
+		JOIN currencies fc ON fc.id = q.from_currency_id
+		JOIN currencies tc ON tc.id = q.to_currency_id
+		WHERE q.created_at >= $1 AND q.created_at < $2
+		GROUP BY fc.code, tc.code
+		ORDER BY quotes DESC
+`
+)
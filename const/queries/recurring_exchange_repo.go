@@ -0,0 +1,61 @@
+package queries
+
+const (
+	RecurringExchangeCreateQuery = `
+		INSERT INTO recurring_exchanges (
+			user_id, from_currency_id, to_currency_id, from_amount, cron_expression, interval_seconds, status, next_run_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+`
+
+	// recurringExchangeSelect resolves the currency codes the exchange path takes
+	recurringExchangeSelect = `
+		SELECT r.*, fc.code AS from_currency_code, tc.code AS to_currency_code
+		FROM recurring_exchanges r
+		JOIN currencies fc ON fc.id = r.from_currency_id
+		JOIN currencies tc ON tc.id = r.to_currency_id
+`
+
+	RecurringExchangeGetByIDQuery = recurringExchangeSelect + `WHERE r.id = $1`
+
+	RecurringExchangeLockByIDQuery = recurringExchangeSelect + `WHERE r.id = $1 FOR UPDATE OF r`
+
+	RecurringExchangeGetByUserQuery = recurringExchangeSelect + `
+		WHERE r.user_id = $1 AND r.status <> 'canceled'
+		ORDER BY r.created_at DESC
+		LIMIT $2 OFFSET $3
+`
+
+	RecurringExchangeCountByUserQuery = `SELECT COUNT(*) FROM recurring_exchanges WHERE user_id = $1 AND status <> 'canceled'`
+
+	RecurringExchangeGetDueQuery = `
+		SELECT id FROM recurring_exchanges
+		WHERE status = 'active' AND next_run_at <= NOW()
+		ORDER BY next_run_at
+		LIMIT $1
+`
+
+	RecurringExchangeUpdateQuery = `
+		UPDATE recurring_exchanges
+		SET from_amount = $1, cron_expression = $2, interval_seconds = $3, status = $4, next_run_at = $5,
+		    last_run_at = $6, consecutive_failures = $7, pause_reason = $8, updated_at = NOW()
+		WHERE id = $9
+		RETURNING updated_at
+`
+
+	RecurringExchangeRunCreateQuery = `
+		INSERT INTO recurring_exchange_runs (recurring_exchange_id, exchange_id, status, error, scheduled_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+`
+
+	RecurringExchangeRunGetByRecurringExchangeQuery = `
+		SELECT * FROM recurring_exchange_runs
+		WHERE recurring_exchange_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+`
+
+	RecurringExchangeRunCountByRecurringExchangeQuery = `SELECT COUNT(*) FROM recurring_exchange_runs WHERE recurring_exchange_id = $1`
+)
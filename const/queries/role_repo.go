@@ -0,0 +1,22 @@
+package queries
+
+const (
+	RoleGetAllQuery = `SELECT * FROM roles ORDER BY name`
+
+	RoleCreateQuery = `
+		INSERT INTO roles (name, description, permissions)
+		VALUES ($1, $2, $3)
+		RETURNING is_system, created_at, updated_at
+`
+
+	RoleUpdateQuery = `
+		UPDATE roles
+		SET description = $1, permissions = $2, updated_at = NOW()
+		WHERE name = $3 AND NOT is_system
+		RETURNING updated_at
+`
+
+	RoleDeleteQuery = `DELETE FROM roles WHERE name = $1 AND NOT is_system`
+
+	RoleCountUsersQuery = `SELECT COUNT(*) FROM users WHERE role = $1`
+)
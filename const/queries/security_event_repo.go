@@ -0,0 +1,21 @@
+package queries
+
+const (
+	SecurityEventCreateQuery = `
+		INSERT INTO security_events (user_id, type, session_id, ip_address, user_agent, details)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+`
+
+	SecurityEventListQuery = `
+		SELECT * FROM security_events
+		WHERE ($1 = 0 OR user_id = $1) AND ($2 = '' OR type = $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+`
+
+	SecurityEventCountQuery = `
+		SELECT COUNT(*) FROM security_events
+		WHERE ($1 = 0 OR user_id = $1) AND ($2 = '' OR type = $2)
+`
+)
@@ -2,13 +2,23 @@ package queries
 
 const (
 	TransactionCreateQuery = `
-		INSERT INTO transactions (user_id, wallet_id, type, amount, fee, status, tx_hash)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO transactions (user_id, wallet_id, type, amount, fee, fee_rule_id, status, tx_hash, address, network, description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at
+`
+
+	// Rescanning a block must not book the same output twice
+	TransactionCreateChainDepositQuery = `
+		INSERT INTO transactions (user_id, wallet_id, type, amount, fee, status, tx_hash, output_index, address, network, block_height, confirmations)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (network, tx_hash, output_index) WHERE type = 'deposit' AND block_height IS NOT NULL DO NOTHING
 		RETURNING id, created_at, updated_at
 `
 
 	TransactionGetByIDQuery = `SELECT * FROM transactions WHERE id = $1`
 
+	TransactionLockByIDQuery = `SELECT * FROM transactions WHERE id = $1 FOR UPDATE`
+
 	TransactionGetUserTransactionsQuery = `
 		SELECT * FROM transactions
 		WHERE user_id = $1
@@ -23,6 +33,26 @@ const (
 		RETURNING updated_at
 `
 
+	TransactionUpdateReviewQuery = `
+		UPDATE transactions
+		SET status = $1, tx_hash = $2, reviewed_by = $3, reviewed_at = $4, reject_reason = $5, updated_at = NOW()
+		WHERE id = $6
+		RETURNING updated_at
+`
+
+	TransactionUpdateConfirmationsQuery = `
+		UPDATE transactions
+		SET status = $1, confirmations = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING updated_at
+`
+
+	TransactionGetPendingChainDepositsQuery = `
+		SELECT * FROM transactions
+		WHERE type = 'deposit' AND status = 'pending' AND network = $1 AND block_height IS NOT NULL
+		ORDER BY id
+`
+
 	TransactionGetPendingQuery = `
 		SELECT * FROM transactions
 		WHERE status = 'pending'
@@ -34,5 +64,14 @@ const (
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
 `
-)
 
+	TransactionListBaseQuery = `SELECT * FROM transactions`
+
+	TransactionCountBaseQuery = `SELECT COUNT(*) FROM transactions`
+
+	TransactionGetDailyWithdrawalVolumeQuery = `
+		SELECT COALESCE(SUM(amount), 0) FROM transactions
+		WHERE wallet_id = $1 AND type = 'withdrawal'
+		  AND status IN ('pending', 'approved', 'completed') AND created_at >= date_trunc('day', NOW())
+`
+)
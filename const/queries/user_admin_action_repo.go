@@ -0,0 +1,18 @@
+package queries
+
+const (
+	UserAdminActionCreateQuery = `
+		INSERT INTO user_admin_actions (user_id, admin_id, action, reason, details)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+`
+
+	UserAdminActionListByUserQuery = `
+		SELECT * FROM user_admin_actions
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+`
+
+	UserAdminActionCountByUserQuery = `SELECT COUNT(*) FROM user_admin_actions WHERE user_id = $1`
+)
@@ -4,11 +4,13 @@ const (
 	UserCreateQuery = `
 		INSERT INTO users (email, password_hash, first_name, last_name, role, is_active, is_verified)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, created_at, updated_at
+		RETURNING id, fee_tier, kyc_level, created_at, updated_at
 `
 
 	UserGetByIDQuery = `SELECT * FROM users WHERE id = $1`
 
+	UserLockByIDQuery = `SELECT * FROM users WHERE id = $1 FOR UPDATE`
+
 	UserGetByEmailQuery = `SELECT * FROM users WHERE email = $1 AND role = 'client'`
 
 	UserUpdateQuery = `
@@ -18,18 +20,148 @@ const (
 		RETURNING updated_at
 `
 
+	UserUpdateFeeTierQuery = `
+		UPDATE users
+		SET fee_tier = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING updated_at
+`
+
+	UserUpdateRoleQuery = `
+		UPDATE users
+		SET role = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING updated_at
+`
+
+	UserUpdateKYCLevelQuery = `
+		UPDATE users
+		SET kyc_level = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING updated_at
+`
+
+	UserUpdatePasswordQuery = `
+		UPDATE users
+		SET password_hash = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING updated_at
+`
+
+	UserMarkVerifiedQuery = `
+		UPDATE users
+		SET is_verified = true, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+`
+
 	// Base queries for queryBuilder
 	UserListBaseQuery = `SELECT * FROM users`
 
 	UserCountBaseQuery = `SELECT COUNT(*) FROM users`
 
 	UserSessionCreateQuery = `
-		INSERT INTO user_sessions (user_id, refresh_token, expires_at)
+		INSERT INTO user_sessions (user_id, refresh_token_hash, expires_at, user_agent, ip_address, remember_me)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, last_used_at
+`
+
+	UserSessionGetByTokenQuery = `SELECT * FROM user_sessions WHERE refresh_token_hash = $1 AND expires_at > NOW()`
+
+	// Matching the old token makes concurrent refreshes of one session fail instead of forking it
+	UserSessionRotateQuery = `
+		UPDATE user_sessions
+		SET refresh_token_hash = $1, expires_at = $2, user_agent = $3, ip_address = $4, last_used_at = NOW()
+		WHERE id = $5 AND refresh_token_hash = $6 AND expires_at > NOW()
+		RETURNING last_used_at
+`
+
+	UserSessionListByUserQuery = `
+		SELECT * FROM user_sessions
+		WHERE user_id = $1 AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+`
+
+	UserSessionDeleteQuery = `DELETE FROM user_sessions WHERE refresh_token_hash = $1 RETURNING *`
+
+	UserSessionDeleteByIDQuery = `DELETE FROM user_sessions WHERE id = $1 AND user_id = $2 RETURNING *`
+
+	UserSessionDeleteByUserQuery = `DELETE FROM user_sessions WHERE user_id = $1 RETURNING *`
+
+	RotatedRefreshTokenCreateQuery = `
+		INSERT INTO rotated_refresh_tokens (token_hash, session_id, user_id)
 		VALUES ($1, $2, $3)
+`
+
+	RotatedRefreshTokenGetQuery = `SELECT * FROM rotated_refresh_tokens WHERE token_hash = $1`
+
+	RevokedSessionCreateQuery = `
+		INSERT INTO revoked_sessions (session_id, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (session_id) DO NOTHING
+`
+
+	RevokedSessionGetActiveQuery = `SELECT session_id, expires_at FROM revoked_sessions WHERE expires_at > NOW()`
+
+	RevokedSessionDeleteExpiredQuery = `DELETE FROM revoked_sessions WHERE expires_at <= NOW()`
+
+	UserTokenCreateQuery = `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id, created_at
 `
 
-	UserSessionGetByTokenQuery = `SELECT * FROM user_sessions WHERE refresh_token = $1 AND expires_at > NOW()`
+	UserTokenLockByHashQuery = `SELECT * FROM user_tokens WHERE token_hash = $1 AND purpose = $2 FOR UPDATE`
+
+	UserTokenMarkUsedQuery = `UPDATE user_tokens SET used_at = NOW() WHERE id = $1 RETURNING used_at`
+
+	// Issuing a new token invalidates the user's earlier ones for the same purpose
+	UserTokenRevokeUnusedQuery = `
+		UPDATE user_tokens SET used_at = NOW()
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+`
+
+	UserTokenGetLatestCreatedQuery = `
+		SELECT MAX(created_at) FROM user_tokens
+		WHERE user_id = $1 AND purpose = $2
+`
+
+	UserSetTOTPSecretQuery = `
+		UPDATE users
+		SET totp_secret = $1, totp_enabled = false, totp_last_step = NULL, updated_at = NOW()
+		WHERE id = $2
+		RETURNING updated_at
+`
+
+	UserEnableTOTPQuery = `
+		UPDATE users
+		SET totp_enabled = true, totp_last_step = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING updated_at
+`
+
+	UserDisableTOTPQuery = `
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled = false, totp_last_step = NULL, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+`
+
+	// Only advances, so a code is accepted at most once
+	UserUseTOTPStepQuery = `
+		UPDATE users
+		SET totp_last_step = $1
+		WHERE id = $2 AND (totp_last_step IS NULL OR totp_last_step < $1)
+`
+
+	RecoveryCodeCreateQuery = `INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)`
+
+	RecoveryCodeDeleteByUserQuery = `DELETE FROM user_recovery_codes WHERE user_id = $1`
+
+	RecoveryCodeUseQuery = `
+		UPDATE user_recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+`
 
-	UserSessionDeleteQuery = `DELETE FROM user_sessions WHERE refresh_token = $1`
+	RecoveryCodeCountUnusedQuery = `SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
 )
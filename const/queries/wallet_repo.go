@@ -16,7 +16,7 @@ const (
 			w.id, w.user_id, w.currency_id, w.balance, w.locked, w.created_at, w.updated_at,
 			c.id as "currency.id", c.code as "currency.code", c.name as "currency.name",
 			c.symbol as "currency.symbol", c.is_active as "currency.is_active",
-			c.is_crypto as "currency.is_crypto", c.created_at as "currency.created_at",
+			c.is_crypto as "currency.is_crypto", c.decimals as "currency.decimals", c.network as "currency.network", c.created_at as "currency.created_at",
 			c.updated_at as "currency.updated_at"
 		FROM wallets w
 		JOIN currencies c ON w.currency_id = c.id
@@ -26,6 +26,16 @@ const (
 
 	WalletGetForUpdateQuery = `SELECT * FROM wallets WHERE id = $1`
 
+	WalletLockByIDQuery = `SELECT * FROM wallets WHERE id = $1 FOR UPDATE`
+
+	// Locks the user's wallets in id order so concurrent swaps cannot deadlock
+	WalletLockByUserAndCurrenciesQuery = `
+		SELECT * FROM wallets
+		WHERE user_id = $1 AND currency_id = ANY($2)
+		ORDER BY id
+		FOR UPDATE
+`
+
 	WalletUpdateBalanceQuery = `
 		UPDATE wallets
 		SET balance = $1, locked = $2, updated_at = NOW()
@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/caspianex/exchange-backend/internal/models"
+	"github.com/caspianex/exchange-backend/pkg/chain"
+	"github.com/caspianex/exchange-backend/pkg/validator"
+	"github.com/go-chi/chi/v5"
+)
+
+// ChainMockHandler drives the in-memory chains used when CHAIN_MOCK is enabled,
+// so deposits can be exercised end to end without a node
+type ChainMockHandler struct {
+	chains map[string]*chain.MemoryChain
+}
+
+func NewChainMockHandler(chains map[string]*chain.MemoryChain) *ChainMockHandler {
+	return &ChainMockHandler{
+		chains: chains,
+	}
+}
+
+// Send queues a transfer to an address for the next block
+func (h *ChainMockHandler) Send(w http.ResponseWriter, r *http.Request) {
+	memoryChain, ok := h.chains[chi.URLParam(r, "network")]
+	if !ok {
+		respondError(w, http.StatusNotFound, "Unknown network")
+		return
+	}
+
+	var req models.MockChainTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	txHash := memoryChain.Send(req.Address, req.Amount)
+
+	respondJSON(w, http.StatusCreated, map[string]string{"tx_hash": txHash})
+}
+
+// Mine produces blocks, confirming queued transfers
+func (h *ChainMockHandler) Mine(w http.ResponseWriter, r *http.Request) {
+	memoryChain, ok := h.chains[chi.URLParam(r, "network")]
+	if !ok {
+		respondError(w, http.StatusNotFound, "Unknown network")
+		return
+	}
+
+	var req models.MockChainMineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	height := memoryChain.Mine(req.Blocks)
+
+	respondJSON(w, http.StatusOK, map[string]int64{"height": height})
+}
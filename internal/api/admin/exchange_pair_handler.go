@@ -63,7 +63,7 @@ func (h *ExchangeRatesHandler) CreateRate(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	rate, err := h.ratesService.CreateRate(r.Context(), &req)
+	rate, err := h.ratesService.CreateRate(r.Context(), auditMeta(r), &req)
 	if err != nil {
 		respondError(w, http.StatusBadRequest, err.Error())
 		return
@@ -87,7 +87,7 @@ func (h *ExchangeRatesHandler) UpdateRate(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	rate, err := h.ratesService.UpdateRate(r.Context(), id, &req)
+	rate, err := h.ratesService.UpdateRate(r.Context(), auditMeta(r), id, &req)
 	if err != nil {
 		respondError(w, http.StatusBadRequest, err.Error())
 		return
@@ -105,7 +105,7 @@ func (h *ExchangeRatesHandler) DeleteRate(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := h.ratesService.DeleteRate(r.Context(), id); err != nil {
+	if err := h.ratesService.DeleteRate(r.Context(), auditMeta(r), id); err != nil {
 		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
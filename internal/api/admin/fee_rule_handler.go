@@ -0,0 +1,130 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/caspianex/exchange-backend/internal/models"
+	"github.com/caspianex/exchange-backend/internal/service"
+	"github.com/caspianex/exchange-backend/pkg/validator"
+	"github.com/go-chi/chi/v5"
+)
+
+type FeeRuleHandler struct {
+	feeService *service.FeeService
+}
+
+func NewFeeRuleHandler(feeService *service.FeeService) *FeeRuleHandler {
+	return &FeeRuleHandler{
+		feeService: feeService,
+	}
+}
+
+// ListRules returns fee rules, optionally filtered by operation. Retired rules are included
+// unless active=true is passed.
+func (h *FeeRuleHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	operation := r.URL.Query().Get("operation")
+	activeOnly := r.URL.Query().Get("active") == "true"
+
+	rules, total, err := h.feeService.ListRules(r.Context(), operation, activeOnly, limit, offset)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.PaginatedResponse{
+		Items: rules,
+		Total: total,
+	})
+}
+
+func (h *FeeRuleHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid fee rule ID")
+		return
+	}
+
+	rule, err := h.feeService.GetRule(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rule)
+}
+
+func (h *FeeRuleHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	var req models.FeeRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule, err := h.feeService.CreateRule(r.Context(), auditMeta(r), &req)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, rule)
+}
+
+// UpdateRule replaces a rule. The response is the new rule; the old one is retired.
+func (h *FeeRuleHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid fee rule ID")
+		return
+	}
+
+	var req models.FeeRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule, err := h.feeService.UpdateRule(r.Context(), auditMeta(r), id, &req)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rule)
+}
+
+func (h *FeeRuleHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid fee rule ID")
+		return
+	}
+
+	if err := h.feeService.DeleteRule(r.Context(), auditMeta(r), id); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Fee rule retired successfully"})
+}
@@ -0,0 +1,183 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/caspianex/exchange-backend/internal/models"
+	"github.com/caspianex/exchange-backend/internal/service"
+	"github.com/caspianex/exchange-backend/pkg/validator"
+	"github.com/go-chi/chi/v5"
+)
+
+type KYCHandler struct {
+	kycService *service.KYCService
+}
+
+func NewKYCHandler(kycService *service.KYCService) *KYCHandler {
+	return &KYCHandler{
+		kycService: kycService,
+	}
+}
+
+// ListApplications returns the review queue, oldest first. Defaults to pending applications.
+func (h *KYCHandler) ListApplications(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "pending"
+	} else if status == "all" {
+		status = ""
+	}
+
+	apps, total, err := h.kycService.ListApplications(r.Context(), status, limit, offset)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.PaginatedResponse{
+		Items: apps,
+		Total: total,
+	})
+}
+
+// GetApplication returns an application with its documents
+func (h *KYCHandler) GetApplication(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid application ID")
+		return
+	}
+
+	app, err := h.kycService.GetApplication(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, app)
+}
+
+// GetDocumentFile streams an uploaded document
+func (h *KYCHandler) GetDocumentFile(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid document ID")
+		return
+	}
+
+	doc, body, err := h.kycService.OpenDocument(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(doc.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", doc.FileName))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, body)
+}
+
+func (h *KYCHandler) ApproveApplication(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid application ID")
+		return
+	}
+
+	var req models.ApproveKYCApplicationRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	app, err := h.kycService.Approve(r.Context(), auditMeta(r), id, req.Reason)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, app)
+}
+
+func (h *KYCHandler) RejectApplication(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid application ID")
+		return
+	}
+
+	var req models.RejectKYCApplicationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	app, err := h.kycService.Reject(r.Context(), auditMeta(r), id, req.Reason)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, app)
+}
+
+func (h *KYCHandler) ListLimits(w http.ResponseWriter, r *http.Request) {
+	limits, err := h.kycService.ListLimits(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, limits)
+}
+
+// SetLimit replaces the daily limits of one level in one currency
+func (h *KYCHandler) SetLimit(w http.ResponseWriter, r *http.Request) {
+	var req models.SetKYCLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, err := h.kycService.SetLimit(r.Context(), auditMeta(r), &req)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, limit)
+}
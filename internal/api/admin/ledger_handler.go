@@ -0,0 +1,88 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/caspianex/exchange-backend/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type LedgerHandler struct {
+	ledgerService *service.LedgerService
+}
+
+func NewLedgerHandler(ledgerService *service.LedgerService) *LedgerHandler {
+	return &LedgerHandler{
+		ledgerService: ledgerService,
+	}
+}
+
+// Reconcile returns wallets whose balances do not match the ledger
+func (h *LedgerHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	mismatches, err := h.ledgerService.Reconcile(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"mismatches": mismatches,
+		"total":      len(mismatches),
+	})
+}
+
+// GetJournal returns a ledger journal with its postings
+func (h *LedgerHandler) GetJournal(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid journal ID")
+		return
+	}
+
+	journal, err := h.ledgerService.GetJournal(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, journal)
+}
+
+// GetWalletPostings returns the ledger history of a wallet along with the derived balance
+func (h *LedgerHandler) GetWalletPostings(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	walletID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid wallet ID")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	postings, err := h.ledgerService.GetWalletPostings(r.Context(), walletID, limit, offset)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	balance, err := h.ledgerService.GetWalletBalance(r.Context(), walletID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"postings":       postings,
+		"ledger_balance": balance,
+	})
+}
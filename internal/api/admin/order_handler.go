@@ -3,6 +3,7 @@ package admin
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	admindto "github.com/caspianex/exchange-backend/internal/dto/admin"
 	"github.com/caspianex/exchange-backend/internal/service"
@@ -68,3 +69,35 @@ func (h *ExchangeHandler) GetExchange(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, admindto.ToExchangeDTO(exchange))
 	return
 }
+
+// GetQuoteStats reports quote-to-trade conversion per pair. from and to are RFC 3339 timestamps
+// and default to the last 7 days.
+func (h *ExchangeHandler) GetQuoteStats(w http.ResponseWriter, r *http.Request) {
+	to := time.Now()
+	if v := r.URL.Query().Get("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid to timestamp")
+			return
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -7)
+	if v := r.URL.Query().Get("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid from timestamp")
+			return
+		}
+		from = parsed
+	}
+
+	stats, err := h.exchangeService.GetQuoteConversionStats(r.Context(), from, to)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, stats)
+}
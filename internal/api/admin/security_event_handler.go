@@ -0,0 +1,52 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/caspianex/exchange-backend/internal/models"
+	"github.com/caspianex/exchange-backend/internal/service"
+)
+
+type SecurityEventHandler struct {
+	securityEventService *service.SecurityEventService
+}
+
+func NewSecurityEventHandler(securityEventService *service.SecurityEventService) *SecurityEventHandler {
+	return &SecurityEventHandler{
+		securityEventService: securityEventService,
+	}
+}
+
+// ListEvents returns security events newest first, optionally filtered by user_id and type
+func (h *SecurityEventHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	var userID int64
+	if v := r.URL.Query().Get("user_id"); v != "" {
+		var err error
+		if userID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+	}
+
+	events, total, err := h.securityEventService.List(r.Context(), userID, r.URL.Query().Get("type"), limit, offset)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.PaginatedResponse{
+		Items: events,
+		Total: total,
+	})
+}
@@ -1,10 +1,16 @@
 package admin
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"strconv"
 
+	"github.com/caspianex/exchange-backend/internal/api/middleware"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/models"
 	"github.com/caspianex/exchange-backend/internal/service"
+	"github.com/caspianex/exchange-backend/pkg/validator"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -59,3 +65,157 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, userWithWallets)
 }
+
+func (h *UserHandler) SetFeeTier(w http.ResponseWriter, r *http.Request) {
+	meta, userID, ok := actionTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.SetFeeTierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.userService.SetFeeTier(r.Context(), meta, userID, &req)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, user)
+}
+
+// UpdateUser changes the user's names
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	meta, userID, ok := actionTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.AdminUpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(r.Context(), meta, userID, &req)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, user)
+}
+
+// DeactivateUser blocks the account. Its access tokens stop working immediately.
+func (h *UserHandler) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	h.handleAction(w, r, h.userService.Deactivate)
+}
+
+func (h *UserHandler) ActivateUser(w http.ResponseWriter, r *http.Request) {
+	h.handleAction(w, r, h.userService.Activate)
+}
+
+// MarkVerified marks the user's email address verified
+func (h *UserHandler) MarkVerified(w http.ResponseWriter, r *http.Request) {
+	h.handleAction(w, r, h.userService.MarkVerified)
+}
+
+// ForceLogout revokes all of the user's sessions
+func (h *UserHandler) ForceLogout(w http.ResponseWriter, r *http.Request) {
+	h.handleAction(w, r, h.userService.ForceLogout)
+}
+
+// UnlockUser lifts a sign-in lockout after failed login attempts
+func (h *UserHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	h.handleAction(w, r, h.userService.Unlock)
+}
+
+// ListActions returns the changes staff made to the user, newest first
+func (h *UserHandler) ListActions(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	actions, total, err := h.userService.ListAdminActions(r.Context(), userID, limit, offset)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"actions": actions,
+		"total":   total,
+	})
+}
+
+// handleAction runs an account action that only takes a reason
+func (h *UserHandler) handleAction(
+	w http.ResponseWriter,
+	r *http.Request,
+	action func(ctx context.Context, meta domain.AuditMeta, userID int64, reason string) (*domain.User, error),
+) {
+	meta, userID, ok := actionTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.AdminActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := action(r.Context(), meta, userID, req.Reason)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, user)
+}
+
+// actionTarget returns the audit details of the admin changing a user and the user's ID from the URL
+func actionTarget(w http.ResponseWriter, r *http.Request) (domain.AuditMeta, int64, bool) {
+	if _, ok := middleware.GetUserID(r.Context()); !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return domain.AuditMeta{}, 0, false
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid user ID")
+		return domain.AuditMeta{}, 0, false
+	}
+
+	return auditMeta(r), userID, true
+}
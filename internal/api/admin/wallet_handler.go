@@ -3,10 +3,12 @@ package admin
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/caspianex/exchange-backend/internal/models"
 	"github.com/caspianex/exchange-backend/internal/service"
 	"github.com/caspianex/exchange-backend/pkg/validator"
+	"github.com/go-chi/chi/v5"
 )
 
 type WalletHandler struct {
@@ -31,17 +33,138 @@ func (h *WalletHandler) ManualDeposit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	depositReq := &models.DepositRequest{
-		CurrencyCode: req.CurrencyCode,
-		Amount:       req.Amount,
-		TxHash:       req.TxHash,
+	tx, err := h.walletService.ManualDeposit(r.Context(), auditMeta(r), &req)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, tx)
+}
+
+// ListWithdrawals returns withdrawal requests oldest first, optionally filtered by status
+func (h *WalletHandler) ListWithdrawals(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	status := r.URL.Query().Get("status")
+
+	withdrawals, err := h.walletService.GetWithdrawals(r.Context(), status, limit, offset)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
 	}
 
-	tx, err := h.walletService.Deposit(r.Context(), req.UserID, depositReq)
+	total, err := h.walletService.GetWithdrawalsCount(r.Context(), status)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.PaginatedResponse{
+		Items: withdrawals,
+		Total: total,
+	})
+}
+
+func (h *WalletHandler) ApproveWithdrawal(w http.ResponseWriter, r *http.Request) {
+	txID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid withdrawal ID")
+		return
+	}
+
+	tx, err := h.walletService.ApproveWithdrawal(r.Context(), auditMeta(r), txID)
 	if err != nil {
 		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	respondJSON(w, http.StatusCreated, tx)
+	respondJSON(w, http.StatusOK, tx)
+}
+
+func (h *WalletHandler) RejectWithdrawal(w http.ResponseWriter, r *http.Request) {
+	txID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid withdrawal ID")
+		return
+	}
+
+	var req models.RejectWithdrawalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tx, err := h.walletService.RejectWithdrawal(r.Context(), auditMeta(r), txID, req.Reason)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tx)
+}
+
+func (h *WalletHandler) MarkWithdrawalSent(w http.ResponseWriter, r *http.Request) {
+	txID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid withdrawal ID")
+		return
+	}
+
+	var req models.MarkWithdrawalSentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tx, err := h.walletService.MarkWithdrawalSent(r.Context(), auditMeta(r), txID, req.TxHash)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tx)
+}
+
+// ImportDepositAddresses adds pre-generated addresses to the deposit address pool
+func (h *WalletHandler) ImportDepositAddresses(w http.ResponseWriter, r *http.Request) {
+	var req models.ImportDepositAddressesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	added, free, err := h.walletService.ImportDepositAddresses(r.Context(), &req)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"added": added,
+		"free":  free,
+	})
 }
@@ -2,8 +2,11 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
+	"github.com/caspianex/exchange-backend/internal/api/middleware"
+	"github.com/caspianex/exchange-backend/internal/domain"
 	"github.com/caspianex/exchange-backend/internal/models"
 	"github.com/caspianex/exchange-backend/internal/service"
 	"github.com/caspianex/exchange-backend/pkg/validator"
@@ -31,7 +34,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.authService.Register(r.Context(), &req)
+	result, err := h.authService.Register(r.Context(), &req, sessionMeta(r))
 	if err != nil {
 		respondError(w, http.StatusBadRequest, err.Error())
 		return
@@ -52,9 +55,36 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.authService.Login(r.Context(), &req)
+	result, err := h.authService.Login(r.Context(), &req, sessionMeta(r))
 	if err != nil {
-		respondError(w, http.StatusUnauthorized, err.Error())
+		status := http.StatusUnauthorized
+		var serviceErr *service.ServiceError
+		if errors.As(err, &serviceErr) && serviceErr.Code == service.ErrCodeLoginThrottled {
+			status = http.StatusTooManyRequests
+		}
+		respondServiceError(w, status, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// LoginMFA exchanges the mfa_token from Login and a TOTP or recovery code for real tokens
+func (h *AuthHandler) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	var req models.LoginMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.authService.LoginMFA(r.Context(), &req, sessionMeta(r))
+	if err != nil {
+		respondServiceError(w, http.StatusUnauthorized, err)
 		return
 	}
 
@@ -74,7 +104,7 @@ func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.authService.RefreshToken(r.Context(), req.RefreshToken)
+	result, err := h.authService.RefreshToken(r.Context(), req.RefreshToken, sessionMeta(r))
 	if err != nil {
 		respondError(w, http.StatusUnauthorized, err.Error())
 		return
@@ -98,3 +128,98 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Logged out successfully"})
 }
+
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req models.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.authService.VerifyEmail(r.Context(), req.Token); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Email verified successfully"})
+}
+
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req models.ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.authService.ResendVerification(r.Context(), req.Email); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "If the email is registered and not yet verified, a verification link has been sent"})
+}
+
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.authService.ForgotPassword(r.Context(), req.Email); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "If the email is registered, a password reset link has been sent"})
+}
+
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.authService.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Password reset successfully"})
+}
+
+// maxUserAgentLength matches user_sessions.user_agent
+const maxUserAgentLength = 512
+
+func sessionMeta(r *http.Request) domain.SessionMeta {
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	return domain.SessionMeta{
+		UserAgent: userAgent,
+		IPAddress: middleware.ClientIP(r),
+	}
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/caspianex/exchange-backend/internal/api/middleware"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/models"
+	"github.com/caspianex/exchange-backend/internal/service"
+	"github.com/caspianex/exchange-backend/pkg/validator"
+	"github.com/go-chi/chi/v5"
+)
+
+// multipartMemory is how much of an upload is buffered in memory before spilling to disk
+const multipartMemory = 1 << 20
+
+type KYCHandler struct {
+	kycService *service.KYCService
+}
+
+func NewKYCHandler(kycService *service.KYCService) *KYCHandler {
+	return &KYCHandler{
+		kycService: kycService,
+	}
+}
+
+// GetStatus returns the user's KYC level, latest application and limits
+func (h *KYCHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	status, err := h.kycService.GetStatus(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, status)
+}
+
+// UploadDocument accepts a multipart form with a "type" field and a "file" part
+func (h *KYCHandler) UploadDocument(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Leave room for the form fields around the file
+	r.Body = http.MaxBytesReader(w, r.Body, h.kycService.MaxDocumentSize()+multipartMemory)
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid upload or file too large")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Missing file")
+		return
+	}
+	defer file.Close()
+
+	docType := domain.KYCDocumentType(r.FormValue("type"))
+
+	doc, err := h.kycService.UploadDocument(r.Context(), userID, docType, header.Filename, header.Size, file)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, doc)
+}
+
+// DeleteDocument removes an upload that has not been submitted yet
+func (h *KYCHandler) DeleteDocument(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid document ID")
+		return
+	}
+
+	if err := h.kycService.DeleteDocument(r.Context(), userID, id); err != nil {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Document deleted successfully"})
+}
+
+// SubmitApplication submits the uploaded documents for review
+func (h *KYCHandler) SubmitApplication(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.SubmitKYCApplicationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	app, err := h.kycService.Submit(r.Context(), userID, req.Level)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, app)
+}
@@ -43,13 +43,41 @@ func (h *ExchangeHandler) CreateExchange(w http.ResponseWriter, r *http.Request)
 
 	exchange, err := h.exchangeService.CreateExchange(r.Context(), userID, &req)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+		respondServiceError(w, http.StatusBadRequest, err)
 		return
 	}
 
 	respondJSON(w, http.StatusCreated, exchange)
 }
 
+// CreateQuote returns a firm price that POST /exchanges executes unchanged when given its quote_id
+func (h *ExchangeHandler) CreateQuote(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.CreateQuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	quote, err := h.exchangeService.CreateQuote(r.Context(), userID, &req)
+	if err != nil {
+		respondServiceError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, quote)
+}
+
 func (h *ExchangeHandler) GetExchanges(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
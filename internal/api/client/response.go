@@ -2,9 +2,11 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/caspianex/exchange-backend/internal/models"
+	"github.com/caspianex/exchange-backend/internal/service"
 )
 
 func respondJSON(w http.ResponseWriter, status int, data interface{}) {
@@ -18,3 +20,17 @@ func respondError(w http.ResponseWriter, status int, message string) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(models.Response{Success: false, Error: message})
 }
+
+// respondServiceError is respondError that also passes on the code of a service.ServiceError
+func respondServiceError(w http.ResponseWriter, status int, err error) {
+	resp := models.Response{Success: false, Error: err.Error()}
+
+	var serviceErr *service.ServiceError
+	if errors.As(err, &serviceErr) {
+		resp.Code = serviceErr.Code
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/caspianex/exchange-backend/internal/api/middleware"
+	"github.com/caspianex/exchange-backend/internal/models"
+	"github.com/caspianex/exchange-backend/internal/service"
+	"github.com/caspianex/exchange-backend/pkg/validator"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService *service.TwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService *service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+	}
+}
+
+func (h *TwoFactorHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	status, err := h.twoFactorService.GetStatus(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, status)
+}
+
+// Setup returns a new secret and otpauth URI to add to an authenticator app
+func (h *TwoFactorHandler) Setup(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	setup, err := h.twoFactorService.Setup(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, setup)
+}
+
+// Enable confirms the setup with a code and returns the recovery codes
+func (h *TwoFactorHandler) Enable(w http.ResponseWriter, r *http.Request) {
+	userID, req, ok := h.decodeCode(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.Enable(r.Context(), userID, req.Code)
+	if err != nil {
+		respondServiceError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, codes)
+}
+
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	userID, req, ok := h.decodeCode(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactorService.Disable(r.Context(), userID, req.Code); err != nil {
+		respondServiceError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Two-factor authentication disabled"})
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, req, ok := h.decodeCode(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(r.Context(), userID, req.Code)
+	if err != nil {
+		respondServiceError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, codes)
+}
+
+func (h *TwoFactorHandler) decodeCode(w http.ResponseWriter, r *http.Request) (int64, *models.TOTPCodeRequest, bool) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return 0, nil, false
+	}
+
+	var req models.TOTPCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return 0, nil, false
+	}
+
+	if err := validator.Validate(&req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return 0, nil, false
+	}
+
+	return userID, &req, true
+}
@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/caspianex/exchange-backend/internal/api/middleware"
 	"github.com/caspianex/exchange-backend/internal/models"
 	"github.com/caspianex/exchange-backend/internal/service"
 	"github.com/caspianex/exchange-backend/pkg/validator"
+	"github.com/go-chi/chi/v5"
 )
 
 type WalletHandler struct {
@@ -37,31 +39,21 @@ func (h *WalletHandler) GetWallets(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, wallets)
 }
 
-func (h *WalletHandler) Deposit(w http.ResponseWriter, r *http.Request) {
+// GetDepositAddress returns the address the user should send crypto deposits to
+func (h *WalletHandler) GetDepositAddress(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
 		respondError(w, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
-	var req models.DepositRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request body")
-		return
-	}
-
-	if err := validator.Validate(&req); err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	tx, err := h.walletService.Deposit(r.Context(), userID, &req)
+	address, err := h.walletService.GetDepositAddress(r.Context(), userID, strings.ToUpper(chi.URLParam(r, "currency")))
 	if err != nil {
 		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	respondJSON(w, http.StatusCreated, tx)
+	respondJSON(w, http.StatusOK, address)
 }
 
 func (h *WalletHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
@@ -84,7 +76,7 @@ func (h *WalletHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
 
 	tx, err := h.walletService.Withdraw(r.Context(), userID, &req)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+		respondServiceError(w, http.StatusBadRequest, err)
 		return
 	}
 
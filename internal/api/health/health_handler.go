@@ -9,14 +9,22 @@ import (
 )
 
 type HealthHandler struct {
-	rateUpdater *worker.RateUpdater
-	startTime   time.Time
+	rateUpdater        *worker.RateUpdater
+	chainWatcher       *worker.ChainWatcher
+	recurringScheduler *worker.RecurringExchangeScheduler
+	startTime          time.Time
 }
 
-func NewHealthHandler(rateUpdater *worker.RateUpdater) *HealthHandler {
+func NewHealthHandler(
+	rateUpdater *worker.RateUpdater,
+	chainWatcher *worker.ChainWatcher,
+	recurringScheduler *worker.RecurringExchangeScheduler,
+) *HealthHandler {
 	return &HealthHandler{
-		rateUpdater: rateUpdater,
-		startTime:   time.Now(),
+		rateUpdater:        rateUpdater,
+		chainWatcher:       chainWatcher,
+		recurringScheduler: recurringScheduler,
+		startTime:          time.Now(),
 	}
 }
 
@@ -28,7 +36,9 @@ type HealthResponse struct {
 }
 
 type WorkersStatus struct {
-	RateUpdater worker.HealthStatus `json:"rate_updater"`
+	RateUpdater        worker.HealthStatus `json:"rate_updater"`
+	ChainWatcher       worker.HealthStatus `json:"chain_watcher"`
+	RecurringScheduler worker.HealthStatus `json:"recurring_scheduler"`
 }
 
 // Health returns basic health check
@@ -44,7 +54,9 @@ func (h *HealthHandler) HealthDetailed(w http.ResponseWriter, r *http.Request) {
 		Timestamp: time.Now(),
 		Uptime:    time.Since(h.startTime).String(),
 		Workers: WorkersStatus{
-			RateUpdater: h.rateUpdater.Health(),
+			RateUpdater:  h.rateUpdater.Health(),
+			ChainWatcher:       h.chainWatcher.Health(),
+			RecurringScheduler: h.recurringScheduler.Health(),
 		},
 	}
 
@@ -2,9 +2,11 @@ package middleware
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"strings"
 
+	"github.com/caspianex/exchange-backend/internal/domain"
 	"github.com/caspianex/exchange-backend/pkg/auth"
 )
 
@@ -14,15 +16,30 @@ const (
 	UserIDKey    contextKey = "user_id"
 	UserEmailKey contextKey = "user_email"
 	UserRoleKey  contextKey = "user_role"
+	SessionIDKey contextKey = "session_id"
 )
 
+// SessionRevocationList reports sessions whose access tokens must be rejected before they expire
+type SessionRevocationList interface {
+	IsSessionRevoked(sessionID int64) bool
+}
+
+// PermissionChecker reports whether a user's current role grants a permission
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID int64, permission domain.Permission) (bool, error)
+}
+
 type AuthMiddleware struct {
-	jwtManager *auth.JWTManager
+	jwtManager  *auth.JWTManager
+	revocations SessionRevocationList
+	permissions PermissionChecker
 }
 
-func NewAuthMiddleware(jwtManager *auth.JWTManager) *AuthMiddleware {
+func NewAuthMiddleware(jwtManager *auth.JWTManager, revocations SessionRevocationList, permissions PermissionChecker) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtManager: jwtManager,
+		jwtManager:  jwtManager,
+		revocations: revocations,
+		permissions: permissions,
 	}
 }
 
@@ -41,25 +58,40 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 		}
 
 		claims, err := m.jwtManager.ValidateToken(parts[1])
-		if err != nil {
+		if err != nil || claims.Type != string(auth.AccessToken) {
 			respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid or expired token"})
 			return
 		}
 
+		// Tokens without a session cannot be revoked, so they are not accepted either
+		if claims.SessionID == 0 || m.revocations.IsSessionRevoked(claims.SessionID) {
+			respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "session has been revoked"})
+			return
+		}
+
 		ctx := r.Context()
 		ctx = context.WithValue(ctx, UserIDKey, claims.UserID)
 		ctx = context.WithValue(ctx, UserEmailKey, claims.Email)
 		ctx = context.WithValue(ctx, UserRoleKey, claims.Role)
+		ctx = context.WithValue(ctx, SessionIDKey, claims.SessionID)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-func (m *AuthMiddleware) RequireRole(role string) func(http.Handler) http.Handler {
+// RequirePermission rejects users whose current role lacks permission. The role is looked up
+// on every request rather than taken from the token, so role changes apply immediately.
+func (m *AuthMiddleware) RequirePermission(permission domain.Permission) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			userRole, ok := r.Context().Value(UserRoleKey).(string)
-			if !ok || userRole != role {
+			userID, ok := GetUserID(r.Context())
+			if !ok {
+				respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+				return
+			}
+
+			allowed, err := m.permissions.HasPermission(r.Context(), userID, permission)
+			if err != nil || !allowed {
 				respondJSON(w, http.StatusForbidden, map[string]string{"error": "insufficient permissions"})
 				return
 			}
@@ -73,6 +105,21 @@ func GetUserID(ctx context.Context) (int64, bool) {
 	return userID, ok
 }
 
+func GetSessionID(ctx context.Context) (int64, bool) {
+	sessionID, ok := ctx.Value(SessionIDKey).(int64)
+	return sessionID, ok
+}
+
+// ClientIP returns the request's remote IP without the port. Behind a proxy it is only the
+// client's address when the router trusts forwarding headers.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func GetUserEmail(ctx context.Context) (string, bool) {
 	email, ok := ctx.Value(UserEmailKey).(string)
 	return email, ok
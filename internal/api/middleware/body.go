@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+)
+
+var errRequestBodyTooLarge = errors.New("request body is too large")
+
+// bufferBody reads the request body, up to limit bytes, and puts a copy back on r so the
+// handler sees exactly what was read. Longer bodies fail with errRequestBodyTooLarge rather
+// than being cut short.
+func bufferBody(r *http.Request, limit int64) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, errRequestBodyTooLarge
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// respondBodyError answers a request whose body bufferBody could not read
+func respondBodyError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errRequestBodyTooLarge) {
+		respondJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()})
+		return
+	}
+	respondJSON(w, http.StatusBadRequest, map[string]string{"error": "failed to read request body"})
+}
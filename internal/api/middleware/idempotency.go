@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/caspianex/exchange-backend/pkg/logger"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 1 << 20
+)
+
+// IdempotencyStore persists the first response per (user, key)
+type IdempotencyStore interface {
+	Acquire(ctx context.Context, key *domain.IdempotencyKey) (*domain.IdempotencyKey, error)
+	Complete(ctx context.Context, id int64, status int, body []byte) error
+	Release(ctx context.Context, id int64) error
+}
+
+// recordingWriter passes the response through while keeping a copy for the store
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(status int) {
+	rw.status = status
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// Idempotency replays the stored response when a request is retried with the same
+// Idempotency-Key header. Requests without the header pass through untouched.
+// Must be mounted after AuthMiddleware.Authenticate since keys are scoped per user.
+func Idempotency(store IdempotencyStore, ttl time.Duration, log *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				respondJSON(w, http.StatusBadRequest, map[string]string{"error": "idempotency key is too long"})
+				return
+			}
+
+			userID, ok := GetUserID(r.Context())
+			if !ok {
+				respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+				return
+			}
+
+			body, err := bufferBody(r, maxIdempotentRequestBytes)
+			if err != nil {
+				respondBodyError(w, err)
+				return
+			}
+
+			record := &domain.IdempotencyKey{
+				UserID:      userID,
+				Key:         key,
+				RequestHash: hashRequest(r, body),
+				ExpiresAt:   time.Now().Add(ttl),
+			}
+
+			existing, err := store.Acquire(r.Context(), record)
+			if err != nil {
+				log.Error("Failed to acquire idempotency key", "user_id", userID, "error", err)
+				respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+				return
+			}
+
+			if existing != nil {
+				if existing.RequestHash != record.RequestHash {
+					respondJSON(w, http.StatusConflict, map[string]string{"error": "idempotency key was already used for a different request"})
+					return
+				}
+				if !existing.IsCompleted() {
+					respondJSON(w, http.StatusConflict, map[string]string{"error": "a request with this idempotency key is still being processed"})
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set(IdempotentReplayedHeader, "true")
+				w.WriteHeader(*existing.ResponseStatus)
+				w.Write(existing.ResponseBody)
+				return
+			}
+
+			// Store writes must outlive a client that hung up mid-request
+			storeCtx := context.WithoutCancel(r.Context())
+
+			ctx, committed := database.TrackCommits(r.Context())
+			r = r.WithContext(ctx)
+
+			defer func() {
+				if p := recover(); p != nil {
+					// A panic after the commit still moved the money, so retries replay the failure
+					if committed() {
+						complete(storeCtx, store, log, record.ID, http.StatusInternalServerError, internalErrorBody)
+					} else if err := store.Release(storeCtx, record.ID); err != nil {
+						log.Error("Failed to release idempotency key", "id", record.ID, "error", err)
+					}
+					panic(p)
+				}
+			}()
+
+			rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rw, r)
+
+			// Server errors are not final, the client should be able to retry them, unless
+			// the request's changes were committed before the error
+			if rw.status >= http.StatusInternalServerError && !committed() {
+				if err := store.Release(storeCtx, record.ID); err != nil {
+					log.Error("Failed to release idempotency key", "id", record.ID, "error", err)
+				}
+				return
+			}
+
+			complete(storeCtx, store, log, record.ID, rw.status, rw.body.Bytes())
+		})
+	}
+}
+
+// internalErrorBody is stored for a request that panicked, as the response was never written
+var internalErrorBody = []byte(`{"error":"Internal server error"}`)
+
+func complete(ctx context.Context, store IdempotencyStore, log *logger.Logger, id int64, status int, body []byte) {
+	if err := store.Complete(ctx, id, status, body); err != nil {
+		log.Error("Failed to store idempotent response", "id", id, "error", err)
+	}
+}
+
+// hashRequest fingerprints a request so a reused key with a different payload is detected
+func hashRequest(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
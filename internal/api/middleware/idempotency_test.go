@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/caspianex/exchange-backend/pkg/logger"
+)
+
+// memoryIdempotencyStore keeps keys in a map, as IdempotencyRepository does in its table
+type memoryIdempotencyStore struct {
+	mu     sync.Mutex
+	nextID int64
+	keys   map[string]*domain.IdempotencyKey
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{keys: make(map[string]*domain.IdempotencyKey)}
+}
+
+func (s *memoryIdempotencyStore) Acquire(ctx context.Context, key *domain.IdempotencyKey) (*domain.IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.keys[key.Key]; ok {
+		copied := *existing
+		return &copied, nil
+	}
+
+	s.nextID++
+	key.ID = s.nextID
+	stored := *key
+	s.keys[key.Key] = &stored
+	return nil, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(ctx context.Context, id int64, status int, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range s.keys {
+		if key.ID == id {
+			key.ResponseStatus = &status
+			key.ResponseBody = append([]byte(nil), body...)
+		}
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, key := range s.keys {
+		if key.ID == id {
+			delete(s.keys, name)
+		}
+	}
+	return nil
+}
+
+// TestIdempotencyKeepsKeyOnceCommitted fails requests with and without a commit before the
+// failure. Only failures before anything was committed may run again on retry.
+func TestIdempotencyKeepsKeyOnceCommitted(t *testing.T) {
+	tests := []struct {
+		name   string
+		commit bool
+		panics bool
+		// wantRuns is how many times the handler runs over two attempts with one key
+		wantRuns int
+	}{
+		{name: "server error before commit", wantRuns: 2},
+		{name: "panic before commit", panics: true, wantRuns: 2},
+		{name: "server error after commit", commit: true, wantRuns: 1},
+		{name: "panic after commit", commit: true, panics: true, wantRuns: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := 0
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				runs++
+				if tt.commit {
+					// As WithTx does once the transaction commits
+					database.MarkCommitted(r.Context())
+				}
+				if tt.panics {
+					panic("after the money moved")
+				}
+				respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			})
+			handler := Idempotency(newMemoryIdempotencyStore(), time.Hour, logger.New("test"))(next)
+
+			var last *httptest.ResponseRecorder
+			for attempt := 0; attempt < 2; attempt++ {
+				last = serveIdempotent(t, handler, "key-1")
+			}
+
+			if runs != tt.wantRuns {
+				t.Fatalf("handler ran %d times, want %d", runs, tt.wantRuns)
+			}
+			if last.Code != http.StatusInternalServerError {
+				t.Fatalf("retry status = %d, want 500", last.Code)
+			}
+			if replayed := last.Header().Get(IdempotentReplayedHeader) == "true"; replayed != tt.commit {
+				t.Fatalf("retry replayed = %v, want %v", replayed, tt.commit)
+			}
+		})
+	}
+}
+
+func TestIdempotencyReplaysSuccess(t *testing.T) {
+	runs := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		runs++
+		respondJSON(w, http.StatusCreated, map[string]int{"id": runs})
+	})
+	handler := Idempotency(newMemoryIdempotencyStore(), time.Hour, logger.New("test"))(next)
+
+	first := serveIdempotent(t, handler, "key-1")
+	retry := serveIdempotent(t, handler, "key-1")
+
+	if runs != 1 {
+		t.Fatalf("handler ran %d times, want 1", runs)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Fatalf("retry = %d %q, want %d %q", retry.Code, retry.Body.String(), first.Code, first.Body.String())
+	}
+}
+
+// serveIdempotent sends a request with key, recovering a panic the way the router's
+// Recoverer middleware does
+func serveIdempotent(t *testing.T, handler http.Handler, key string) (w *httptest.ResponseRecorder) {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodPost, "/exchanges", strings.NewReader(`{"from_amount":"10"}`))
+	r.Header.Set(IdempotencyKeyHeader, key)
+	r = r.WithContext(context.WithValue(r.Context(), UserIDKey, int64(7)))
+	w = httptest.NewRecorder()
+
+	defer func() {
+		if p := recover(); p != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}()
+	handler.ServeHTTP(w, r)
+	return w
+}
@@ -45,6 +45,7 @@ func Logger(log *logger.Logger) func(http.Handler) http.Handler {
 				"duration_ms", duration.Milliseconds(),
 				"size", rw.size,
 				"remote_addr", r.RemoteAddr,
+				"request_id", GetRequestID(r.Context()),
 			)
 		})
 	}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/caspianex/exchange-backend/pkg/logger"
+	"github.com/caspianex/exchange-backend/pkg/ratelimit"
+)
+
+// RateLimitKeyFunc returns who a request is counted against
+type RateLimitKeyFunc func(r *http.Request) string
+
+// RateLimitByIP counts requests per client IP
+func RateLimitByIP(r *http.Request) string {
+	return "ip:" + ClientIP(r)
+}
+
+// RateLimitByUser counts requests per signed-in user, or per IP before authentication
+func RateLimitByUser(r *http.Request) string {
+	if userID, ok := GetUserID(r.Context()); ok {
+		return "user:" + strconv.FormatInt(userID, 10)
+	}
+	return RateLimitByIP(r)
+}
+
+// RateLimitPolicy is a named limit. Routes sharing a policy share its counters.
+type RateLimitPolicy struct {
+	Name  string
+	Limit ratelimit.Limit
+	Key   RateLimitKeyFunc
+}
+
+// RateLimit rejects requests over the policy's limit with 429 and sets X-RateLimit-Limit,
+// X-RateLimit-Remaining and X-RateLimit-Reset (seconds) on every response, plus Retry-After
+// when rejecting. A policy without requests is disabled. Requests are let through when the
+// store fails, so an outage of the store does not take the API down.
+func RateLimit(store ratelimit.Store, policy RateLimitPolicy, log *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if policy.Limit.Requests <= 0 || policy.Limit.Window <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := store.Allow(r.Context(), policy.Name+":"+policy.Key(r), policy.Limit)
+			if err != nil {
+				log.Error("Rate limit check failed", "policy", policy.Name, "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(result.RetryAfter))))
+				respondJSON(w, http.StatusTooManyRequests, map[string]string{"error": "rate limit exceeded"})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/caspianex/exchange-backend/pkg/logger"
+	"github.com/caspianex/exchange-backend/pkg/ratelimit"
+	"github.com/redis/go-redis/v9"
+)
+
+func newRateLimitedHandler(t *testing.T, limit ratelimit.Limit) (*miniredis.Miniredis, http.Handler) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	// Aligned to the windows below so the expected reset times are whole seconds
+	mr.SetTime(time.Unix(1_700_000_000, 0))
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	policy := RateLimitPolicy{Name: "test", Limit: limit, Key: RateLimitByIP}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	store := ratelimit.NewRedisStore(client, "rl:")
+	return mr, RateLimit(store, policy, logger.New("test"))(next)
+}
+
+func serve(handler http.Handler, remoteAddr string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// rateLimitResponse is the status and rate limit headers expected for one request
+type rateLimitResponse struct {
+	status     int
+	remaining  string
+	reset      string
+	retryAfter string
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit ratelimit.Limit
+		// wait is how long after the start of the window the requests are made
+		wait time.Duration
+		want []rateLimitResponse
+	}{
+		{
+			name:  "sliding window",
+			limit: ratelimit.Limit{Requests: 2, Window: 10 * time.Second, Algorithm: ratelimit.SlidingWindow},
+			wait:  time.Second,
+			want: []rateLimitResponse{
+				{http.StatusOK, "1", "9", ""},
+				{http.StatusOK, "0", "9", ""},
+				// The current window is full, so the next request fits once it has ended and
+				// the previous window's weight has fallen to half
+				{http.StatusTooManyRequests, "0", "9", "14"},
+			},
+		},
+		{
+			name:  "token bucket",
+			limit: ratelimit.Limit{Requests: 2, Window: 4 * time.Second, Algorithm: ratelimit.TokenBucket},
+			want: []rateLimitResponse{
+				{http.StatusOK, "1", "2", ""},
+				{http.StatusOK, "0", "4", ""},
+				{http.StatusTooManyRequests, "0", "4", "2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr, handler := newRateLimitedHandler(t, tt.limit)
+			mr.SetTime(time.Unix(1_700_000_000, 0).Add(tt.wait))
+
+			for i, want := range tt.want {
+				w := serve(handler, "203.0.113.7:1234")
+
+				if w.Code != want.status {
+					t.Fatalf("request %d: status = %d, want %d", i, w.Code, want.status)
+				}
+				header := w.Header()
+				if got := header.Get("X-RateLimit-Limit"); got != "2" {
+					t.Errorf("request %d: X-RateLimit-Limit = %q, want 2", i, got)
+				}
+				if got := header.Get("X-RateLimit-Remaining"); got != want.remaining {
+					t.Errorf("request %d: X-RateLimit-Remaining = %q, want %s", i, got, want.remaining)
+				}
+				if got := header.Get("X-RateLimit-Reset"); got != want.reset {
+					t.Errorf("request %d: X-RateLimit-Reset = %q, want %s", i, got, want.reset)
+				}
+				if got := header.Get("Retry-After"); got != want.retryAfter {
+					t.Errorf("request %d: Retry-After = %q, want %q", i, got, want.retryAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitCountsPerKey(t *testing.T) {
+	_, handler := newRateLimitedHandler(t, ratelimit.Limit{Requests: 1, Window: time.Minute, Algorithm: ratelimit.TokenBucket})
+
+	if w := serve(handler, "203.0.113.7:1234"); w.Code != http.StatusOK {
+		t.Fatalf("first client: status = %d, want 200", w.Code)
+	}
+	if w := serve(handler, "203.0.113.7:5678"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("first client from another port: status = %d, want 429", w.Code)
+	}
+	if w := serve(handler, "198.51.100.2:1234"); w.Code != http.StatusOK {
+		t.Fatalf("second client: status = %d, want 200", w.Code)
+	}
+}
+
+func TestRateLimitAllowsWhenStoreFails(t *testing.T) {
+	mr, handler := newRateLimitedHandler(t, ratelimit.Limit{Requests: 1, Window: time.Minute, Algorithm: ratelimit.TokenBucket})
+	mr.Close()
+
+	for i := 0; i < 3; i++ {
+		w := serve(handler, "203.0.113.7:1234")
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i, w.Code)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != "" {
+			t.Fatalf("request %d: X-RateLimit-Limit = %q, want none", i, got)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package wellknown
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/caspianex/exchange-backend/pkg/auth"
+)
+
+type JWKSHandler struct {
+	jwtManager *auth.JWTManager
+}
+
+func NewJWKSHandler(jwtManager *auth.JWTManager) *JWKSHandler {
+	return &JWKSHandler{jwtManager: jwtManager}
+}
+
+// JWKS publishes the public keys access tokens can be verified with. The set is empty
+// while tokens are signed HS256.
+func (h *JWKSHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	set := auth.JWKS{Keys: []auth.JWK{}}
+	if keys := h.jwtManager.Keys(); keys != nil {
+		set = keys.JWKS(time.Now())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// Keys are published before they start signing, so a short cache is enough for rotation
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(set)
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"net"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type APIKeyScope string
+
+const (
+	// APIKeyScopeRead allows reading wallets, transactions and exchanges
+	APIKeyScopeRead APIKeyScope = "read"
+	// APIKeyScopeTrade allows creating and canceling exchanges and recurring exchanges
+	APIKeyScopeTrade APIKeyScope = "trade"
+	// APIKeyScopeWithdraw allows withdrawals. Keys with it must have an IP allowlist.
+	APIKeyScopeWithdraw APIKeyScope = "withdraw"
+)
+
+// APIKey authenticates a programmatic client. Requests are signed with the key's secret,
+// which is only shown once when the key is created.
+type APIKey struct {
+	ID              int64          `db:"id" json:"id"`
+	UserID          int64          `db:"user_id" json:"user_id"`
+	Name            string         `db:"name" json:"name"`
+	KeyID           string         `db:"key_id" json:"key_id"`
+	SecretEncrypted string         `db:"secret_encrypted" json:"-"`
+	Scopes          pq.StringArray `db:"scopes" json:"scopes"`
+	AllowedIPs      pq.StringArray `db:"allowed_ips" json:"allowed_ips"`
+	LastUsedAt      *time.Time     `db:"last_used_at" json:"last_used_at,omitempty"`
+	LastUsedIP      *string        `db:"last_used_ip" json:"last_used_ip,omitempty"`
+	RevokedAt       *time.Time     `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt       time.Time      `db:"created_at" json:"created_at"`
+}
+
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	for _, s := range k.Scopes {
+		if APIKeyScope(s) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsIP reports whether ip matches the allowlist. An empty allowlist allows any address.
+func (k *APIKey) AllowsIP(ip string) bool {
+	if len(k.AllowedIPs) == 0 {
+		return true
+	}
+
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+
+	for _, entry := range k.AllowedIPs {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(addr) {
+				return true
+			}
+		} else if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyRequest is what a signed request presents for authentication
+type APIKeyRequest struct {
+	KeyID     string
+	Timestamp string
+	Nonce     string
+	Signature string
+	Method    string
+	URI       string
+	Body      []byte
+	IPAddress string
+}
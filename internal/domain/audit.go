@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditActionManualDeposit      AuditAction = "wallet.manual_deposit"
+	AuditActionExchangeRateCreate AuditAction = "exchange_rate.create"
+	AuditActionExchangeRateUpdate AuditAction = "exchange_rate.update"
+	AuditActionExchangeRateDelete AuditAction = "exchange_rate.delete"
+	AuditActionUserUpdateProfile  AuditAction = "user.update_profile"
+	AuditActionUserDeactivate     AuditAction = "user.deactivate"
+	AuditActionUserActivate       AuditAction = "user.activate"
+	AuditActionUserMarkVerified   AuditAction = "user.mark_verified"
+	AuditActionUserForceLogout    AuditAction = "user.force_logout"
+	AuditActionUserUnlock         AuditAction = "user.unlock"
+	AuditActionUserChangeRole     AuditAction = "user.change_role"
+	AuditActionUserSetFeeTier     AuditAction = "user.set_fee_tier"
+	AuditActionRoleCreate         AuditAction = "role.create"
+	AuditActionRoleUpdate         AuditAction = "role.update"
+	AuditActionRoleDelete         AuditAction = "role.delete"
+	AuditActionWithdrawalApprove  AuditAction = "withdrawal.approve"
+	AuditActionWithdrawalReject   AuditAction = "withdrawal.reject"
+	AuditActionWithdrawalSent     AuditAction = "withdrawal.mark_sent"
+	AuditActionFeeRuleCreate      AuditAction = "fee_rule.create"
+	AuditActionFeeRuleUpdate      AuditAction = "fee_rule.update"
+	AuditActionFeeRuleDelete      AuditAction = "fee_rule.delete"
+	AuditActionKYCApprove         AuditAction = "kyc.approve"
+	AuditActionKYCReject          AuditAction = "kyc.reject"
+	AuditActionKYCSetLimit        AuditAction = "kyc.set_limit"
+	AuditActionSessionRevoke      AuditAction = "session.revoke"
+)
+
+const (
+	AuditTargetTransaction  = "transaction"
+	AuditTargetExchangeRate = "exchange_rate"
+	AuditTargetUser         = "user"
+	AuditTargetRole         = "role"
+	AuditTargetFeeRule      = "fee_rule"
+	AuditTargetKYCApp       = "kyc_application"
+	AuditTargetKYCLimit     = "kyc_limit"
+	AuditTargetSession      = "session"
+)
+
+// AuditGenesisHash is the prev_hash of the first audit log entry
+var AuditGenesisHash = strings.Repeat("0", 64)
+
+// AuditMeta identifies who made a back-office change and the request it came in on
+type AuditMeta struct {
+	ActorID   int64
+	IPAddress string
+	RequestID string
+}
+
+// AuditEntry is one row of the append-only audit log. Hash covers every other field and
+// PrevHash, chaining each entry to the one before it.
+type AuditEntry struct {
+	ID          int64           `db:"id" json:"id"`
+	ActorID     *int64          `db:"actor_id" json:"actor_id,omitempty"`
+	Action      AuditAction     `db:"action" json:"action"`
+	TargetType  string          `db:"target_type" json:"target_type"`
+	TargetID    string          `db:"target_id" json:"target_id"`
+	BeforeState json.RawMessage `db:"before_state" json:"before"`
+	AfterState  json.RawMessage `db:"after_state" json:"after"`
+	IPAddress   string          `db:"ip_address" json:"ip_address"`
+	RequestID   string          `db:"request_id" json:"request_id"`
+	PrevHash    string          `db:"prev_hash" json:"prev_hash"`
+	Hash        string          `db:"hash" json:"hash"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+}
+
+// ComputeHash returns the hex SHA-256 of the entry's contents and PrevHash. The fields are
+// encoded as a JSON array so no combination of values can produce the same input as another.
+func (e *AuditEntry) ComputeHash() string {
+	data, _ := json.Marshal([]interface{}{
+		e.PrevHash,
+		e.ActorID,
+		e.Action,
+		e.TargetType,
+		e.TargetID,
+		string(e.BeforeState),
+		string(e.AfterState),
+		e.IPAddress,
+		e.RequestID,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditLogFilter narrows an audit log listing. Zero values match everything.
+type AuditLogFilter struct {
+	ActorID    int64
+	Action     string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+}
+
+// AuditChainStatus is the result of checking the audit log hash chain
+type AuditChainStatus struct {
+	Valid    bool   `json:"valid"`
+	Checked  int64  `json:"checked"`
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	// LatestHash is the hash of the newest entry when the chain is valid
+	LatestHash string `json:"latest_hash,omitempty"`
+}
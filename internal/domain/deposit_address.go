@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// DepositAddress is a blockchain address that incoming deposits for one user and currency are sent to.
+// Unallocated pool addresses have no UserID.
+type DepositAddress struct {
+	ID          int64      `db:"id" json:"id"`
+	CurrencyID  int32      `db:"currency_id" json:"currency_id"`
+	Network     string     `db:"network" json:"network"`
+	Address     string     `db:"address" json:"address"`
+	UserID      *int64     `db:"user_id" json:"user_id,omitempty"`
+	AllocatedAt *time.Time `db:"allocated_at" json:"allocated_at,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
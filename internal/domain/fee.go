@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type FeeOperation string
+type FeeRuleType string
+
+const (
+	FeeOperationWithdrawal FeeOperation = "withdrawal"
+	FeeOperationExchange   FeeOperation = "exchange"
+
+	// FeeRuleTypePercent charges Percent of the amount
+	FeeRuleTypePercent FeeRuleType = "percent"
+	// FeeRuleTypeFlat charges FlatAmount regardless of the amount
+	FeeRuleTypeFlat FeeRuleType = "flat"
+	// FeeRuleTypeTiered charges the percent and flat part of the first tier the amount falls into
+	FeeRuleTypeTiered FeeRuleType = "tiered"
+)
+
+const DefaultFeeTier = "standard"
+
+// FeeRule prices a withdrawal or exchange. Nil match fields match anything; when several rules
+// match, the highest priority wins, then the most specific one.
+//
+// Withdrawal fees are charged in the withdrawn currency on the withdrawn amount.
+// Exchange fees are charged in the to-currency on the gross to-amount, while amount bands and
+// tiers are matched against the from-amount the client entered.
+type FeeRule struct {
+	ID        int64        `db:"id" json:"id"`
+	Name      string       `db:"name" json:"name"`
+	Operation FeeOperation `db:"operation" json:"operation"`
+
+	CurrencyID     *int32           `db:"currency_id" json:"currency_id,omitempty"`
+	Network        *string          `db:"network" json:"network,omitempty"`
+	FromCurrencyID *int32           `db:"from_currency_id" json:"from_currency_id,omitempty"`
+	ToCurrencyID   *int32           `db:"to_currency_id" json:"to_currency_id,omitempty"`
+	UserTier       *string          `db:"user_tier" json:"user_tier,omitempty"`
+	MinAmount      *decimal.Decimal `db:"min_amount" json:"min_amount,omitempty"`
+	MaxAmount      *decimal.Decimal `db:"max_amount" json:"max_amount,omitempty"`
+
+	Type       FeeRuleType      `db:"type" json:"type"`
+	Percent    decimal.Decimal  `db:"percent" json:"percent"`
+	FlatAmount decimal.Decimal  `db:"flat_amount" json:"flat_amount"`
+	MinFee     *decimal.Decimal `db:"min_fee" json:"min_fee,omitempty"`
+	MaxFee     *decimal.Decimal `db:"max_fee" json:"max_fee,omitempty"`
+	Tiers      FeeTiers         `db:"tiers" json:"tiers,omitempty"`
+
+	Priority   int        `db:"priority" json:"priority"`
+	IsActive   bool       `db:"is_active" json:"is_active"`
+	ReplacedBy *int64     `db:"replaced_by" json:"replaced_by,omitempty"`
+	CreatedBy  *int64     `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	RetiredAt  *time.Time `db:"retired_at" json:"retired_at,omitempty"`
+}
+
+// FeeTier is one band of a tiered rule. A nil UpTo is the open-ended top band.
+type FeeTier struct {
+	UpTo    *decimal.Decimal `json:"up_to,omitempty"`
+	Percent decimal.Decimal  `json:"percent"`
+	Flat    decimal.Decimal  `json:"flat"`
+}
+
+// FeeTiers is stored as JSONB
+type FeeTiers []FeeTier
+
+func (t FeeTiers) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+func (t *FeeTiers) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return fmt.Errorf("cannot scan %T into FeeTiers", src)
+	}
+}
+
+// FeeContext is what a rule is matched against
+type FeeContext struct {
+	Operation      FeeOperation
+	CurrencyID     int32
+	Network        string
+	FromCurrencyID int32
+	ToCurrencyID   int32
+	UserTier       string
+	Amount         decimal.Decimal
+}
+
+// Matches reports whether every criterion set on the rule holds for the context
+func (r *FeeRule) Matches(fc *FeeContext) bool {
+	if r.Operation != fc.Operation {
+		return false
+	}
+	if r.CurrencyID != nil && *r.CurrencyID != fc.CurrencyID {
+		return false
+	}
+	if r.Network != nil && *r.Network != fc.Network {
+		return false
+	}
+	if r.FromCurrencyID != nil && *r.FromCurrencyID != fc.FromCurrencyID {
+		return false
+	}
+	if r.ToCurrencyID != nil && *r.ToCurrencyID != fc.ToCurrencyID {
+		return false
+	}
+	if r.UserTier != nil && *r.UserTier != fc.UserTier {
+		return false
+	}
+	if r.MinAmount != nil && fc.Amount.LessThan(*r.MinAmount) {
+		return false
+	}
+	if r.MaxAmount != nil && fc.Amount.GreaterThan(*r.MaxAmount) {
+		return false
+	}
+	return true
+}
+
+// Specificity counts the criteria the rule sets, used to break priority ties
+func (r *FeeRule) Specificity() int {
+	n := 0
+	for _, set := range []bool{
+		r.CurrencyID != nil, r.Network != nil, r.FromCurrencyID != nil, r.ToCurrencyID != nil,
+		r.UserTier != nil, r.MinAmount != nil || r.MaxAmount != nil,
+	} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+// ChargedCurrencyID returns the currency the fee is charged in, which flat amounts and fee
+// bounds are denominated in. It is nil when the rule matches several currencies.
+func (r *FeeRule) ChargedCurrencyID() *int32 {
+	if r.Operation == FeeOperationExchange {
+		return r.ToCurrencyID
+	}
+	return r.CurrencyID
+}
+
+// BandCurrencyID returns the currency amount bands and tier limits are denominated in.
+// It is nil when the rule matches several currencies.
+func (r *FeeRule) BandCurrencyID() *int32 {
+	if r.Operation == FeeOperationExchange {
+		return r.FromCurrencyID
+	}
+	return r.CurrencyID
+}
+
+// Calculate returns the unrounded fee on base. bandAmount selects the tier of a tiered rule.
+func (r *FeeRule) Calculate(base, bandAmount decimal.Decimal) decimal.Decimal {
+	hundred := decimal.NewFromInt(100)
+
+	var fee decimal.Decimal
+	switch r.Type {
+	case FeeRuleTypePercent:
+		fee = base.Mul(r.Percent).Div(hundred)
+	case FeeRuleTypeFlat:
+		fee = r.FlatAmount
+	case FeeRuleTypeTiered:
+		for _, tier := range r.Tiers {
+			if tier.UpTo == nil || bandAmount.LessThanOrEqual(*tier.UpTo) {
+				fee = base.Mul(tier.Percent).Div(hundred).Add(tier.Flat)
+				break
+			}
+		}
+	}
+
+	if r.MinFee != nil && fee.LessThan(*r.MinFee) {
+		fee = *r.MinFee
+	}
+	if r.MaxFee != nil && fee.GreaterThan(*r.MaxFee) {
+		fee = *r.MaxFee
+	}
+
+	return fee
+}
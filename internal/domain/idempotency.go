@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// IdempotencyKey is the stored outcome of a request sent with an Idempotency-Key header.
+// A key without a response status is still being processed.
+type IdempotencyKey struct {
+	ID             int64      `db:"id" json:"id"`
+	UserID         int64      `db:"user_id" json:"user_id"`
+	Key            string     `db:"idempotency_key" json:"key"`
+	RequestHash    string     `db:"request_hash" json:"request_hash"`
+	ResponseStatus *int       `db:"response_status" json:"response_status,omitempty"`
+	ResponseBody   []byte     `db:"response_body" json:"-"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	CompletedAt    *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+	ExpiresAt      time.Time  `db:"expires_at" json:"expires_at"`
+}
+
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.ResponseStatus != nil
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type KYCApplicationStatus string
+type KYCDocumentType string
+
+const (
+	KYCApplicationStatusPending  KYCApplicationStatus = "pending"
+	KYCApplicationStatusApproved KYCApplicationStatus = "approved"
+	KYCApplicationStatusRejected KYCApplicationStatus = "rejected"
+
+	KYCDocumentTypePassport       KYCDocumentType = "passport"
+	KYCDocumentTypeIDCard         KYCDocumentType = "id_card"
+	KYCDocumentTypeSelfie         KYCDocumentType = "selfie"
+	KYCDocumentTypeProofOfAddress KYCDocumentType = "proof_of_address"
+)
+
+// MaxKYCLevel is the highest level an application can request
+const MaxKYCLevel = 2
+
+// KYCLevelRequirements lists, per level, the document groups an application for that level
+// must include. Any one type of a group satisfies it.
+var KYCLevelRequirements = map[int16][][]KYCDocumentType{
+	1: {
+		{KYCDocumentTypePassport, KYCDocumentTypeIDCard},
+		{KYCDocumentTypeSelfie},
+	},
+	2: {
+		{KYCDocumentTypeProofOfAddress},
+	},
+}
+
+// KYCApplication asks for the user's KYC level to be raised to Level
+type KYCApplication struct {
+	ID         int64                `db:"id" json:"id"`
+	UserID     int64                `db:"user_id" json:"user_id"`
+	Level      int16                `db:"level" json:"level"`
+	Status     KYCApplicationStatus `db:"status" json:"status"`
+	Reason     string               `db:"reason" json:"reason,omitempty"`
+	ReviewedBy *int64               `db:"reviewed_by" json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time           `db:"reviewed_at" json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time            `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time            `db:"updated_at" json:"updated_at"`
+
+	Documents []KYCDocument `db:"-" json:"documents,omitempty"`
+}
+
+// KYCDocument is an uploaded file. ApplicationID is nil until the document is submitted.
+type KYCDocument struct {
+	ID            int64           `db:"id" json:"id"`
+	UserID        int64           `db:"user_id" json:"user_id"`
+	ApplicationID *int64          `db:"application_id" json:"application_id,omitempty"`
+	Type          KYCDocumentType `db:"type" json:"type"`
+	StorageKey    string          `db:"storage_key" json:"-"`
+	FileName      string          `db:"file_name" json:"file_name"`
+	ContentType   string          `db:"content_type" json:"content_type"`
+	SizeBytes     int64           `db:"size_bytes" json:"size_bytes"`
+	CreatedAt     time.Time       `db:"created_at" json:"created_at"`
+}
+
+// KYCLimit caps daily volume in one currency for users at Level. Nil is unlimited.
+// Exchange volume counts the from-amount of exchanges out of the currency.
+type KYCLimit struct {
+	Level                int16            `db:"level" json:"level"`
+	CurrencyID           int32            `db:"currency_id" json:"currency_id"`
+	CurrencyCode         string           `db:"currency_code" json:"currency_code"`
+	DailyWithdrawalLimit *decimal.Decimal `db:"daily_withdrawal_limit" json:"daily_withdrawal_limit"`
+	DailyExchangeLimit   *decimal.Decimal `db:"daily_exchange_limit" json:"daily_exchange_limit"`
+	UpdatedAt            time.Time        `db:"updated_at" json:"updated_at"`
+}
+
+// KYCStatus is the user's view of their verification
+type KYCStatus struct {
+	Level             int16           `json:"level"`
+	LatestApplication *KYCApplication `json:"latest_application,omitempty"`
+	PendingDocuments  []KYCDocument   `json:"pending_documents"`
+	Limits            []KYCLimit      `json:"limits"`
+}
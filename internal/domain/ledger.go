@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type LedgerJournalType string
+
+const (
+	LedgerJournalTypeDeposit           LedgerJournalType = "deposit"
+	LedgerJournalTypeWithdrawal        LedgerJournalType = "withdrawal"
+	LedgerJournalTypeWithdrawalHold    LedgerJournalType = "withdrawal_hold"
+	LedgerJournalTypeWithdrawalRelease LedgerJournalType = "withdrawal_release"
+	LedgerJournalTypeExchange          LedgerJournalType = "exchange"
+	LedgerJournalTypeExchangeHold      LedgerJournalType = "exchange_hold"
+	LedgerJournalTypeExchangeRelease   LedgerJournalType = "exchange_release"
+	LedgerJournalTypeOpeningBalance    LedgerJournalType = "opening_balance"
+)
+
+// System ledger accounts. User funds live in per-wallet accounts, see WalletLedgerAccount.
+const (
+	LedgerAccountExternal       = "system:external"
+	LedgerAccountExchange       = "system:exchange"
+	LedgerAccountFees           = "system:fees"
+	LedgerAccountOpeningBalance = "system:opening_balance"
+)
+
+// Reference types a journal can point at
+const (
+	LedgerReferenceTransaction = "transaction"
+	LedgerReferenceExchange    = "exchange"
+	LedgerReferenceWallet      = "wallet"
+)
+
+type LedgerJournal struct {
+	ID            int64             `db:"id" json:"id"`
+	UID           string            `db:"uid" json:"uid"`
+	Type          LedgerJournalType `db:"type" json:"type"`
+	ReferenceType string            `db:"reference_type" json:"reference_type"`
+	ReferenceID   int64             `db:"reference_id" json:"reference_id"`
+	Description   string            `db:"description" json:"description"`
+	CreatedAt     time.Time         `db:"created_at" json:"created_at"`
+	Postings      []LedgerPosting   `db:"-" json:"postings,omitempty"`
+}
+
+// LedgerPosting is a single signed leg of a journal. Postings of one journal sum to zero per currency.
+type LedgerPosting struct {
+	ID         int64           `db:"id" json:"id"`
+	JournalID  int64           `db:"journal_id" json:"journal_id"`
+	Account    string          `db:"account" json:"account"`
+	WalletID   *int64          `db:"wallet_id" json:"wallet_id,omitempty"`
+	CurrencyID int32           `db:"currency_id" json:"currency_id"`
+	Amount     decimal.Decimal `db:"amount" json:"amount"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+}
+
+// WalletLedgerMismatch is a wallet whose stored balance disagrees with its ledger postings
+type WalletLedgerMismatch struct {
+	WalletID     int64           `db:"wallet_id" json:"wallet_id"`
+	UserID       int64           `db:"user_id" json:"user_id"`
+	CurrencyID   int32           `db:"currency_id" json:"currency_id"`
+	Balance      decimal.Decimal `db:"balance" json:"balance"`
+	LedgerAmount decimal.Decimal `db:"ledger_balance" json:"ledger_balance"`
+	Locked       decimal.Decimal `db:"locked" json:"locked"`
+	LedgerLocked decimal.Decimal `db:"ledger_locked" json:"ledger_locked"`
+}
+
+// WalletLedgerAccount returns the ledger account holding a wallet's available balance
+func WalletLedgerAccount(walletID int64) string {
+	return fmt.Sprintf("wallet:%d", walletID)
+}
+
+// WalletLockedLedgerAccount returns the ledger account holding a wallet's locked balance
+func WalletLockedLedgerAccount(walletID int64) string {
+	return fmt.Sprintf("wallet:%d:locked", walletID)
+}
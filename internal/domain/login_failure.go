@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// LoginFailureScope is what a run of failed sign-ins is counted against
+type LoginFailureScope string
+
+const (
+	// LoginFailureScopeAccount counts failures per email address tried, whether or not it has an account
+	LoginFailureScopeAccount LoginFailureScope = "account"
+	LoginFailureScopeIP      LoginFailureScope = "ip"
+	// LoginFailureScopeMFAToken counts second-factor attempts per mfa_pending token, by jti
+	LoginFailureScopeMFAToken LoginFailureScope = "mfa_token"
+)
+
+// LoginFailure is a run of failed sign-ins. It starts over once no failure was seen for a while.
+type LoginFailure struct {
+	Scope         LoginFailureScope `db:"scope" json:"scope"`
+	Subject       string            `db:"subject" json:"subject"`
+	Failures      int               `db:"failures" json:"failures"`
+	LastFailureAt time.Time         `db:"last_failure_at" json:"last_failure_at"`
+	LockedUntil   *time.Time        `db:"locked_until" json:"locked_until,omitempty"`
+}
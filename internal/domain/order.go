@@ -2,6 +2,8 @@ package domain
 
 import (
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type CurrencyExchangeStatus string
@@ -10,23 +12,36 @@ const (
 	CurrencyExchangeStatusPending   CurrencyExchangeStatus = "pending"
 	CurrencyExchangeStatusCompleted CurrencyExchangeStatus = "completed"
 	CurrencyExchangeStatusCanceled  CurrencyExchangeStatus = "canceled"
+	CurrencyExchangeStatusExpired   CurrencyExchangeStatus = "expired"
 )
 
 type CurrencyExchange struct {
-	ID               int64                  `db:"id" json:"id"`
-	UID              string                 `db:"uid" json:"uid"`
-	UserID           int64                  `db:"user_id" json:"user_id"`
-	Email            string                 `db:"email" json:"email,omitempty"`
-	FromCurrencyID   int32                  `db:"from_currency_id" json:"from_currency_id"`
-	ToCurrencyID     int32                  `db:"to_currency_id" json:"to_currency_id"`
-	FromAmount       float64                `db:"from_amount" json:"from_amount"`
-	ToAmount         float64                `db:"to_amount" json:"to_amount"`
-	ToAmountWithFee  float64                `db:"to_amount_with_fee" json:"to_amount_with_fee"`
-	ExchangeRate     float64                `db:"exchange_rate" json:"exchange_rate"`
-	Fee              float64                `db:"fee" json:"fee"`
-	Status           CurrencyExchangeStatus `db:"status" json:"status"`
-	CreatedAt        time.Time              `db:"created_at" json:"created_at"`
-	UpdatedAt        time.Time              `db:"updated_at" json:"updated_at"`
+	ID              int64                  `db:"id" json:"id"`
+	UID             string                 `db:"uid" json:"uid"`
+	UserID          int64                  `db:"user_id" json:"user_id"`
+	Email           string                 `db:"email" json:"email,omitempty"`
+	FromCurrencyID  int32                  `db:"from_currency_id" json:"from_currency_id"`
+	ToCurrencyID    int32                  `db:"to_currency_id" json:"to_currency_id"`
+	FromAmount      decimal.Decimal        `db:"from_amount" json:"from_amount"`
+	ToAmount        decimal.Decimal        `db:"to_amount" json:"to_amount"`
+	ToAmountWithFee decimal.Decimal        `db:"to_amount_with_fee" json:"to_amount_with_fee"`
+	ExchangeRate    decimal.Decimal        `db:"exchange_rate" json:"exchange_rate"`
+	Fee             decimal.Decimal        `db:"fee" json:"fee"`
+	FeeAmount       decimal.Decimal        `db:"fee_amount" json:"fee_amount"`
+	FeeRuleID       *int64                 `db:"fee_rule_id" json:"fee_rule_id,omitempty"`
+	TargetRate      *decimal.Decimal       `db:"target_rate" json:"target_rate,omitempty"`
+	ExpiresAt       *time.Time             `db:"expires_at" json:"expires_at,omitempty"`
+	Status          CurrencyExchangeStatus `db:"status" json:"status"`
+	CreatedAt       time.Time              `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time              `db:"updated_at" json:"updated_at"`
+}
+
+// LimitMatch is a pending limit exchange whose pair rate has reached its target, with the
+// pair's rate and fee at the time it matched
+type LimitMatch struct {
+	CurrencyExchange
+	MarketRate decimal.Decimal `db:"market_rate"`
+	MarketFee  decimal.Decimal `db:"market_fee"`
 }
 
 type CurrencyExchangeWithDetails struct {
@@ -44,11 +59,15 @@ type CurrencyExchangeWithCurrencies struct {
 	Email           string                 `db:"email"`
 	FromCurrencyID  int32                  `db:"from_currency_id"`
 	ToCurrencyID    int32                  `db:"to_currency_id"`
-	FromAmount      float64                `db:"from_amount"`
-	ToAmount        float64                `db:"to_amount"`
-	ToAmountWithFee float64                `db:"to_amount_with_fee"`
-	ExchangeRate    float64                `db:"exchange_rate"`
-	Fee             float64                `db:"fee"`
+	FromAmount      decimal.Decimal        `db:"from_amount"`
+	ToAmount        decimal.Decimal        `db:"to_amount"`
+	ToAmountWithFee decimal.Decimal        `db:"to_amount_with_fee"`
+	ExchangeRate    decimal.Decimal        `db:"exchange_rate"`
+	Fee             decimal.Decimal        `db:"fee"`
+	FeeAmount       decimal.Decimal        `db:"fee_amount"`
+	FeeRuleID       *int64                 `db:"fee_rule_id"`
+	TargetRate      *decimal.Decimal       `db:"target_rate"`
+	ExpiresAt       *time.Time             `db:"expires_at"`
 	Status          CurrencyExchangeStatus `db:"status"`
 	CreatedAt       time.Time              `db:"created_at"`
 	UpdatedAt       time.Time              `db:"updated_at"`
@@ -69,21 +88,39 @@ func (c *CurrencyExchangeWithCurrencies) ToCurrencyExchange() *CurrencyExchange
 		ToAmountWithFee: c.ToAmountWithFee,
 		ExchangeRate:    c.ExchangeRate,
 		Fee:             c.Fee,
+		FeeAmount:       c.FeeAmount,
+		FeeRuleID:       c.FeeRuleID,
+		TargetRate:      c.TargetRate,
+		ExpiresAt:       c.ExpiresAt,
 		Status:          c.Status,
 		CreatedAt:       c.CreatedAt,
 		UpdatedAt:       c.UpdatedAt,
 	}
 }
 
+// IsLimit reports whether the exchange waits for a target rate instead of executing immediately
+func (c *CurrencyExchange) IsLimit() bool {
+	return c.TargetRate != nil
+}
+
 type ExchangeRate struct {
-	ID             int64     `db:"id" json:"id"`
-	FromCurrencyID int32     `db:"from_currency_id" json:"from_currency_id"`
-	ToCurrencyID   int32     `db:"to_currency_id" json:"to_currency_id"`
-	Rate           float64   `db:"rate" json:"rate"`
-	Fee            float64   `db:"fee" json:"fee"`
-	IsActive       bool      `db:"is_active" json:"is_active"`
-	CreatedAt      time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+	ID             int64           `db:"id" json:"id"`
+	FromCurrencyID int32           `db:"from_currency_id" json:"from_currency_id"`
+	ToCurrencyID   int32           `db:"to_currency_id" json:"to_currency_id"`
+	Rate           decimal.Decimal `db:"rate" json:"rate"`
+	Fee            decimal.Decimal `db:"fee" json:"fee"`
+	IsActive       bool            `db:"is_active" json:"is_active"`
+
+	// Trading limits on the from-amount. Nil means unlimited.
+	MinAmount  *decimal.Decimal `db:"min_amount" json:"min_amount,omitempty"`
+	MaxAmount  *decimal.Decimal `db:"max_amount" json:"max_amount,omitempty"`
+	DailyLimit *decimal.Decimal `db:"daily_limit" json:"daily_limit,omitempty"`
+	// AmountPrecision is the number of decimal places allowed in the from-amount.
+	// Nil falls back to the from-currency's decimals.
+	AmountPrecision *int32 `db:"amount_precision" json:"amount_precision,omitempty"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
 
 type ExchangeRateWithCurrencies struct {
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ExchangeQuote is a priced exchange the user can execute unchanged until ExpiresAt
+type ExchangeQuote struct {
+	ID              int64           `db:"id" json:"-"`
+	UID             string          `db:"uid" json:"quote_id"`
+	UserID          int64           `db:"user_id" json:"user_id"`
+	FromCurrencyID  int32           `db:"from_currency_id" json:"from_currency_id"`
+	ToCurrencyID    int32           `db:"to_currency_id" json:"to_currency_id"`
+	FromAmount      decimal.Decimal `db:"from_amount" json:"from_amount"`
+	ToAmount        decimal.Decimal `db:"to_amount" json:"to_amount"`
+	ToAmountWithFee decimal.Decimal `db:"to_amount_with_fee" json:"to_amount_with_fee"`
+	ExchangeRate    decimal.Decimal `db:"exchange_rate" json:"exchange_rate"`
+	Fee             decimal.Decimal `db:"fee" json:"fee"`
+	FeeAmount       decimal.Decimal `db:"fee_amount" json:"fee_amount"`
+	FeeRuleID       *int64          `db:"fee_rule_id" json:"fee_rule_id,omitempty"`
+	ExpiresAt       time.Time       `db:"expires_at" json:"expires_at"`
+	ExchangeID      *int64          `db:"exchange_id" json:"exchange_id,omitempty"`
+	ExecutedAt      *time.Time      `db:"executed_at" json:"executed_at,omitempty"`
+	CreatedAt       time.Time       `db:"created_at" json:"created_at"`
+}
+
+func (q *ExchangeQuote) IsExecuted() bool {
+	return q.ExchangeID != nil
+}
+
+func (q *ExchangeQuote) IsExpired(now time.Time) bool {
+	return !now.Before(q.ExpiresAt)
+}
+
+// QuoteConversionStats summarises how many quotes for a pair turned into exchanges
+type QuoteConversionStats struct {
+	FromCurrencyCode string  `db:"from_currency_code" json:"from_currency_code"`
+	ToCurrencyCode   string  `db:"to_currency_code" json:"to_currency_code"`
+	Quotes           int64   `db:"quotes" json:"quotes"`
+	Executed         int64   `db:"executed" json:"executed"`
+	Expired          int64   `db:"expired" json:"expired"`
+	ConversionRate   float64 `db:"-" json:"conversion_rate"`
+}
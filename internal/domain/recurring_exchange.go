@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type RecurringExchangeStatus string
+type RecurringExchangeRunStatus string
+
+const (
+	RecurringExchangeStatusActive   RecurringExchangeStatus = "active"
+	RecurringExchangeStatusPaused   RecurringExchangeStatus = "paused"
+	RecurringExchangeStatusCanceled RecurringExchangeStatus = "canceled"
+
+	RecurringExchangeRunStatusSucceeded RecurringExchangeRunStatus = "succeeded"
+	RecurringExchangeRunStatusFailed    RecurringExchangeRunStatus = "failed"
+)
+
+// RecurringExchange swaps FromAmount on a cron or fixed-interval schedule.
+// Exactly one of CronExpression and IntervalSeconds is set.
+type RecurringExchange struct {
+	ID                  int64                   `db:"id" json:"id"`
+	UserID              int64                   `db:"user_id" json:"user_id"`
+	FromCurrencyID      int32                   `db:"from_currency_id" json:"from_currency_id"`
+	ToCurrencyID        int32                   `db:"to_currency_id" json:"to_currency_id"`
+	FromCurrencyCode    string                  `db:"from_currency_code" json:"from_currency_code"`
+	ToCurrencyCode      string                  `db:"to_currency_code" json:"to_currency_code"`
+	FromAmount          decimal.Decimal         `db:"from_amount" json:"from_amount"`
+	CronExpression      *string                 `db:"cron_expression" json:"cron_expression,omitempty"`
+	IntervalSeconds     *int64                  `db:"interval_seconds" json:"interval_seconds,omitempty"`
+	Status              RecurringExchangeStatus `db:"status" json:"status"`
+	NextRunAt           time.Time               `db:"next_run_at" json:"next_run_at"`
+	LastRunAt           *time.Time              `db:"last_run_at" json:"last_run_at,omitempty"`
+	ConsecutiveFailures int                     `db:"consecutive_failures" json:"consecutive_failures"`
+	PauseReason         string                  `db:"pause_reason" json:"pause_reason,omitempty"`
+	CreatedAt           time.Time               `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time               `db:"updated_at" json:"updated_at"`
+}
+
+type RecurringExchangeRun struct {
+	ID                  int64                      `db:"id" json:"id"`
+	RecurringExchangeID int64                      `db:"recurring_exchange_id" json:"recurring_exchange_id"`
+	ExchangeID          *int64                     `db:"exchange_id" json:"exchange_id,omitempty"`
+	Status              RecurringExchangeRunStatus `db:"status" json:"status"`
+	Error               string                     `db:"error" json:"error,omitempty"`
+	ScheduledAt         time.Time                  `db:"scheduled_at" json:"scheduled_at"`
+	CreatedAt           time.Time                  `db:"created_at" json:"created_at"`
+}
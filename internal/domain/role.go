@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Permission names one back-office capability. Admin routes each require one.
+type Permission string
+
+const (
+	// PermissionAll grants every permission, including ones added later
+	PermissionAll Permission = "*"
+
+	PermissionUsersRead              Permission = "users:read"
+	PermissionUsersWrite             Permission = "users:write"
+	PermissionRolesManage            Permission = "roles:manage"
+	PermissionSecurityEventsRead     Permission = "security_events:read"
+	PermissionAuditLogRead           Permission = "audit_log:read"
+	PermissionExchangesRead          Permission = "exchanges:read"
+	PermissionExchangeRatesRead      Permission = "exchange_rates:read"
+	PermissionExchangeRatesWrite     Permission = "exchange_rates:write"
+	PermissionDepositsCreate         Permission = "deposits:create"
+	PermissionDepositAddressesImport Permission = "deposit_addresses:import"
+	PermissionWithdrawalsRead        Permission = "withdrawals:read"
+	PermissionWithdrawalsManage      Permission = "withdrawals:manage"
+	PermissionFeeRulesRead           Permission = "fee_rules:read"
+	PermissionFeeRulesWrite          Permission = "fee_rules:write"
+	PermissionKYCRead                Permission = "kyc:read"
+	PermissionKYCReview              Permission = "kyc:review"
+	PermissionKYCLimitsWrite         Permission = "kyc_limits:write"
+	PermissionLedgerRead             Permission = "ledger:read"
+	PermissionChainMock              Permission = "chain_mock:use"
+)
+
+// PermissionInfo describes a permission for the role management API
+type PermissionInfo struct {
+	Name        Permission `json:"name"`
+	Description string     `json:"description"`
+}
+
+// Permissions lists every permission a role can be granted
+var Permissions = []PermissionInfo{
+	{PermissionUsersRead, "View users and their sessions"},
+	{PermissionUsersWrite, "Change users and revoke their sessions"},
+	{PermissionRolesManage, "Manage roles and assign them to users"},
+	{PermissionSecurityEventsRead, "View security events"},
+	{PermissionAuditLogRead, "View and verify the audit log"},
+	{PermissionExchangesRead, "View exchanges and quote statistics"},
+	{PermissionExchangeRatesRead, "View exchange rates"},
+	{PermissionExchangeRatesWrite, "Create, change and delete exchange rates"},
+	{PermissionDepositsCreate, "Credit manual deposits"},
+	{PermissionDepositAddressesImport, "Import deposit addresses"},
+	{PermissionWithdrawalsRead, "View withdrawals"},
+	{PermissionWithdrawalsManage, "Approve, reject and mark withdrawals sent"},
+	{PermissionFeeRulesRead, "View fee rules"},
+	{PermissionFeeRulesWrite, "Create, change and delete fee rules"},
+	{PermissionKYCRead, "View KYC applications, documents and limits"},
+	{PermissionKYCReview, "Approve and reject KYC applications"},
+	{PermissionKYCLimitsWrite, "Change KYC limits"},
+	{PermissionLedgerRead, "View the ledger and reconciliation"},
+	{PermissionChainMock, "Drive the in-memory mock chains"},
+}
+
+func IsPermission(p Permission) bool {
+	if p == PermissionAll {
+		return true
+	}
+	for _, info := range Permissions {
+		if info.Name == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Role is a named set of permissions assigned to users
+type Role struct {
+	Name        string         `db:"name" json:"name"`
+	Description string         `db:"description" json:"description"`
+	Permissions pq.StringArray `db:"permissions" json:"permissions"`
+	IsSystem    bool           `db:"is_system" json:"is_system"`
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+func (r *Role) Has(permission Permission) bool {
+	for _, p := range r.Permissions {
+		if Permission(p) == PermissionAll || Permission(p) == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers reports whether r holds every permission of other, so granting other adds nothing r lacks
+func (r *Role) Covers(other *Role) bool {
+	for _, p := range other.Permissions {
+		if !r.Has(Permission(p)) {
+			return false
+		}
+	}
+	return true
+}
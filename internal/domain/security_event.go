@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+type SecurityEventType string
+
+const (
+	// SecurityEventRefreshTokenReuse is an already rotated refresh token presented again.
+	// The session it belonged to is revoked.
+	SecurityEventRefreshTokenReuse SecurityEventType = "refresh_token_reuse"
+	// SecurityEventAccountLocked is sign-in locked after repeated failed attempts
+	SecurityEventAccountLocked SecurityEventType = "account_locked"
+)
+
+// SecurityEvent records something an account owner or admin may need to investigate
+type SecurityEvent struct {
+	ID        int64             `db:"id" json:"id"`
+	UserID    *int64            `db:"user_id" json:"user_id,omitempty"`
+	Type      SecurityEventType `db:"type" json:"type"`
+	SessionID *int64            `db:"session_id" json:"session_id,omitempty"`
+	IPAddress string            `db:"ip_address" json:"ip_address"`
+	UserAgent string            `db:"user_agent" json:"user_agent"`
+	Details   string            `db:"details" json:"details"`
+	CreatedAt time.Time         `db:"created_at" json:"created_at"`
+}
@@ -2,6 +2,8 @@ package domain
 
 import (
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type TransactionType string
@@ -12,20 +14,37 @@ const (
 	TransactionTypeWithdrawal TransactionType = "withdrawal"
 
 	TransactionStatusPending   TransactionStatus = "pending"
+	TransactionStatusApproved  TransactionStatus = "approved"
 	TransactionStatusCompleted TransactionStatus = "completed"
+	TransactionStatusRejected  TransactionStatus = "rejected"
 	TransactionStatusFailed    TransactionStatus = "failed"
 	TransactionStatusCanceled  TransactionStatus = "canceled"
 )
 
 type Transaction struct {
-	ID        int64             `db:"id" json:"id"`
-	UserID    int64             `db:"user_id" json:"user_id"`
-	WalletID  int64             `db:"wallet_id" json:"wallet_id"`
-	Type      TransactionType   `db:"type" json:"type"`
-	Amount    float64           `db:"amount" json:"amount"`
-	Fee       float64           `db:"fee" json:"fee"`
-	Status    TransactionStatus `db:"status" json:"status"`
-	TxHash    string            `db:"tx_hash" json:"tx_hash,omitempty"`
-	CreatedAt time.Time         `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time         `db:"updated_at" json:"updated_at"`
+	ID            int64             `db:"id" json:"id"`
+	UserID        int64             `db:"user_id" json:"user_id"`
+	WalletID      int64             `db:"wallet_id" json:"wallet_id"`
+	Type          TransactionType   `db:"type" json:"type"`
+	Amount        decimal.Decimal   `db:"amount" json:"amount"`
+	Fee           decimal.Decimal   `db:"fee" json:"fee"`
+	FeeRuleID     *int64            `db:"fee_rule_id" json:"fee_rule_id,omitempty"`
+	Status        TransactionStatus `db:"status" json:"status"`
+	TxHash        string            `db:"tx_hash" json:"tx_hash,omitempty"`
+	OutputIndex   int               `db:"output_index" json:"output_index"`
+	Address       string            `db:"address" json:"address,omitempty"`
+	Network       string            `db:"network" json:"network,omitempty"`
+	BlockHeight   *int64            `db:"block_height" json:"block_height,omitempty"`
+	Confirmations int64             `db:"confirmations" json:"confirmations"`
+	ReviewedBy    *int64            `db:"reviewed_by" json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time        `db:"reviewed_at" json:"reviewed_at,omitempty"`
+	RejectReason  string            `db:"reject_reason" json:"reject_reason,omitempty"`
+	Description   string            `db:"description" json:"description,omitempty"`
+	CreatedAt     time.Time         `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time         `db:"updated_at" json:"updated_at"`
+}
+
+// HeldAmount is what a withdrawal keeps in Wallet.Locked until it is sent or rejected
+func (t *Transaction) HeldAmount() decimal.Decimal {
+	return t.Amount.Add(t.Fee)
 }
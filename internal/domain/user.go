@@ -6,9 +6,11 @@ import (
 
 type UserRole string
 
+// UserRole names a Role. Client and super admin are system roles; the others are managed
+// through the admin API.
 const (
-	UserRoleClient UserRole = "client"
-	UserRoleAdmin  UserRole = "admin"
+	UserRoleClient     UserRole = "client"
+	UserRoleSuperAdmin UserRole = "super_admin"
 )
 
 type User struct {
@@ -20,14 +22,90 @@ type User struct {
 	Role         UserRole  `db:"role" json:"role"`
 	IsActive     bool      `db:"is_active" json:"is_active"`
 	IsVerified   bool      `db:"is_verified" json:"is_verified"`
+	FeeTier      string    `db:"fee_tier" json:"fee_tier"`
+	KYCLevel     int16     `db:"kyc_level" json:"kyc_level"`
 	CreatedAt    time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+
+	// TOTPSecret is set by 2FA setup; TOTPEnabled once the first code is confirmed
+	TOTPSecret   *string `db:"totp_secret" json:"-"`
+	TOTPEnabled  bool    `db:"totp_enabled" json:"totp_enabled"`
+	TOTPLastStep *int64  `db:"totp_last_step" json:"-"`
+}
+
+type UserTokenPurpose string
+
+const (
+	UserTokenPurposeEmailVerification UserTokenPurpose = "email_verification"
+	UserTokenPurposePasswordReset     UserTokenPurpose = "password_reset"
+)
+
+// UserToken is a single-use token sent to the user by email
+type UserToken struct {
+	ID        int64            `db:"id" json:"id"`
+	UserID    int64            `db:"user_id" json:"user_id"`
+	Purpose   UserTokenPurpose `db:"purpose" json:"purpose"`
+	TokenHash string           `db:"token_hash" json:"-"`
+	ExpiresAt time.Time        `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time       `db:"used_at" json:"used_at,omitempty"`
+	CreatedAt time.Time        `db:"created_at" json:"created_at"`
 }
 
 type UserSession struct {
-	ID           int64     `db:"id" json:"id"`
-	UserID       int64     `db:"user_id" json:"user_id"`
-	RefreshToken string    `db:"refresh_token" json:"-"`
-	ExpiresAt    time.Time `db:"expires_at" json:"expires_at"`
-	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	ID               int64     `db:"id" json:"id"`
+	UserID           int64     `db:"user_id" json:"user_id"`
+	RefreshTokenHash string    `db:"refresh_token_hash" json:"-"`
+	ExpiresAt        time.Time `db:"expires_at" json:"expires_at"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+	UserAgent        string    `db:"user_agent" json:"user_agent"`
+	IPAddress        string    `db:"ip_address" json:"ip_address"`
+	LastUsedAt       time.Time `db:"last_used_at" json:"last_used_at"`
+	RememberMe       bool      `db:"remember_me" json:"remember_me"`
+
+	// Current marks the session of the request that listed it
+	Current bool `db:"-" json:"current"`
+}
+
+// RotatedRefreshToken is a refresh token its session has already replaced
+type RotatedRefreshToken struct {
+	TokenHash string    `db:"token_hash"`
+	SessionID int64     `db:"session_id"`
+	UserID    int64     `db:"user_id"`
+	RotatedAt time.Time `db:"rotated_at"`
+}
+
+// RevokedSession is a session whose access tokens are rejected until ExpiresAt
+type RevokedSession struct {
+	SessionID int64     `db:"session_id"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+// SessionMeta describes the client opening or refreshing a session
+type SessionMeta struct {
+	UserAgent string
+	IPAddress string
+}
+
+type UserAdminActionType string
+
+const (
+	UserAdminActionDeactivate    UserAdminActionType = "deactivate"
+	UserAdminActionActivate      UserAdminActionType = "activate"
+	UserAdminActionChangeRole    UserAdminActionType = "change_role"
+	UserAdminActionUpdateProfile UserAdminActionType = "update_profile"
+	UserAdminActionMarkVerified  UserAdminActionType = "mark_verified"
+	UserAdminActionForceLogout   UserAdminActionType = "force_logout"
+	UserAdminActionUnlock        UserAdminActionType = "unlock"
+	UserAdminActionSetFeeTier    UserAdminActionType = "set_fee_tier"
+)
+
+// UserAdminAction records a change staff made to a user account and why
+type UserAdminAction struct {
+	ID        int64               `db:"id" json:"id"`
+	UserID    int64               `db:"user_id" json:"user_id"`
+	AdminID   *int64              `db:"admin_id" json:"admin_id,omitempty"`
+	Action    UserAdminActionType `db:"action" json:"action"`
+	Reason    string              `db:"reason" json:"reason"`
+	Details   string              `db:"details" json:"details"`
+	CreatedAt time.Time           `db:"created_at" json:"created_at"`
 }
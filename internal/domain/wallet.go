@@ -2,8 +2,13 @@ package domain
 
 import (
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
+// AmountScale is the number of decimal places the DECIMAL(20,8) amount columns store
+const AmountScale = 8
+
 type Currency struct {
 	ID        int32     `db:"id" json:"id"`
 	Code      string    `db:"code" json:"code"`
@@ -11,18 +16,35 @@ type Currency struct {
 	Symbol    string    `db:"symbol" json:"symbol"`
 	IsActive  bool      `db:"is_active" json:"is_active"`
 	IsCrypto  bool      `db:"is_crypto" json:"is_crypto"`
+	Decimals  int32     `db:"decimals" json:"decimals"`
+	Network   string    `db:"network" json:"network,omitempty"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
 
+// Round truncates an amount to the currency's precision. Truncation never credits more than was computed.
+func (c *Currency) Round(amount decimal.Decimal) decimal.Decimal {
+	return amount.RoundDown(c.Decimals)
+}
+
+// RoundUp rounds an amount up to the currency's precision, used for fees charged to the user
+func (c *Currency) RoundUp(amount decimal.Decimal) decimal.Decimal {
+	return amount.RoundUp(c.Decimals)
+}
+
+// IsValidAmount reports whether the amount fits the currency's precision without rounding
+func (c *Currency) IsValidAmount(amount decimal.Decimal) bool {
+	return amount.Equal(c.Round(amount))
+}
+
 type Wallet struct {
-	ID         int64     `db:"id" json:"id"`
-	UserID     int64     `db:"user_id" json:"user_id"`
-	CurrencyID int32     `db:"currency_id" json:"currency_id"`
-	Balance    float64   `db:"balance" json:"balance"`
-	Locked     float64   `db:"locked" json:"locked"`
-	CreatedAt  time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+	ID         int64           `db:"id" json:"id"`
+	UserID     int64           `db:"user_id" json:"user_id"`
+	CurrencyID int32           `db:"currency_id" json:"currency_id"`
+	Balance    decimal.Decimal `db:"balance" json:"balance"`
+	Locked     decimal.Decimal `db:"locked" json:"locked"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time       `db:"updated_at" json:"updated_at"`
 }
 
 type WalletWithCurrency struct {
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/caspianex/exchange-backend/internal/domain"
+
+	"github.com/shopspring/decimal"
 )
 
 // ExchangeDTO represents a currency exchange with all details for admin view
@@ -16,11 +18,15 @@ type ExchangeDTO struct {
 	ToCurrencyID    int32                         `json:"to_currency_id"`
 	FromCurrency    CurrencyDTO                   `json:"from_currency"`
 	ToCurrency      CurrencyDTO                   `json:"to_currency"`
-	FromAmount      float64                       `json:"from_amount"`
-	ToAmount        float64                       `json:"to_amount"`
-	ToAmountWithFee float64                       `json:"to_amount_with_fee"`
-	ExchangeRate    float64                       `json:"exchange_rate"`
-	Fee             float64                       `json:"fee"`
+	FromAmount      decimal.Decimal               `json:"from_amount"`
+	ToAmount        decimal.Decimal               `json:"to_amount"`
+	ToAmountWithFee decimal.Decimal               `json:"to_amount_with_fee"`
+	ExchangeRate    decimal.Decimal               `json:"exchange_rate"`
+	Fee             decimal.Decimal               `json:"fee"`
+	FeeAmount       decimal.Decimal               `json:"fee_amount"`
+	TargetRate      *decimal.Decimal              `json:"target_rate,omitempty"`
+	ExpiresAt       *time.Time                    `json:"expires_at,omitempty"`
+	FeeRuleID       *int64                        `json:"fee_rule_id,omitempty"`
 	Status          domain.CurrencyExchangeStatus `json:"status"`
 	CreatedAt       time.Time                     `json:"created_at"`
 	UpdatedAt       time.Time                     `json:"updated_at"`
@@ -34,6 +40,7 @@ type CurrencyDTO struct {
 	Symbol   string `json:"symbol"`
 	IsActive bool   `json:"is_active"`
 	IsCrypto bool   `json:"is_crypto"`
+	Decimals int32  `json:"decimals"`
 }
 
 // ListExchangesResponse represents the response for listing exchanges
@@ -4,24 +4,29 @@ import (
 	"time"
 
 	"github.com/caspianex/exchange-backend/internal/domain"
+
+	"github.com/shopspring/decimal"
 )
 
 // ExchangeDTO represents a currency exchange with all details for client view
 type ExchangeDTO struct {
-	ID              int64                          `json:"id"`
-	UID             string                         `json:"uid"`
-	FromCurrencyID  int32                          `json:"from_currency_id"`
-	ToCurrencyID    int32                          `json:"to_currency_id"`
-	FromCurrency    CurrencyDTO                    `json:"from_currency"`
-	ToCurrency      CurrencyDTO                    `json:"to_currency"`
-	FromAmount      float64                        `json:"from_amount"`
-	ToAmount        float64                        `json:"to_amount"`
-	ToAmountWithFee float64                        `json:"to_amount_with_fee"`
-	ExchangeRate    float64                        `json:"exchange_rate"`
-	Fee             float64                        `json:"fee"`
-	Status          domain.CurrencyExchangeStatus  `json:"status"`
-	CreatedAt       time.Time                      `json:"created_at"`
-	UpdatedAt       time.Time                      `json:"updated_at"`
+	ID              int64                         `json:"id"`
+	UID             string                        `json:"uid"`
+	FromCurrencyID  int32                         `json:"from_currency_id"`
+	ToCurrencyID    int32                         `json:"to_currency_id"`
+	FromCurrency    CurrencyDTO                   `json:"from_currency"`
+	ToCurrency      CurrencyDTO                   `json:"to_currency"`
+	FromAmount      decimal.Decimal               `json:"from_amount"`
+	ToAmount        decimal.Decimal               `json:"to_amount"`
+	ToAmountWithFee decimal.Decimal               `json:"to_amount_with_fee"`
+	ExchangeRate    decimal.Decimal               `json:"exchange_rate"`
+	Fee             decimal.Decimal               `json:"fee"`
+	FeeAmount       decimal.Decimal               `json:"fee_amount"`
+	TargetRate      *decimal.Decimal              `json:"target_rate,omitempty"`
+	ExpiresAt       *time.Time                    `json:"expires_at,omitempty"`
+	Status          domain.CurrencyExchangeStatus `json:"status"`
+	CreatedAt       time.Time                     `json:"created_at"`
+	UpdatedAt       time.Time                     `json:"updated_at"`
 }
 
 // CurrencyDTO represents currency information
@@ -32,6 +37,7 @@ type CurrencyDTO struct {
 	Symbol   string `json:"symbol"`
 	IsActive bool   `json:"is_active"`
 	IsCrypto bool   `json:"is_crypto"`
+	Decimals int32  `json:"decimals"`
 }
 
 // ListExchangesResponse represents the response for listing exchanges
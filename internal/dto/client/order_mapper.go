@@ -16,6 +16,9 @@ func ToExchangeDTO(exchange domain.CurrencyExchangeWithCurrencies) ExchangeDTO {
 		ToAmountWithFee: exchange.ToAmountWithFee,
 		ExchangeRate:    exchange.ExchangeRate,
 		Fee:             exchange.Fee,
+		FeeAmount:       exchange.FeeAmount,
+		TargetRate:      exchange.TargetRate,
+		ExpiresAt:       exchange.ExpiresAt,
 		Status:          exchange.Status,
 		CreatedAt:       exchange.CreatedAt,
 		UpdatedAt:       exchange.UpdatedAt,
@@ -40,5 +43,6 @@ func ToCurrencyDTO(currency domain.Currency) CurrencyDTO {
 		Symbol:   currency.Symbol,
 		IsActive: currency.IsActive,
 		IsCrypto: currency.IsCrypto,
+		Decimals: currency.Decimals,
 	}
 }
@@ -0,0 +1,18 @@
+package models
+
+import "github.com/caspianex/exchange-backend/internal/domain"
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=read trade withdraw"`
+	// AllowedIPs are IP addresses or CIDR ranges. Required for keys with the withdraw scope.
+	AllowedIPs []string `json:"allowed_ips" validate:"max=20"`
+	// TOTPCode is required for keys with the withdraw scope
+	TOTPCode string `json:"totp_code"`
+}
+
+// APIKeyCreatedResponse is the only time the secret is shown
+type APIKeyCreatedResponse struct {
+	*domain.APIKey
+	Secret string `json:"secret"`
+}
@@ -1,14 +1,26 @@
 package models
 
+import "github.com/shopspring/decimal"
+
 type CreateExchangeRatesRequest struct {
-	FromCurrencyID int32   `json:"from_currency_id" validate:"required,gt=0"`
-	ToCurrencyID   int32   `json:"to_currency_id" validate:"required,gt=0"`
-	Fee            float64 `json:"fee" validate:"gte=0"`
-	Rate           float64 `json:"rate" validate:"gte=0"`
-	IsActive       bool    `json:"is_active"`
+	FromCurrencyID int32           `json:"from_currency_id" validate:"required,gt=0"`
+	ToCurrencyID   int32           `json:"to_currency_id" validate:"required,gt=0"`
+	Fee            decimal.Decimal `json:"fee" validate:"gte=0"`
+	Rate           decimal.Decimal `json:"rate" validate:"gte=0"`
+	IsActive       bool            `json:"is_active"`
+	PairLimits
 }
 
 type UpdateExchangeRatesRequest struct {
-	Fee      float64 `json:"fee" validate:"gte=0"`
-	IsActive bool    `json:"is_active"`
+	Fee      decimal.Decimal `json:"fee" validate:"gte=0"`
+	IsActive bool            `json:"is_active"`
+	PairLimits
+}
+
+// PairLimits are the trading limits of an exchange pair. Omitted fields are unlimited.
+type PairLimits struct {
+	MinAmount       *decimal.Decimal `json:"min_amount"`
+	MaxAmount       *decimal.Decimal `json:"max_amount"`
+	DailyLimit      *decimal.Decimal `json:"daily_limit"`
+	AmountPrecision *int32           `json:"amount_precision"`
 }
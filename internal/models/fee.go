@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// FeeRuleRequest creates a fee rule or replaces an existing one. Empty match fields match anything.
+type FeeRuleRequest struct {
+	Name             string           `json:"name" validate:"required,max=100"`
+	Operation        string           `json:"operation" validate:"required,oneof=withdrawal exchange"`
+	CurrencyCode     string           `json:"currency_code"`
+	Network          string           `json:"network" validate:"omitempty,max=30"`
+	FromCurrencyCode string           `json:"from_currency_code"`
+	ToCurrencyCode   string           `json:"to_currency_code"`
+	UserTier         string           `json:"user_tier" validate:"omitempty,max=20"`
+	MinAmount        *decimal.Decimal `json:"min_amount"`
+	MaxAmount        *decimal.Decimal `json:"max_amount"`
+	Type             string           `json:"type" validate:"required,oneof=percent flat tiered"`
+	Percent          decimal.Decimal  `json:"percent"`
+	FlatAmount       decimal.Decimal  `json:"flat_amount"`
+	MinFee           *decimal.Decimal `json:"min_fee"`
+	MaxFee           *decimal.Decimal `json:"max_fee"`
+	Tiers            []domain.FeeTier `json:"tiers"`
+	Priority         int              `json:"priority"`
+}
+
+type SetFeeTierRequest struct {
+	FeeTier string `json:"fee_tier" validate:"required,max=20"`
+	Reason  string `json:"reason" validate:"required,max=500"`
+}
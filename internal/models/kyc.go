@@ -0,0 +1,23 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+type SubmitKYCApplicationRequest struct {
+	Level int16 `json:"level" validate:"required,min=1,max=2"`
+}
+
+type ApproveKYCApplicationRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+type RejectKYCApplicationRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// SetKYCLimitRequest replaces the daily limits of a level in one currency. Omitted limits are unlimited.
+type SetKYCLimitRequest struct {
+	Level                int16            `json:"level" validate:"min=0,max=2"`
+	CurrencyCode         string           `json:"currency_code" validate:"required"`
+	DailyWithdrawalLimit *decimal.Decimal `json:"daily_withdrawal_limit"`
+	DailyExchangeLimit   *decimal.Decimal `json:"daily_exchange_limit"`
+}
@@ -1,11 +1,28 @@
 package models
 
-import "github.com/caspianex/exchange-backend/internal/domain"
+import (
+	"time"
 
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// CreateExchangeRequest executes at the current rate, or executes quote QuoteID when set.
+// With a quote the pair and amount may be omitted. With TargetRate the exchange stays pending
+// until the rate reaches it or ExpiresAt passes.
 type CreateExchangeRequest struct {
-	FromCurrencyCode string  `json:"from_currency_code" validate:"required"`
-	ToCurrencyCode   string  `json:"to_currency_code" validate:"required"`
-	FromAmount       float64 `json:"from_amount" validate:"required,gt=0"`
+	QuoteID          string           `json:"quote_id" validate:"omitempty,uuid"`
+	FromCurrencyCode string           `json:"from_currency_code" validate:"required_without=QuoteID"`
+	ToCurrencyCode   string           `json:"to_currency_code" validate:"required_without=QuoteID"`
+	FromAmount       decimal.Decimal  `json:"from_amount" validate:"required_without=QuoteID,omitempty,gt=0"`
+	TargetRate       *decimal.Decimal `json:"target_rate"`
+	ExpiresAt        *time.Time       `json:"expires_at"`
+}
+
+type CreateQuoteRequest struct {
+	FromCurrencyCode string          `json:"from_currency_code" validate:"required"`
+	ToCurrencyCode   string          `json:"to_currency_code" validate:"required"`
+	FromAmount       decimal.Decimal `json:"from_amount" validate:"required,gt=0"`
 }
 
 type GetExchangeResponse struct {
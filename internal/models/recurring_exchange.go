@@ -0,0 +1,20 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// CreateRecurringExchangeRequest schedules an exchange by a standard 5-field cron expression
+// (UTC unless prefixed with CRON_TZ=) or by a fixed interval such as "168h". Exactly one is required.
+type CreateRecurringExchangeRequest struct {
+	FromCurrencyCode string          `json:"from_currency_code" validate:"required"`
+	ToCurrencyCode   string          `json:"to_currency_code" validate:"required"`
+	FromAmount       decimal.Decimal `json:"from_amount" validate:"required,gt=0"`
+	Cron             string          `json:"cron" validate:"required_without=Interval,excluded_with=Interval,max=100"`
+	Interval         string          `json:"interval" validate:"required_without=Cron,max=20"`
+}
+
+// UpdateRecurringExchangeRequest replaces the amount and schedule. The pair cannot be changed.
+type UpdateRecurringExchangeRequest struct {
+	FromAmount decimal.Decimal `json:"from_amount" validate:"required,gt=0"`
+	Cron       string          `json:"cron" validate:"required_without=Interval,excluded_with=Interval,max=100"`
+	Interval   string          `json:"interval" validate:"required_without=Cron,max=20"`
+}
@@ -0,0 +1,17 @@
+package models
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=20"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"required,min=1"`
+}
+
+type UpdateRoleRequest struct {
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"required,min=1"`
+}
+
+type AssignRoleRequest struct {
+	Role   string `json:"role" validate:"required"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
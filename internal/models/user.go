@@ -0,0 +1,12 @@
+package models
+
+// AdminActionRequest carries the reason staff give for changing a user account
+type AdminActionRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type AdminUpdateUserRequest struct {
+	FirstName string `json:"first_name" validate:"required,max=100"`
+	LastName  string `json:"last_name" validate:"required,max=100"`
+	Reason    string `json:"reason" validate:"required,max=500"`
+}
@@ -1,21 +1,41 @@
 package models
 
-type DepositRequest struct {
-	CurrencyCode string  `json:"currency_code" validate:"required"`
-	Amount       float64 `json:"amount" validate:"required,gt=0"`
-	TxHash       string  `json:"tx_hash"`
-}
+import "github.com/shopspring/decimal"
 
 type WithdrawRequest struct {
-	CurrencyCode string  `json:"currency_code" validate:"required"`
-	Amount       float64 `json:"amount" validate:"required,gt=0"`
+	CurrencyCode string          `json:"currency_code" validate:"required"`
+	Amount       decimal.Decimal `json:"amount" validate:"required,gt=0"`
+	Address      string          `json:"address" validate:"required,max=255"`
+	Network      string          `json:"network" validate:"omitempty,max=30"`
+	TOTPCode     string          `json:"totp_code" validate:"omitempty,max=10"`
 }
 
 type AdminDepositRequest struct {
-	UserID       int64   `json:"user_id" validate:"required,gt=0"`
-	CurrencyCode string  `json:"currency_code" validate:"required"`
-	Amount       float64 `json:"amount" validate:"required,gt=0"`
-	TxHash       string  `json:"tx_hash"`
-	Description  string  `json:"description"`
+	UserID       int64           `json:"user_id" validate:"required,gt=0"`
+	CurrencyCode string          `json:"currency_code" validate:"required"`
+	Amount       decimal.Decimal `json:"amount" validate:"required,gt=0"`
+	TxHash       string          `json:"tx_hash"`
+	Description  string          `json:"description" validate:"max=500"`
+}
+
+type RejectWithdrawalRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type MarkWithdrawalSentRequest struct {
+	TxHash string `json:"tx_hash" validate:"required,max=255"`
 }
 
+type ImportDepositAddressesRequest struct {
+	CurrencyCode string   `json:"currency_code" validate:"required"`
+	Addresses    []string `json:"addresses" validate:"required,min=1,max=1000,dive,required,max=255"`
+}
+
+type MockChainTransferRequest struct {
+	Address string          `json:"address" validate:"required"`
+	Amount  decimal.Decimal `json:"amount" validate:"required,gt=0"`
+}
+
+type MockChainMineRequest struct {
+	Blocks int `json:"blocks" validate:"required,min=1,max=1000"`
+}
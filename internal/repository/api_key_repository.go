@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+)
+
+type APIKeyRepository struct {
+	db *database.Postgres
+}
+
+func NewAPIKeyRepository(db *database.Postgres) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	return r.db.QueryRowContext(
+		ctx, queries.APIKeyCreateQuery,
+		key.UserID, key.Name, key.KeyID, key.SecretEncrypted, key.Scopes, key.AllowedIPs,
+	).Scan(&key.ID, &key.CreatedAt)
+}
+
+// GetByKeyID returns nil without error when no key has keyID
+func (r *APIKeyRepository) GetByKeyID(ctx context.Context, keyID string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.db.GetContext(ctx, &key, queries.APIKeyGetByKeyIDQuery, keyID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListByUser returns the user's keys that are not revoked, newest first
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userID int64) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	err := r.db.SelectContext(ctx, &keys, queries.APIKeyListByUserQuery, userID)
+	return keys, err
+}
+
+func (r *APIKeyRepository) CountActiveByUser(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, queries.APIKeyCountActiveByUserQuery, userID)
+	return count, err
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, userID, id int64) error {
+	result, err := r.db.ExecContext(ctx, queries.APIKeyRevokeQuery, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("api key not found")
+	}
+
+	return nil
+}
+
+// Touch records when and from where the key was last used
+func (r *APIKeyRepository) Touch(ctx context.Context, id int64, ip string) error {
+	_, err := r.db.ExecContext(ctx, queries.APIKeyTouchQuery, id, ip)
+	return err
+}
+
+// UseNonce records nonce for the key until expiresAt. It returns false when the nonce
+// was already used and has not expired.
+func (r *APIKeyRepository) UseNonce(ctx context.Context, apiKeyID int64, nonce string, expiresAt time.Time) (bool, error) {
+	if _, err := r.db.ExecContext(ctx, queries.APIKeyNonceDeleteExpiredQuery, apiKeyID, nonce); err != nil {
+		return false, err
+	}
+
+	result, err := r.db.ExecContext(ctx, queries.APIKeyNonceUseQuery, apiKeyID, nonce, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+)
+
+type AuditLogRepository struct {
+	db *database.Postgres
+}
+
+func NewAuditLogRepository(db *database.Postgres) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// LastHashTx takes the audit log append lock for the rest of tx and returns the hash of the
+// newest entry, or the genesis hash when the log is empty
+func (r *AuditLogRepository) LastHashTx(ctx context.Context, tx *sqlx.Tx) (string, error) {
+	if _, err := tx.ExecContext(ctx, queries.AuditLogLockQuery); err != nil {
+		return "", err
+	}
+
+	var hash string
+	err := tx.GetContext(ctx, &hash, queries.AuditLogLastHashQuery)
+	if err == sql.ErrNoRows {
+		return domain.AuditGenesisHash, nil
+	}
+	return hash, err
+}
+
+func (r *AuditLogRepository) CreateTx(ctx context.Context, tx *sqlx.Tx, entry *domain.AuditEntry) error {
+	return tx.QueryRowContext(
+		ctx, queries.AuditLogCreateQuery,
+		entry.ActorID, entry.Action, entry.TargetType, entry.TargetID, nullJSON(entry.BeforeState),
+		nullJSON(entry.AfterState), entry.IPAddress, entry.RequestID, entry.PrevHash, entry.Hash, entry.CreatedAt,
+	).Scan(&entry.ID)
+}
+
+// List returns entries matching filter, newest first
+func (r *AuditLogRepository) List(ctx context.Context, filter *domain.AuditLogFilter, limit, offset int) ([]domain.AuditEntry, error) {
+	var entries []domain.AuditEntry
+
+	qb := auditLogFilter(newQueryBuilder(queries.AuditLogListBaseQuery), filter)
+	query, args := qb.Build("ORDER BY id DESC", fmt.Sprintf("LIMIT $%d OFFSET $%d", qb.paramCounter, qb.paramCounter+1))
+	args = append(args, limit, offset)
+
+	err := r.db.SelectContext(ctx, &entries, query, args...)
+	return entries, err
+}
+
+func (r *AuditLogRepository) Count(ctx context.Context, filter *domain.AuditLogFilter) (int64, error) {
+	var count int64
+
+	query, args := auditLogFilter(newQueryBuilder(queries.AuditLogCountBaseQuery), filter).Build("", "")
+
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+// ListAfter returns up to limit entries with an ID above afterID, oldest first
+func (r *AuditLogRepository) ListAfter(ctx context.Context, afterID int64, limit int) ([]domain.AuditEntry, error) {
+	var entries []domain.AuditEntry
+	err := r.db.SelectContext(ctx, &entries, queries.AuditLogListAfterQuery, afterID, limit)
+	return entries, err
+}
+
+func auditLogFilter(qb *QueryBuilder, filter *domain.AuditLogFilter) *QueryBuilder {
+	if filter.ActorID != 0 {
+		qb.AddWhere(fmt.Sprintf("actor_id = $%d", qb.paramCounter), filter.ActorID)
+	}
+	if filter.Action != "" {
+		qb.AddWhere(fmt.Sprintf("action = $%d", qb.paramCounter), filter.Action)
+	}
+	if filter.TargetType != "" {
+		qb.AddWhere(fmt.Sprintf("target_type = $%d", qb.paramCounter), filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		qb.AddWhere(fmt.Sprintf("target_id = $%d", qb.paramCounter), filter.TargetID)
+	}
+	if filter.RequestID != "" {
+		qb.AddWhere(fmt.Sprintf("request_id = $%d", qb.paramCounter), filter.RequestID)
+	}
+	if filter.From != nil {
+		qb.AddWhere(fmt.Sprintf("created_at >= $%d", qb.paramCounter), *filter.From)
+	}
+	if filter.To != nil {
+		qb.AddWhere(fmt.Sprintf("created_at < $%d", qb.paramCounter), *filter.To)
+	}
+	return qb
+}
+
+// nullJSON stores an absent state as NULL rather than an empty string, which is not valid JSON
+func nullJSON(data []byte) interface{} {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/pkg/database"
+)
+
+type ChainCursorRepository struct {
+	db *database.Postgres
+}
+
+func NewChainCursorRepository(db *database.Postgres) *ChainCursorRepository {
+	return &ChainCursorRepository{db: db}
+}
+
+// Get returns the last scanned block of the network. found is false before the first scan.
+func (r *ChainCursorRepository) Get(ctx context.Context, network string) (block int64, found bool, err error) {
+	err = r.db.QueryRowContext(ctx, queries.ChainCursorGetQuery, network).Scan(&block)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return block, true, nil
+}
+
+func (r *ChainCursorRepository) Set(ctx context.Context, network string, block int64) error {
+	_, err := r.db.ExecContext(ctx, queries.ChainCursorSetQuery, network, block)
+	return err
+}
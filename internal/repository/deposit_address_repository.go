@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+)
+
+type DepositAddressRepository struct {
+	db *database.Postgres
+}
+
+func NewDepositAddressRepository(db *database.Postgres) *DepositAddressRepository {
+	return &DepositAddressRepository{db: db}
+}
+
+func (r *DepositAddressRepository) GetByUserAndCurrencyTx(ctx context.Context, tx *sqlx.Tx, userID int64, currencyID int32) (*domain.DepositAddress, error) {
+	var address domain.DepositAddress
+	err := tx.GetContext(ctx, &address, queries.DepositAddressGetByUserAndCurrencyQuery, userID, currencyID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// GetByAddress returns nil when the address is not in the pool
+func (r *DepositAddressRepository) GetByAddress(ctx context.Context, network, address string) (*domain.DepositAddress, error) {
+	var depositAddress domain.DepositAddress
+	err := r.db.GetContext(ctx, &depositAddress, queries.DepositAddressGetByAddressQuery, network, address)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &depositAddress, nil
+}
+
+// GetAllocatedAddresses returns every address on the network that has been handed to a user
+func (r *DepositAddressRepository) GetAllocatedAddresses(ctx context.Context, network string) ([]string, error) {
+	var addresses []string
+	err := r.db.SelectContext(ctx, &addresses, queries.DepositAddressGetAllocatedByNetworkQuery, network)
+	return addresses, err
+}
+
+// AllocateFromPoolTx assigns the oldest free pool address of the currency to the user
+func (r *DepositAddressRepository) AllocateFromPoolTx(ctx context.Context, tx *sqlx.Tx, userID int64, currencyID int32) (*domain.DepositAddress, error) {
+	var address domain.DepositAddress
+	err := tx.GetContext(ctx, &address, queries.DepositAddressAllocateFromPoolQuery, userID, currencyID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("no deposit addresses available")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// AddToPool imports pre-generated addresses, skipping ones already known. Returns how many were added.
+func (r *DepositAddressRepository) AddToPool(ctx context.Context, currency *domain.Currency, addresses []string) (int64, error) {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var added int64
+	for _, address := range addresses {
+		res, err := tx.ExecContext(ctx, queries.DepositAddressAddToPoolQuery, currency.ID, currency.Network, address)
+		if err != nil {
+			return 0, fmt.Errorf("failed to add deposit address: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		added += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit deposit addresses: %w", err)
+	}
+	database.MarkCommitted(ctx)
+
+	return added, nil
+}
+
+func (r *DepositAddressRepository) CountFree(ctx context.Context, currencyID int32) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, queries.DepositAddressCountFreeQuery, currencyID).Scan(&count)
+	return count, err
+}
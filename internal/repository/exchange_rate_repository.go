@@ -10,6 +10,8 @@ import (
 	"github.com/caspianex/exchange-backend/internal/domain"
 	"github.com/caspianex/exchange-backend/pkg/cache"
 	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
 )
 
 type ExchangeRateRepository struct {
@@ -24,18 +26,13 @@ func NewExchangeRateRepository(db *database.Postgres, cacheService *cache.CacheS
 	}
 }
 
-func (r *ExchangeRateRepository) Create(ctx context.Context, rate *domain.ExchangeRate) error {
-	if err := r.db.QueryRowContext(
+// CreateTx inserts the rate. Call RefreshCache after commit.
+func (r *ExchangeRateRepository) CreateTx(ctx context.Context, tx *sqlx.Tx, rate *domain.ExchangeRate) error {
+	return tx.QueryRowContext(
 		ctx, queries.ExchangeRateCreateQuery,
 		rate.FromCurrencyID, rate.ToCurrencyID, rate.Rate, rate.Fee, rate.IsActive,
-	).Scan(&rate.ID, &rate.CreatedAt, &rate.UpdatedAt); err != nil {
-		return err
-	}
-
-	// Update all cache keys for this exchange rate
-	r.cacheService.UpdateExchangeRateCache(rate)
-
-	return nil
+		rate.MinAmount, rate.MaxAmount, rate.DailyLimit, rate.AmountPrecision,
+	).Scan(&rate.ID, &rate.CreatedAt, &rate.UpdatedAt)
 }
 
 func (r *ExchangeRateRepository) GetByPair(ctx context.Context, fromId, toId int32) (*domain.ExchangeRate, error) {
@@ -120,39 +117,40 @@ func (r *ExchangeRateRepository) GetActive(ctx context.Context) ([]domain.Exchan
 	return rates, nil
 }
 
-func (r *ExchangeRateRepository) Update(ctx context.Context, rate *domain.ExchangeRate) error {
-	if err := r.db.QueryRowContext(
+// UpdateTx stores the admin-managed fields of the rate. Call RefreshCache after commit.
+func (r *ExchangeRateRepository) UpdateTx(ctx context.Context, tx *sqlx.Tx, rate *domain.ExchangeRate) error {
+	return tx.QueryRowContext(
 		ctx, queries.ExchangeRateUpdateQuery,
-		rate.Fee, rate.IsActive, rate.ID,
-	).Scan(&rate.UpdatedAt); err != nil {
-		return err
+		rate.Fee, rate.IsActive, rate.MinAmount, rate.MaxAmount, rate.DailyLimit,
+		rate.AmountPrecision, rate.ID,
+	).Scan(&rate.UpdatedAt)
+}
+
+// DeleteTx removes the rate. Call InvalidateCache after commit.
+func (r *ExchangeRateRepository) DeleteTx(ctx context.Context, tx *sqlx.Tx, id int64) error {
+	var from, to int32
+	err := tx.QueryRowContext(ctx, queries.ExchangeRateDeleteQuery, id).Scan(&from, &to)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("exchange rate not found")
 	}
+	return err
+}
 
+// RefreshCache replaces the cached rate with committed state
+func (r *ExchangeRateRepository) RefreshCache(rate *domain.ExchangeRate) {
 	// Update all cache keys for this exchange rate
 	r.cacheService.UpdateExchangeRateCache(rate)
-
-	return nil
 }
 
-func (r *ExchangeRateRepository) Delete(ctx context.Context, id int64) error {
-	row := r.db.QueryRowContext(ctx, queries.ExchangeRateDeleteQuery, id)
-	if row != nil {
-		var from, to int32
-		err := row.Scan(&from, &to)
-		if err != nil {
-			return err
-		}
-		// Invalidate all cache keys for this exchange rate
-		r.cacheService.InvalidateExchangeRateCache(from, to, id)
-	}
-
-	return nil
+// InvalidateCache drops a deleted rate from the cache
+func (r *ExchangeRateRepository) InvalidateCache(rate *domain.ExchangeRate) {
+	r.cacheService.InvalidateExchangeRateCache(rate.FromCurrencyID, rate.ToCurrencyID, rate.ID)
 }
 
 // RateUpdateData holds data for batch updating rates (worker updates only rate, not fee)
 type RateUpdateData struct {
 	ID   int64
-	Rate float64
+	Rate decimal.Decimal
 }
 
 // BatchUpdate updates multiple exchange rates in a single transaction
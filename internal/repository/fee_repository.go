@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+)
+
+type FeeRuleRepository struct {
+	db *database.Postgres
+}
+
+func NewFeeRuleRepository(db *database.Postgres) *FeeRuleRepository {
+	return &FeeRuleRepository{db: db}
+}
+
+func (r *FeeRuleRepository) CreateTx(ctx context.Context, tx *sqlx.Tx, rule *domain.FeeRule) error {
+	return tx.QueryRowContext(
+		ctx, queries.FeeRuleCreateQuery,
+		rule.Name, rule.Operation, rule.CurrencyID, rule.Network, rule.FromCurrencyID, rule.ToCurrencyID, rule.UserTier,
+		rule.MinAmount, rule.MaxAmount, rule.Type, rule.Percent, rule.FlatAmount, rule.MinFee, rule.MaxFee, rule.Tiers,
+		rule.Priority, rule.CreatedBy,
+	).Scan(&rule.ID, &rule.IsActive, &rule.CreatedAt)
+}
+
+func (r *FeeRuleRepository) GetByID(ctx context.Context, id int64) (*domain.FeeRule, error) {
+	var rule domain.FeeRule
+	err := r.db.GetContext(ctx, &rule, queries.FeeRuleGetByIDQuery, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("fee rule not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *FeeRuleRepository) LockByIDTx(ctx context.Context, tx *sqlx.Tx, id int64) (*domain.FeeRule, error) {
+	var rule domain.FeeRule
+	err := tx.GetContext(ctx, &rule, queries.FeeRuleLockByIDQuery, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("fee rule not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *FeeRuleRepository) GetActiveByOperation(ctx context.Context, operation domain.FeeOperation) ([]domain.FeeRule, error) {
+	var rules []domain.FeeRule
+	err := r.db.SelectContext(ctx, &rules, queries.FeeRuleGetActiveByOperationQuery, operation)
+	return rules, err
+}
+
+// RetireTx deactivates a rule. replacedBy points at its successor when the rule was updated.
+func (r *FeeRuleRepository) RetireTx(ctx context.Context, tx *sqlx.Tx, rule *domain.FeeRule, replacedBy *int64) error {
+	if err := tx.QueryRowContext(ctx, queries.FeeRuleRetireQuery, replacedBy, rule.ID).Scan(&rule.RetiredAt); err != nil {
+		return err
+	}
+	rule.IsActive = false
+	rule.ReplacedBy = replacedBy
+	return nil
+}
+
+func (r *FeeRuleRepository) List(ctx context.Context, operation string, activeOnly bool, limit, offset int) ([]domain.FeeRule, error) {
+	var rules []domain.FeeRule
+
+	qb := newQueryBuilder(queries.FeeRuleListBaseQuery)
+
+	if operation != "" {
+		qb.AddWhere(fmt.Sprintf("operation = $%d", qb.paramCounter), operation)
+	}
+
+	if activeOnly {
+		qb.AddWhere(fmt.Sprintf("is_active = $%d", qb.paramCounter), true)
+	}
+
+	query, args := qb.Build("ORDER BY operation, priority DESC, id", fmt.Sprintf("LIMIT $%d OFFSET $%d", qb.paramCounter, qb.paramCounter+1))
+	args = append(args, limit, offset)
+
+	err := r.db.SelectContext(ctx, &rules, query, args...)
+	return rules, err
+}
+
+func (r *FeeRuleRepository) Count(ctx context.Context, operation string, activeOnly bool) (int64, error) {
+	var count int64
+
+	qb := newQueryBuilder(queries.FeeRuleCountBaseQuery)
+
+	if operation != "" {
+		qb.AddWhere(fmt.Sprintf("operation = $%d", qb.paramCounter), operation)
+	}
+
+	if activeOnly {
+		qb.AddWhere(fmt.Sprintf("is_active = $%d", qb.paramCounter), true)
+	}
+
+	query, args := qb.Build("", "")
+
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+)
+
+type IdempotencyRepository struct {
+	db *database.Postgres
+}
+
+func NewIdempotencyRepository(db *database.Postgres) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Acquire claims key for a new request. It returns nil once the claim succeeds,
+// or the stored record when another request already holds the key.
+func (r *IdempotencyRepository) Acquire(ctx context.Context, key *domain.IdempotencyKey) (*domain.IdempotencyKey, error) {
+	err := r.db.QueryRowContext(
+		ctx, queries.IdempotencyKeyAcquireQuery,
+		key.UserID, key.Key, key.RequestHash, key.ExpiresAt,
+	).Scan(&key.ID, &key.CreatedAt)
+	if err == nil {
+		return nil, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to acquire idempotency key: %w", err)
+	}
+
+	var existing domain.IdempotencyKey
+	if err := r.db.GetContext(ctx, &existing, queries.IdempotencyKeyGetByUserAndKeyQuery, key.UserID, key.Key); err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return &existing, nil
+}
+
+// Complete stores the response so later retries can replay it
+func (r *IdempotencyRepository) Complete(ctx context.Context, id int64, status int, body []byte) error {
+	_, err := r.db.ExecContext(ctx, queries.IdempotencyKeyCompleteQuery, status, body, id)
+	return err
+}
+
+// Release drops a claimed key so the request can be retried from scratch
+func (r *IdempotencyRepository) Release(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, queries.IdempotencyKeyDeleteQuery, id)
+	return err
+}
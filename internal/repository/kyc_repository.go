@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type KYCRepository struct {
+	db *database.Postgres
+}
+
+func NewKYCRepository(db *database.Postgres) *KYCRepository {
+	return &KYCRepository{db: db}
+}
+
+func (r *KYCRepository) CreateApplicationTx(ctx context.Context, tx *sqlx.Tx, app *domain.KYCApplication) error {
+	err := tx.QueryRowContext(
+		ctx, queries.KYCApplicationCreateQuery,
+		app.UserID, app.Level, app.Status,
+	).Scan(&app.ID, &app.CreatedAt, &app.UpdatedAt)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return fmt.Errorf("a kyc application is already pending")
+	}
+	return err
+}
+
+func (r *KYCRepository) GetApplicationByID(ctx context.Context, id int64) (*domain.KYCApplication, error) {
+	var app domain.KYCApplication
+	err := r.db.GetContext(ctx, &app, queries.KYCApplicationGetByIDQuery, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("kyc application not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+func (r *KYCRepository) LockApplicationByIDTx(ctx context.Context, tx *sqlx.Tx, id int64) (*domain.KYCApplication, error) {
+	var app domain.KYCApplication
+	err := tx.GetContext(ctx, &app, queries.KYCApplicationLockByIDQuery, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("kyc application not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+// GetLatestApplication returns the user's most recent application, or nil when there is none
+func (r *KYCRepository) GetLatestApplication(ctx context.Context, userID int64) (*domain.KYCApplication, error) {
+	var app domain.KYCApplication
+	err := r.db.GetContext(ctx, &app, queries.KYCApplicationGetLatestByUserQuery, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+func (r *KYCRepository) ListApplications(ctx context.Context, status string, limit, offset int) ([]domain.KYCApplication, error) {
+	var apps []domain.KYCApplication
+	err := r.db.SelectContext(ctx, &apps, queries.KYCApplicationListQuery, status, limit, offset)
+	return apps, err
+}
+
+func (r *KYCRepository) CountApplications(ctx context.Context, status string) (int64, error) {
+	var count int64
+	err := r.db.GetContext(ctx, &count, queries.KYCApplicationCountQuery, status)
+	return count, err
+}
+
+func (r *KYCRepository) ReviewApplicationTx(ctx context.Context, tx *sqlx.Tx, app *domain.KYCApplication) error {
+	return tx.QueryRowContext(
+		ctx, queries.KYCApplicationReviewQuery,
+		app.Status, app.Reason, app.ReviewedBy, app.ID,
+	).Scan(&app.ReviewedAt, &app.UpdatedAt)
+}
+
+func (r *KYCRepository) CreateDocument(ctx context.Context, doc *domain.KYCDocument) error {
+	return r.db.QueryRowContext(
+		ctx, queries.KYCDocumentCreateQuery,
+		doc.UserID, doc.Type, doc.StorageKey, doc.FileName, doc.ContentType, doc.SizeBytes,
+	).Scan(&doc.ID, &doc.CreatedAt)
+}
+
+func (r *KYCRepository) GetDocumentByID(ctx context.Context, id int64) (*domain.KYCDocument, error) {
+	var doc domain.KYCDocument
+	err := r.db.GetContext(ctx, &doc, queries.KYCDocumentGetByIDQuery, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("kyc document not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// GetUnsubmittedDocuments returns the user's uploads not yet attached to an application
+func (r *KYCRepository) GetUnsubmittedDocuments(ctx context.Context, userID int64) ([]domain.KYCDocument, error) {
+	var docs []domain.KYCDocument
+	err := r.db.SelectContext(ctx, &docs, queries.KYCDocumentGetUnsubmittedQuery, userID)
+	return docs, err
+}
+
+func (r *KYCRepository) GetUnsubmittedDocumentsTx(ctx context.Context, tx *sqlx.Tx, userID int64) ([]domain.KYCDocument, error) {
+	var docs []domain.KYCDocument
+	err := tx.SelectContext(ctx, &docs, queries.KYCDocumentGetUnsubmittedQuery, userID)
+	return docs, err
+}
+
+func (r *KYCRepository) GetApplicationDocuments(ctx context.Context, applicationID int64) ([]domain.KYCDocument, error) {
+	var docs []domain.KYCDocument
+	err := r.db.SelectContext(ctx, &docs, queries.KYCDocumentGetByApplicationQuery, applicationID)
+	return docs, err
+}
+
+// AttachDocumentsTx attaches all of the user's unsubmitted documents to the application
+func (r *KYCRepository) AttachDocumentsTx(ctx context.Context, tx *sqlx.Tx, userID, applicationID int64) error {
+	_, err := tx.ExecContext(ctx, queries.KYCDocumentAttachQuery, applicationID, userID)
+	return err
+}
+
+// DeleteUnsubmittedDocument removes the row and returns the storage key of the file to delete.
+// Submitted documents are kept as review evidence.
+func (r *KYCRepository) DeleteUnsubmittedDocument(ctx context.Context, userID, id int64) (string, error) {
+	var key string
+	err := r.db.QueryRowContext(ctx, queries.KYCDocumentDeleteUnsubmittedQuery, id, userID).Scan(&key)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("kyc document not found")
+	}
+	return key, err
+}
+
+func (r *KYCRepository) ListLimits(ctx context.Context) ([]domain.KYCLimit, error) {
+	var limits []domain.KYCLimit
+	err := r.db.SelectContext(ctx, &limits, queries.KYCLimitListQuery)
+	return limits, err
+}
+
+func (r *KYCRepository) GetLimitsByLevel(ctx context.Context, level int16) ([]domain.KYCLimit, error) {
+	var limits []domain.KYCLimit
+	err := r.db.SelectContext(ctx, &limits, queries.KYCLimitGetByLevelQuery, level)
+	return limits, err
+}
+
+// GetLimit returns the limit for the level and currency, or nil when none is configured
+func (r *KYCRepository) GetLimit(ctx context.Context, level int16, currencyID int32) (*domain.KYCLimit, error) {
+	var limit domain.KYCLimit
+	err := r.db.GetContext(ctx, &limit, queries.KYCLimitGetQuery, level, currencyID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &limit, nil
+}
+
+// LockLimitTx selects the limit with SELECT ... FOR UPDATE, or nil when none is configured
+func (r *KYCRepository) LockLimitTx(ctx context.Context, tx *sqlx.Tx, level int16, currencyID int32) (*domain.KYCLimit, error) {
+	var limit domain.KYCLimit
+	err := tx.GetContext(ctx, &limit, queries.KYCLimitLockQuery, level, currencyID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &limit, nil
+}
+
+func (r *KYCRepository) UpsertLimitTx(ctx context.Context, tx *sqlx.Tx, limit *domain.KYCLimit) error {
+	return tx.QueryRowContext(
+		ctx, queries.KYCLimitUpsertQuery,
+		limit.Level, limit.CurrencyID, limit.DailyWithdrawalLimit, limit.DailyExchangeLimit,
+	).Scan(&limit.UpdatedAt)
+}
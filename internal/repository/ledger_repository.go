@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+)
+
+type LedgerRepository struct {
+	db *database.Postgres
+}
+
+func NewLedgerRepository(db *database.Postgres) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// CreateJournal writes a journal and all of its postings in a single transaction
+func (r *LedgerRepository) CreateJournal(ctx context.Context, journal *domain.LedgerJournal) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.CreateJournalTx(ctx, tx, journal); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit ledger journal: %w", err)
+	}
+	database.MarkCommitted(ctx)
+
+	return nil
+}
+
+// CreateJournalTx writes a journal and its postings inside the caller's transaction.
+// The balance check runs as a deferred trigger when the caller commits.
+func (r *LedgerRepository) CreateJournalTx(ctx context.Context, tx *sqlx.Tx, journal *domain.LedgerJournal) error {
+	if err := tx.QueryRowContext(
+		ctx, queries.LedgerJournalCreateQuery,
+		journal.UID, journal.Type, journal.ReferenceType, journal.ReferenceID, journal.Description,
+	).Scan(&journal.ID, &journal.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create ledger journal: %w", err)
+	}
+
+	for i := range journal.Postings {
+		posting := &journal.Postings[i]
+		posting.JournalID = journal.ID
+		if err := tx.QueryRowContext(
+			ctx, queries.LedgerPostingCreateQuery,
+			posting.JournalID, posting.Account, posting.WalletID, posting.CurrencyID, posting.Amount,
+		).Scan(&posting.ID, &posting.CreatedAt); err != nil {
+			return fmt.Errorf("failed to create ledger posting: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *LedgerRepository) GetJournalByID(ctx context.Context, id int64) (*domain.LedgerJournal, error) {
+	var journal domain.LedgerJournal
+	err := r.db.GetContext(ctx, &journal, queries.LedgerJournalGetByIDQuery, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("ledger journal not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.db.SelectContext(ctx, &journal.Postings, queries.LedgerPostingsGetByJournalQuery, id); err != nil {
+		return nil, err
+	}
+
+	return &journal, nil
+}
+
+func (r *LedgerRepository) GetWalletPostings(ctx context.Context, walletID int64, limit, offset int) ([]domain.LedgerPosting, error) {
+	var postings []domain.LedgerPosting
+	err := r.db.SelectContext(ctx, &postings, queries.LedgerPostingsGetByWalletQuery, walletID, limit, offset)
+	return postings, err
+}
+
+func (r *LedgerRepository) GetAccountBalance(ctx context.Context, account string) (decimal.Decimal, error) {
+	var balance decimal.Decimal
+	err := r.db.GetContext(ctx, &balance, queries.LedgerAccountBalanceQuery, account)
+	return balance, err
+}
+
+// GetWalletMismatches returns wallets whose balance or locked amount differs from the ledger
+func (r *LedgerRepository) GetWalletMismatches(ctx context.Context) ([]domain.WalletLedgerMismatch, error) {
+	var mismatches []domain.WalletLedgerMismatch
+	err := r.db.SelectContext(ctx, &mismatches, queries.LedgerReconcileWalletsQuery)
+	return mismatches, err
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+)
+
+type LoginFailureRepository struct {
+	db *database.Postgres
+}
+
+func NewLoginFailureRepository(db *database.Postgres) *LoginFailureRepository {
+	return &LoginFailureRepository{db: db}
+}
+
+// GetForUpdateTx locks the subject's run inside the caller's transaction, creating an empty
+// one at now if there is none
+func (r *LoginFailureRepository) GetForUpdateTx(ctx context.Context, tx *sqlx.Tx, scope domain.LoginFailureScope, subject string, now time.Time) (*domain.LoginFailure, error) {
+	if _, err := tx.ExecContext(ctx, queries.LoginFailureEnsureQuery, scope, subject, now); err != nil {
+		return nil, err
+	}
+
+	var failure domain.LoginFailure
+	if err := tx.GetContext(ctx, &failure, queries.LoginFailureGetForUpdateQuery, scope, subject); err != nil {
+		return nil, err
+	}
+	return &failure, nil
+}
+
+func (r *LoginFailureRepository) UpdateTx(ctx context.Context, tx *sqlx.Tx, failure *domain.LoginFailure) error {
+	_, err := tx.ExecContext(ctx, queries.LoginFailureUpdateQuery,
+		failure.Scope, failure.Subject, failure.Failures, failure.LastFailureAt, failure.LockedUntil)
+	return err
+}
+
+// RecordFailure counts a failure at now. Failures before since no longer count.
+func (r *LoginFailureRepository) RecordFailure(ctx context.Context, scope domain.LoginFailureScope, subject string, now, since time.Time) (*domain.LoginFailure, error) {
+	var failure domain.LoginFailure
+	err := r.db.GetContext(ctx, &failure, queries.LoginFailureRecordQuery, scope, subject, now, since)
+	if err != nil {
+		return nil, err
+	}
+	return &failure, nil
+}
+
+func (r *LoginFailureRepository) Lock(ctx context.Context, scope domain.LoginFailureScope, subject string, until time.Time) error {
+	_, err := r.db.ExecContext(ctx, queries.LoginFailureLockQuery, scope, subject, until)
+	return err
+}
+
+// LockIfReached locks the subject until the given time when its run has at least maxFailures
+// failures. Returns whether it locked.
+func (r *LoginFailureRepository) LockIfReached(ctx context.Context, scope domain.LoginFailureScope, subject string, maxFailures int, until time.Time) (bool, error) {
+	var failures int
+	err := r.db.GetContext(ctx, &failures, queries.LoginFailureLockIfReachedQuery, scope, subject, maxFailures, until)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *LoginFailureRepository) Release(ctx context.Context, scope domain.LoginFailureScope, subject string) error {
+	_, err := r.db.ExecContext(ctx, queries.LoginFailureReleaseQuery, scope, subject)
+	return err
+}
+
+func (r *LoginFailureRepository) Delete(ctx context.Context, scope domain.LoginFailureScope, subject string) error {
+	_, err := r.db.ExecContext(ctx, queries.LoginFailureDeleteQuery, scope, subject)
+	return err
+}
+
+func (r *LoginFailureRepository) DeleteTx(ctx context.Context, tx *sqlx.Tx, scope domain.LoginFailureScope, subject string) (bool, error) {
+	result, err := tx.ExecContext(ctx, queries.LoginFailureDeleteQuery, scope, subject)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// DeleteStale removes runs that ended before since and are not locked
+func (r *LoginFailureRepository) DeleteStale(ctx context.Context, since time.Time) error {
+	_, err := r.db.ExecContext(ctx, queries.LoginFailureDeleteStaleQuery, since)
+	return err
+}
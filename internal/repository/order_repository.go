@@ -9,6 +9,8 @@ import (
 	"github.com/caspianex/exchange-backend/internal/domain"
 	"github.com/caspianex/exchange-backend/pkg/cache"
 	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
 )
 
 type CurrencyExchangeRepository struct {
@@ -28,7 +30,18 @@ func (r *CurrencyExchangeRepository) Create(ctx context.Context, exchange *domai
 		ctx, queries.CurrencyExchangeCreateQuery,
 		exchange.UID, exchange.UserID, exchange.FromCurrencyID, exchange.ToCurrencyID,
 		exchange.FromAmount, exchange.ToAmount, exchange.ToAmountWithFee,
-		exchange.ExchangeRate, exchange.Fee, exchange.Status,
+		exchange.ExchangeRate, exchange.Fee, exchange.FeeAmount, exchange.FeeRuleID,
+		exchange.TargetRate, exchange.ExpiresAt, exchange.Status,
+	).Scan(&exchange.ID, &exchange.CreatedAt, &exchange.UpdatedAt)
+}
+
+func (r *CurrencyExchangeRepository) CreateTx(ctx context.Context, tx *sqlx.Tx, exchange *domain.CurrencyExchange) error {
+	return tx.QueryRowContext(
+		ctx, queries.CurrencyExchangeCreateQuery,
+		exchange.UID, exchange.UserID, exchange.FromCurrencyID, exchange.ToCurrencyID,
+		exchange.FromAmount, exchange.ToAmount, exchange.ToAmountWithFee,
+		exchange.ExchangeRate, exchange.Fee, exchange.FeeAmount, exchange.FeeRuleID,
+		exchange.TargetRate, exchange.ExpiresAt, exchange.Status,
 	).Scan(&exchange.ID, &exchange.CreatedAt, &exchange.UpdatedAt)
 }
 
@@ -114,6 +127,64 @@ func (r *CurrencyExchangeRepository) Update(ctx context.Context, exchange *domai
 	).Scan(&exchange.UpdatedAt)
 }
 
+func (r *CurrencyExchangeRepository) LockByIDTx(ctx context.Context, tx *sqlx.Tx, id int64) (*domain.CurrencyExchange, error) {
+	var exchange domain.CurrencyExchange
+	err := tx.GetContext(ctx, &exchange, queries.CurrencyExchangeLockByIDQuery, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("exchange not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &exchange, nil
+}
+
+func (r *CurrencyExchangeRepository) UpdateTx(ctx context.Context, tx *sqlx.Tx, exchange *domain.CurrencyExchange) error {
+	return tx.QueryRowContext(
+		ctx, queries.CurrencyExchangeUpdateQuery,
+		exchange.Status, exchange.ID,
+	).Scan(&exchange.UpdatedAt)
+}
+
+// UpdateExecutionTx stores the price a pending exchange was executed at along with its new status
+func (r *CurrencyExchangeRepository) UpdateExecutionTx(ctx context.Context, tx *sqlx.Tx, exchange *domain.CurrencyExchange) error {
+	return tx.QueryRowContext(
+		ctx, queries.CurrencyExchangeUpdateExecutionQuery,
+		exchange.ToAmount, exchange.ToAmountWithFee, exchange.ExchangeRate, exchange.Fee,
+		exchange.FeeAmount, exchange.FeeRuleID, exchange.Status, exchange.ID,
+	).Scan(&exchange.UpdatedAt)
+}
+
+// GetMatchedLimitExchanges returns up to limit pending limit exchanges with an ID above afterID
+// whose pair rate has reached the target, in ID order
+func (r *CurrencyExchangeRepository) GetMatchedLimitExchanges(ctx context.Context, afterID int64, limit int) ([]domain.LimitMatch, error) {
+	var matches []domain.LimitMatch
+	err := r.db.SelectContext(ctx, &matches, queries.CurrencyExchangeGetMatchedLimitQuery, afterID, limit)
+	return matches, err
+}
+
+// ExpireLimitTx marks up to limit overdue limit exchanges expired and returns them. Their holds
+// must be released in the same transaction.
+func (r *CurrencyExchangeRepository) ExpireLimitTx(ctx context.Context, tx *sqlx.Tx, limit int) ([]domain.CurrencyExchange, error) {
+	var exchanges []domain.CurrencyExchange
+	err := tx.SelectContext(ctx, &exchanges, queries.CurrencyExchangeExpireLimitQuery, limit)
+	return exchanges, err
+}
+
+// GetDailyVolumeTx sums the user's from-amount on the pair since the start of the day
+func (r *CurrencyExchangeRepository) GetDailyVolumeTx(ctx context.Context, tx *sqlx.Tx, userID int64, fromCurrencyID, toCurrencyID int32) (decimal.Decimal, error) {
+	var volume decimal.Decimal
+	err := tx.GetContext(ctx, &volume, queries.CurrencyExchangeGetDailyVolumeQuery, userID, fromCurrencyID, toCurrencyID)
+	return volume, err
+}
+
+// GetDailyVolumeByCurrencyTx sums the user's from-amount out of the currency since the start of the day
+func (r *CurrencyExchangeRepository) GetDailyVolumeByCurrencyTx(ctx context.Context, tx *sqlx.Tx, userID int64, fromCurrencyID int32) (decimal.Decimal, error) {
+	var volume decimal.Decimal
+	err := tx.GetContext(ctx, &volume, queries.CurrencyExchangeGetDailyVolumeByCurrencyQuery, userID, fromCurrencyID)
+	return volume, err
+}
+
 func (r *CurrencyExchangeRepository) GetExchangeRate(ctx context.Context, fromCurrencyID, toCurrencyID int32) (*domain.ExchangeRate, error) {
 	if rate, found := r.cacheService.GetExchangeRate(fromCurrencyID, toCurrencyID); found {
 		return rate, nil
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+)
+
+type ExchangeQuoteRepository struct {
+	db *database.Postgres
+}
+
+func NewExchangeQuoteRepository(db *database.Postgres) *ExchangeQuoteRepository {
+	return &ExchangeQuoteRepository{db: db}
+}
+
+func (r *ExchangeQuoteRepository) Create(ctx context.Context, quote *domain.ExchangeQuote) error {
+	return r.db.QueryRowContext(
+		ctx, queries.ExchangeQuoteCreateQuery,
+		quote.UID, quote.UserID, quote.FromCurrencyID, quote.ToCurrencyID, quote.FromAmount, quote.ToAmount,
+		quote.ToAmountWithFee, quote.ExchangeRate, quote.Fee, quote.FeeAmount, quote.FeeRuleID, quote.ExpiresAt,
+	).Scan(&quote.ID, &quote.CreatedAt)
+}
+
+func (r *ExchangeQuoteRepository) LockByUIDTx(ctx context.Context, tx *sqlx.Tx, uid string) (*domain.ExchangeQuote, error) {
+	var quote domain.ExchangeQuote
+	err := tx.GetContext(ctx, &quote, queries.ExchangeQuoteLockByUIDQuery, uid)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("quote not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+func (r *ExchangeQuoteRepository) MarkExecutedTx(ctx context.Context, tx *sqlx.Tx, quote *domain.ExchangeQuote, exchangeID int64) error {
+	if err := tx.QueryRowContext(ctx, queries.ExchangeQuoteMarkExecutedQuery, exchangeID, quote.ID).Scan(&quote.ExecutedAt); err != nil {
+		return err
+	}
+	quote.ExchangeID = &exchangeID
+	return nil
+}
+
+// GetConversionStats counts quotes per pair created in [from, to)
+func (r *ExchangeQuoteRepository) GetConversionStats(ctx context.Context, from, to time.Time) ([]domain.QuoteConversionStats, error) {
+	var stats []domain.QuoteConversionStats
+	err := r.db.SelectContext(ctx, &stats, queries.ExchangeQuoteConversionStatsQuery, from, to)
+	return stats, err
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+)
+
+// RecoveryCodeRepository stores hashes of 2FA recovery codes
+type RecoveryCodeRepository struct {
+	db *database.Postgres
+}
+
+func NewRecoveryCodeRepository(db *database.Postgres) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{db: db}
+}
+
+// ReplaceTx deletes the user's codes, used or not, and stores hashes as the new set
+func (r *RecoveryCodeRepository) ReplaceTx(ctx context.Context, tx *sqlx.Tx, userID int64, hashes []string) error {
+	if err := r.DeleteByUserTx(ctx, tx, userID); err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		if _, err := tx.ExecContext(ctx, queries.RecoveryCodeCreateQuery, userID, hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *RecoveryCodeRepository) DeleteByUserTx(ctx context.Context, tx *sqlx.Tx, userID int64) error {
+	_, err := tx.ExecContext(ctx, queries.RecoveryCodeDeleteByUserQuery, userID)
+	return err
+}
+
+// Use marks an unused code as used and reports whether one matched
+func (r *RecoveryCodeRepository) Use(ctx context.Context, userID int64, hash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, queries.RecoveryCodeUseQuery, userID, hash)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *RecoveryCodeRepository) CountUnused(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, queries.RecoveryCodeCountUnusedQuery, userID)
+	return count, err
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+)
+
+type RecurringExchangeRepository struct {
+	db *database.Postgres
+}
+
+func NewRecurringExchangeRepository(db *database.Postgres) *RecurringExchangeRepository {
+	return &RecurringExchangeRepository{db: db}
+}
+
+func (r *RecurringExchangeRepository) Create(ctx context.Context, rec *domain.RecurringExchange) error {
+	return r.db.QueryRowContext(
+		ctx, queries.RecurringExchangeCreateQuery,
+		rec.UserID, rec.FromCurrencyID, rec.ToCurrencyID, rec.FromAmount, rec.CronExpression, rec.IntervalSeconds,
+		rec.Status, rec.NextRunAt,
+	).Scan(&rec.ID, &rec.CreatedAt, &rec.UpdatedAt)
+}
+
+func (r *RecurringExchangeRepository) GetByID(ctx context.Context, id int64) (*domain.RecurringExchange, error) {
+	var rec domain.RecurringExchange
+	err := r.db.GetContext(ctx, &rec, queries.RecurringExchangeGetByIDQuery, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("recurring exchange not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (r *RecurringExchangeRepository) LockByIDTx(ctx context.Context, tx *sqlx.Tx, id int64) (*domain.RecurringExchange, error) {
+	var rec domain.RecurringExchange
+	err := tx.GetContext(ctx, &rec, queries.RecurringExchangeLockByIDQuery, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("recurring exchange not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (r *RecurringExchangeRepository) GetByUser(ctx context.Context, userID int64, limit, offset int) ([]domain.RecurringExchange, error) {
+	var recs []domain.RecurringExchange
+	err := r.db.SelectContext(ctx, &recs, queries.RecurringExchangeGetByUserQuery, userID, limit, offset)
+	return recs, err
+}
+
+func (r *RecurringExchangeRepository) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := r.db.GetContext(ctx, &count, queries.RecurringExchangeCountByUserQuery, userID)
+	return count, err
+}
+
+// GetDueIDs returns up to limit active schedules whose next run has passed, most overdue first
+func (r *RecurringExchangeRepository) GetDueIDs(ctx context.Context, limit int) ([]int64, error) {
+	var ids []int64
+	err := r.db.SelectContext(ctx, &ids, queries.RecurringExchangeGetDueQuery, limit)
+	return ids, err
+}
+
+func (r *RecurringExchangeRepository) Update(ctx context.Context, rec *domain.RecurringExchange) error {
+	return r.db.QueryRowContext(
+		ctx, queries.RecurringExchangeUpdateQuery,
+		rec.FromAmount, rec.CronExpression, rec.IntervalSeconds, rec.Status, rec.NextRunAt,
+		rec.LastRunAt, rec.ConsecutiveFailures, rec.PauseReason, rec.ID,
+	).Scan(&rec.UpdatedAt)
+}
+
+func (r *RecurringExchangeRepository) UpdateTx(ctx context.Context, tx *sqlx.Tx, rec *domain.RecurringExchange) error {
+	return tx.QueryRowContext(
+		ctx, queries.RecurringExchangeUpdateQuery,
+		rec.FromAmount, rec.CronExpression, rec.IntervalSeconds, rec.Status, rec.NextRunAt,
+		rec.LastRunAt, rec.ConsecutiveFailures, rec.PauseReason, rec.ID,
+	).Scan(&rec.UpdatedAt)
+}
+
+func (r *RecurringExchangeRepository) CreateRunTx(ctx context.Context, tx *sqlx.Tx, run *domain.RecurringExchangeRun) error {
+	return tx.QueryRowContext(
+		ctx, queries.RecurringExchangeRunCreateQuery,
+		run.RecurringExchangeID, run.ExchangeID, run.Status, run.Error, run.ScheduledAt,
+	).Scan(&run.ID, &run.CreatedAt)
+}
+
+func (r *RecurringExchangeRepository) GetRuns(ctx context.Context, recurringExchangeID int64, limit, offset int) ([]domain.RecurringExchangeRun, error) {
+	var runs []domain.RecurringExchangeRun
+	err := r.db.SelectContext(ctx, &runs, queries.RecurringExchangeRunGetByRecurringExchangeQuery, recurringExchangeID, limit, offset)
+	return runs, err
+}
+
+func (r *RecurringExchangeRepository) CountRuns(ctx context.Context, recurringExchangeID int64) (int64, error) {
+	var count int64
+	err := r.db.GetContext(ctx, &count, queries.RecurringExchangeRunCountByRecurringExchangeQuery, recurringExchangeID)
+	return count, err
+}
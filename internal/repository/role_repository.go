@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/cache"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type RoleRepository struct {
+	db           *database.Postgres
+	cacheService *cache.CacheService
+}
+
+func NewRoleRepository(db *database.Postgres, cacheService *cache.CacheService) *RoleRepository {
+	return &RoleRepository{
+		db:           db,
+		cacheService: cacheService,
+	}
+}
+
+func (r *RoleRepository) GetAll(ctx context.Context) ([]domain.Role, error) {
+	if roles, found := r.cacheService.GetRoles(); found {
+		return roles, nil
+	}
+
+	return r.reload(ctx)
+}
+
+func (r *RoleRepository) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	roles, err := r.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range roles {
+		if roles[i].Name == name {
+			return &roles[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("role %s not found", name)
+}
+
+// CreateTx inserts the role. Call Reload after commit.
+func (r *RoleRepository) CreateTx(ctx context.Context, tx *sqlx.Tx, role *domain.Role) error {
+	err := tx.QueryRowContext(
+		ctx, queries.RoleCreateQuery,
+		role.Name, role.Description, role.Permissions,
+	).Scan(&role.IsSystem, &role.CreatedAt, &role.UpdatedAt)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return fmt.Errorf("role %s already exists", role.Name)
+	}
+	return err
+}
+
+// UpdateTx changes a role's description and permissions. System roles cannot be changed.
+// Call Reload after commit.
+func (r *RoleRepository) UpdateTx(ctx context.Context, tx *sqlx.Tx, role *domain.Role) error {
+	err := tx.QueryRowContext(
+		ctx, queries.RoleUpdateQuery,
+		role.Description, role.Permissions, role.Name,
+	).Scan(&role.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("role %s not found or cannot be changed", role.Name)
+	}
+	return err
+}
+
+// DeleteTx removes the role. Call Reload after commit.
+func (r *RoleRepository) DeleteTx(ctx context.Context, tx *sqlx.Tx, name string) error {
+	result, err := tx.ExecContext(ctx, queries.RoleDeleteQuery, name)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("role %s not found or cannot be deleted", name)
+	}
+
+	return nil
+}
+
+func (r *RoleRepository) CountUsers(ctx context.Context, name string) (int64, error) {
+	var count int64
+	err := r.db.GetContext(ctx, &count, queries.RoleCountUsersQuery, name)
+	return count, err
+}
+
+// Reload replaces the cached roles with the committed ones
+func (r *RoleRepository) Reload(ctx context.Context) error {
+	_, err := r.reload(ctx)
+	return err
+}
+
+func (r *RoleRepository) reload(ctx context.Context) ([]domain.Role, error) {
+	var roles []domain.Role
+	if err := r.db.SelectContext(ctx, &roles, queries.RoleGetAllQuery); err != nil {
+		return nil, err
+	}
+
+	r.cacheService.SetRoles(roles)
+	return append([]domain.Role(nil), roles...), nil
+}
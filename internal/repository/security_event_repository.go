@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+)
+
+type SecurityEventRepository struct {
+	db *database.Postgres
+}
+
+func NewSecurityEventRepository(db *database.Postgres) *SecurityEventRepository {
+	return &SecurityEventRepository{db: db}
+}
+
+func (r *SecurityEventRepository) Create(ctx context.Context, event *domain.SecurityEvent) error {
+	return r.db.QueryRowContext(
+		ctx, queries.SecurityEventCreateQuery,
+		event.UserID, event.Type, event.SessionID, event.IPAddress, event.UserAgent, event.Details,
+	).Scan(&event.ID, &event.CreatedAt)
+}
+
+func (r *SecurityEventRepository) CreateTx(ctx context.Context, tx *sqlx.Tx, event *domain.SecurityEvent) error {
+	return tx.QueryRowContext(
+		ctx, queries.SecurityEventCreateQuery,
+		event.UserID, event.Type, event.SessionID, event.IPAddress, event.UserAgent, event.Details,
+	).Scan(&event.ID, &event.CreatedAt)
+}
+
+// List returns events newest first. A zero userID or empty eventType matches all.
+func (r *SecurityEventRepository) List(ctx context.Context, userID int64, eventType string, limit, offset int) ([]domain.SecurityEvent, error) {
+	var events []domain.SecurityEvent
+	err := r.db.SelectContext(ctx, &events, queries.SecurityEventListQuery, userID, eventType, limit, offset)
+	return events, err
+}
+
+func (r *SecurityEventRepository) Count(ctx context.Context, userID int64, eventType string) (int64, error) {
+	var count int64
+	err := r.db.GetContext(ctx, &count, queries.SecurityEventCountQuery, userID, eventType)
+	return count, err
+}
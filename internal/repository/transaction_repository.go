@@ -8,8 +8,14 @@ import (
 	"github.com/caspianex/exchange-backend/const/queries"
 	"github.com/caspianex/exchange-backend/internal/domain"
 	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
 )
 
+// uniqueViolation is the Postgres error code for a unique constraint violation
+const uniqueViolation = "23505"
+
 type TransactionRepository struct {
 	db *database.Postgres
 }
@@ -21,8 +27,52 @@ func NewTransactionRepository(db *database.Postgres) *TransactionRepository {
 func (r *TransactionRepository) Create(ctx context.Context, tx *domain.Transaction) error {
 	return r.db.QueryRowContext(
 		ctx, queries.TransactionCreateQuery,
-		tx.UserID, tx.WalletID, tx.Type, tx.Amount, tx.Fee, tx.Status, tx.TxHash,
+		tx.UserID, tx.WalletID, tx.Type, tx.Amount, tx.Fee, tx.FeeRuleID, tx.Status, tx.TxHash, tx.Address, tx.Network,
+		tx.Description,
+	).Scan(&tx.ID, &tx.CreatedAt, &tx.UpdatedAt)
+}
+
+func (r *TransactionRepository) CreateTx(ctx context.Context, sqlTx *sqlx.Tx, tx *domain.Transaction) error {
+	err := sqlTx.QueryRowContext(
+		ctx, queries.TransactionCreateQuery,
+		tx.UserID, tx.WalletID, tx.Type, tx.Amount, tx.Fee, tx.FeeRuleID, tx.Status, tx.TxHash, tx.Address, tx.Network,
+		tx.Description,
+	).Scan(&tx.ID, &tx.CreatedAt, &tx.UpdatedAt)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return fmt.Errorf("transaction hash already recorded")
+	}
+	return err
+}
+
+// CreateChainDeposit books a deposit observed on chain. It returns false when the
+// output is already recorded for the network.
+func (r *TransactionRepository) CreateChainDeposit(ctx context.Context, tx *domain.Transaction) (bool, error) {
+	err := r.db.QueryRowContext(
+		ctx, queries.TransactionCreateChainDepositQuery,
+		tx.UserID, tx.WalletID, tx.Type, tx.Amount, tx.Fee, tx.Status, tx.TxHash, tx.OutputIndex, tx.Address, tx.Network,
+		tx.BlockHeight, tx.Confirmations,
 	).Scan(&tx.ID, &tx.CreatedAt, &tx.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UpdateConfirmationsTx stores a chain deposit's confirmation count and status
+func (r *TransactionRepository) UpdateConfirmationsTx(ctx context.Context, sqlTx *sqlx.Tx, tx *domain.Transaction) error {
+	return sqlTx.QueryRowContext(
+		ctx, queries.TransactionUpdateConfirmationsQuery,
+		tx.Status, tx.Confirmations, tx.ID,
+	).Scan(&tx.UpdatedAt)
+}
+
+func (r *TransactionRepository) GetPendingChainDeposits(ctx context.Context, network string) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	err := r.db.SelectContext(ctx, &transactions, queries.TransactionGetPendingChainDepositsQuery, network)
+	return transactions, err
 }
 
 func (r *TransactionRepository) GetByID(ctx context.Context, id int64) (*domain.Transaction, error) {
@@ -34,6 +84,19 @@ func (r *TransactionRepository) GetByID(ctx context.Context, id int64) (*domain.
 	return &tx, err
 }
 
+// LockByIDTx selects a transaction with SELECT ... FOR UPDATE inside the caller's transaction
+func (r *TransactionRepository) LockByIDTx(ctx context.Context, sqlTx *sqlx.Tx, id int64) (*domain.Transaction, error) {
+	var tx domain.Transaction
+	err := sqlTx.GetContext(ctx, &tx, queries.TransactionLockByIDQuery, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("transaction not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
 func (r *TransactionRepository) GetUserTransactions(ctx context.Context, userID int64, limit, offset int) ([]domain.Transaction, error) {
 	var transactions []domain.Transaction
 	err := r.db.SelectContext(ctx, &transactions, queries.TransactionGetUserTransactionsQuery, userID, limit, offset)
@@ -47,6 +110,22 @@ func (r *TransactionRepository) Update(ctx context.Context, tx *domain.Transacti
 	).Scan(&tx.UpdatedAt)
 }
 
+// UpdateReviewTx stores a status change made during withdrawal review
+func (r *TransactionRepository) UpdateReviewTx(ctx context.Context, sqlTx *sqlx.Tx, tx *domain.Transaction) error {
+	return sqlTx.QueryRowContext(
+		ctx, queries.TransactionUpdateReviewQuery,
+		tx.Status, tx.TxHash, tx.ReviewedBy, tx.ReviewedAt, tx.RejectReason, tx.ID,
+	).Scan(&tx.UpdatedAt)
+}
+
+// GetDailyWithdrawalVolumeTx sums the amount withdrawn from the wallet since the start of the day,
+// including withdrawals still under review
+func (r *TransactionRepository) GetDailyWithdrawalVolumeTx(ctx context.Context, sqlTx *sqlx.Tx, walletID int64) (decimal.Decimal, error) {
+	var volume decimal.Decimal
+	err := sqlTx.GetContext(ctx, &volume, queries.TransactionGetDailyWithdrawalVolumeQuery, walletID)
+	return volume, err
+}
+
 func (r *TransactionRepository) GetPendingTransactions(ctx context.Context) ([]domain.Transaction, error) {
 	var transactions []domain.Transaction
 	err := r.db.SelectContext(ctx, &transactions, queries.TransactionGetPendingQuery)
@@ -58,3 +137,36 @@ func (r *TransactionRepository) GetAllTransactions(ctx context.Context, limit, o
 	err := r.db.SelectContext(ctx, &transactions, queries.TransactionGetAllQuery, limit, offset)
 	return transactions, err
 }
+
+func (r *TransactionRepository) GetTransactionsByType(ctx context.Context, txType domain.TransactionType, status string, limit, offset int) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+
+	qb := newQueryBuilder(queries.TransactionListBaseQuery)
+	qb.AddWhere(fmt.Sprintf("type = $%d", qb.paramCounter), txType)
+
+	if status != "" {
+		qb.AddWhere(fmt.Sprintf("status = $%d", qb.paramCounter), status)
+	}
+
+	query, args := qb.Build("ORDER BY created_at ASC", fmt.Sprintf("LIMIT $%d OFFSET $%d", qb.paramCounter, qb.paramCounter+1))
+	args = append(args, limit, offset)
+
+	err := r.db.SelectContext(ctx, &transactions, query, args...)
+	return transactions, err
+}
+
+func (r *TransactionRepository) GetTransactionsByTypeCount(ctx context.Context, txType domain.TransactionType, status string) (int64, error) {
+	var count int64
+
+	qb := newQueryBuilder(queries.TransactionCountBaseQuery)
+	qb.AddWhere(fmt.Sprintf("type = $%d", qb.paramCounter), txType)
+
+	if status != "" {
+		qb.AddWhere(fmt.Sprintf("status = $%d", qb.paramCounter), status)
+	}
+
+	query, args := qb.Build("", "")
+
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+)
+
+type UserAdminActionRepository struct {
+	db *database.Postgres
+}
+
+func NewUserAdminActionRepository(db *database.Postgres) *UserAdminActionRepository {
+	return &UserAdminActionRepository{db: db}
+}
+
+func (r *UserAdminActionRepository) CreateTx(ctx context.Context, tx *sqlx.Tx, action *domain.UserAdminAction) error {
+	return tx.QueryRowContext(
+		ctx, queries.UserAdminActionCreateQuery,
+		action.UserID, action.AdminID, action.Action, action.Reason, action.Details,
+	).Scan(&action.ID, &action.CreatedAt)
+}
+
+// ListByUser returns the actions taken on the user, newest first
+func (r *UserAdminActionRepository) ListByUser(ctx context.Context, userID int64, limit, offset int) ([]domain.UserAdminAction, error) {
+	var actions []domain.UserAdminAction
+	err := r.db.SelectContext(ctx, &actions, queries.UserAdminActionListByUserQuery, userID, limit, offset)
+	return actions, err
+}
+
+func (r *UserAdminActionRepository) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := r.db.GetContext(ctx, &count, queries.UserAdminActionCountByUserQuery, userID)
+	return count, err
+}
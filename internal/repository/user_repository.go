@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/caspianex/exchange-backend/const/queries"
 	"github.com/caspianex/exchange-backend/internal/domain"
 	"github.com/caspianex/exchange-backend/pkg/cache"
 	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
 )
 
 type UserRepository struct {
@@ -28,7 +30,7 @@ func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 		ctx, queries.UserCreateQuery,
 		user.Email, user.PasswordHash, user.FirstName, user.LastName,
 		user.Role, user.IsActive, user.IsVerified,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt); err != nil {
+	).Scan(&user.ID, &user.FeeTier, &user.KYCLevel, &user.CreatedAt, &user.UpdatedAt); err != nil {
 		return err
 	}
 
@@ -60,6 +62,20 @@ func (r *UserRepository) GetByID(ctx context.Context, id int64) (*domain.User, e
 	return &user, nil
 }
 
+// LockByIDTx selects a user with SELECT ... FOR UPDATE inside the caller's transaction,
+// bypassing the cache
+func (r *UserRepository) LockByIDTx(ctx context.Context, tx *sqlx.Tx, id int64) (*domain.User, error) {
+	var user domain.User
+	err := tx.GetContext(ctx, &user, queries.UserLockByIDQuery, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	// Check cache first
 	if user, found := r.cacheService.GetUserByEmail(email); found {
@@ -96,6 +112,42 @@ func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	return nil
 }
 
+func (r *UserRepository) UpdateFeeTierTx(ctx context.Context, tx *sqlx.Tx, user *domain.User) error {
+	return tx.QueryRowContext(
+		ctx, queries.UserUpdateFeeTierQuery,
+		user.FeeTier, user.ID,
+	).Scan(&user.UpdatedAt)
+}
+
+// UpdateTx stores the names and the active and verified flags. Call RefreshCache after commit.
+func (r *UserRepository) UpdateTx(ctx context.Context, tx *sqlx.Tx, user *domain.User) error {
+	return tx.QueryRowContext(
+		ctx, queries.UserUpdateQuery,
+		user.FirstName, user.LastName, user.IsActive, user.IsVerified, user.ID,
+	).Scan(&user.UpdatedAt)
+}
+
+func (r *UserRepository) UpdateRoleTx(ctx context.Context, tx *sqlx.Tx, user *domain.User) error {
+	return tx.QueryRowContext(
+		ctx, queries.UserUpdateRoleQuery,
+		user.Role, user.ID,
+	).Scan(&user.UpdatedAt)
+}
+
+// UpdateKYCLevelTx stores the level granted by an approved KYC application. The caller
+// refreshes the cache once the transaction commits.
+func (r *UserRepository) UpdateKYCLevelTx(ctx context.Context, tx *sqlx.Tx, user *domain.User) error {
+	return tx.QueryRowContext(
+		ctx, queries.UserUpdateKYCLevelQuery,
+		user.KYCLevel, user.ID,
+	).Scan(&user.UpdatedAt)
+}
+
+// RefreshCache replaces the cached user with committed state
+func (r *UserRepository) RefreshCache(user *domain.User) {
+	r.cacheService.SetUser(user)
+}
+
 func (r *UserRepository) List(ctx context.Context, limit, offset int, email string) ([]domain.User, error) {
 	// This operation is not cached - admin operation, not frequent
 	var users []domain.User
@@ -137,26 +189,26 @@ func (r *UserRepository) Count(ctx context.Context, email string) (int64, error)
 func (r *UserRepository) CreateSession(ctx context.Context, session *domain.UserSession) error {
 	if err := r.db.QueryRowContext(
 		ctx, queries.UserSessionCreateQuery,
-		session.UserID, session.RefreshToken, session.ExpiresAt,
-	).Scan(&session.ID, &session.CreatedAt); err != nil {
+		session.UserID, session.RefreshTokenHash, session.ExpiresAt, session.UserAgent, session.IPAddress, session.RememberMe,
+	).Scan(&session.ID, &session.CreatedAt, &session.LastUsedAt); err != nil {
 		return err
 	}
 
 	// Update cache immediately (no DB write - already done above)
-	r.cacheService.SetSession(session, session.ExpiresAt.Sub(session.CreatedAt))
+	r.cacheService.SetSession(session, time.Until(session.ExpiresAt))
 
 	return nil
 }
 
-func (r *UserRepository) GetSessionByToken(ctx context.Context, token string) (*domain.UserSession, error) {
+func (r *UserRepository) GetSessionByTokenHash(ctx context.Context, tokenHash string) (*domain.UserSession, error) {
 	// Check cache first
-	if session, found := r.cacheService.GetSession(token); found {
+	if session, found := r.cacheService.GetSession(tokenHash); found {
 		return session, nil
 	}
 
 	// Cache miss - fetch from DB
 	var session domain.UserSession
-	err := r.db.GetContext(ctx, &session, queries.UserSessionGetByTokenQuery, token)
+	err := r.db.GetContext(ctx, &session, queries.UserSessionGetByTokenQuery, tokenHash)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("session not found or expired")
 	}
@@ -165,19 +217,163 @@ func (r *UserRepository) GetSessionByToken(ctx context.Context, token string) (*
 	}
 
 	// Update cache
-	r.cacheService.SetSession(&session, session.ExpiresAt.Sub(session.CreatedAt))
+	r.cacheService.SetSession(&session, time.Until(session.ExpiresAt))
 
 	return &session, nil
 }
 
-func (r *UserRepository) DeleteSession(ctx context.Context, token string) error {
-	// Delete from DB immediately (critical operation)
-	if _, err := r.db.ExecContext(ctx, queries.UserSessionDeleteQuery, token); err != nil {
+// RotateSessionTx replaces the session's refresh token hash, keeping its ID so access tokens
+// issued for it stay attributable to the same device, and records the old hash as rotated.
+// It returns false when oldHash is no longer the session's current token, e.g. because a
+// concurrent refresh rotated it first.
+func (r *UserRepository) RotateSessionTx(ctx context.Context, tx *sqlx.Tx, session *domain.UserSession, oldHash string) (bool, error) {
+	err := tx.QueryRowContext(
+		ctx, queries.UserSessionRotateQuery,
+		session.RefreshTokenHash, session.ExpiresAt, session.UserAgent, session.IPAddress, session.ID, oldHash,
+	).Scan(&session.LastUsedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, queries.RotatedRefreshTokenCreateQuery, oldHash, session.ID, session.UserID); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CacheRotatedSession swaps the cached session over to its new token hash after RotateSessionTx commits
+func (r *UserRepository) CacheRotatedSession(session *domain.UserSession, oldHash string) {
+	r.cacheService.DeleteSession(oldHash)
+	r.cacheService.SetSession(session, time.Until(session.ExpiresAt))
+}
+
+// GetRotatedRefreshToken returns the rotated token with tokenHash, or nil if there is none
+func (r *UserRepository) GetRotatedRefreshToken(ctx context.Context, tokenHash string) (*domain.RotatedRefreshToken, error) {
+	var token domain.RotatedRefreshToken
+	err := r.db.GetContext(ctx, &token, queries.RotatedRefreshTokenGetQuery, tokenHash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *UserRepository) ListSessionsByUser(ctx context.Context, userID int64) ([]domain.UserSession, error) {
+	var sessions []domain.UserSession
+	err := r.db.SelectContext(ctx, &sessions, queries.UserSessionListByUserQuery, userID)
+	return sessions, err
+}
+
+// RevokeSessionByTokenHashTx deletes the session holding the token. The revoke methods return the
+// deleted sessions; the caller passes them to ForgetSessions once the transaction commits.
+func (r *UserRepository) RevokeSessionByTokenHashTx(ctx context.Context, tx *sqlx.Tx, tokenHash string) ([]domain.UserSession, error) {
+	return r.revokeTx(ctx, tx, queries.UserSessionDeleteQuery, tokenHash)
+}
+
+func (r *UserRepository) RevokeSessionTx(ctx context.Context, tx *sqlx.Tx, userID, sessionID int64) ([]domain.UserSession, error) {
+	return r.revokeTx(ctx, tx, queries.UserSessionDeleteByIDQuery, sessionID, userID)
+}
+
+func (r *UserRepository) RevokeSessionsByUserTx(ctx context.Context, tx *sqlx.Tx, userID int64) ([]domain.UserSession, error) {
+	return r.revokeTx(ctx, tx, queries.UserSessionDeleteByUserQuery, userID)
+}
+
+// revokeTx deletes sessions with query and lists them as revoked until their access tokens expire
+func (r *UserRepository) revokeTx(ctx context.Context, tx *sqlx.Tx, query string, args ...interface{}) ([]domain.UserSession, error) {
+	var sessions []domain.UserSession
+	if err := tx.SelectContext(ctx, &sessions, query, args...); err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		if _, err := tx.ExecContext(ctx, queries.RevokedSessionCreateQuery, session.ID, session.ExpiresAt); err != nil {
+			return nil, err
+		}
+	}
+
+	return sessions, nil
+}
+
+// ForgetSessions evicts revoked sessions from the cache and adds them to the in-memory
+// revocation list checked on every authenticated request
+func (r *UserRepository) ForgetSessions(sessions []domain.UserSession) {
+	for _, session := range sessions {
+		r.cacheService.DeleteSession(session.RefreshTokenHash)
+		r.cacheService.RevokeSession(session.ID, session.ExpiresAt)
+	}
+}
+
+func (r *UserRepository) UpdatePasswordTx(ctx context.Context, tx *sqlx.Tx, user *domain.User) error {
+	return tx.QueryRowContext(
+		ctx, queries.UserUpdatePasswordQuery,
+		user.PasswordHash, user.ID,
+	).Scan(&user.UpdatedAt)
+}
+
+func (r *UserRepository) MarkVerifiedTx(ctx context.Context, tx *sqlx.Tx, user *domain.User) error {
+	if err := tx.QueryRowContext(ctx, queries.UserMarkVerifiedQuery, user.ID).Scan(&user.UpdatedAt); err != nil {
+		return err
+	}
+	user.IsVerified = true
+	return nil
+}
+
+// SetTOTPSecret stores a new, not yet confirmed TOTP secret and disables 2FA until it is confirmed
+func (r *UserRepository) SetTOTPSecret(ctx context.Context, user *domain.User) error {
+	if err := r.db.QueryRowContext(
+		ctx, queries.UserSetTOTPSecretQuery,
+		user.TOTPSecret, user.ID,
+	).Scan(&user.UpdatedAt); err != nil {
 		return err
 	}
 
-	// Delete from cache
-	r.cacheService.DeleteSession(token)
+	user.TOTPEnabled = false
+	user.TOTPLastStep = nil
+	r.cacheService.SetUser(user)
+
+	return nil
+}
+
+func (r *UserRepository) EnableTOTPTx(ctx context.Context, tx *sqlx.Tx, user *domain.User, step int64) error {
+	if err := tx.QueryRowContext(ctx, queries.UserEnableTOTPQuery, step, user.ID).Scan(&user.UpdatedAt); err != nil {
+		return err
+	}
+	user.TOTPEnabled = true
+	user.TOTPLastStep = &step
+	return nil
+}
 
+func (r *UserRepository) DisableTOTPTx(ctx context.Context, tx *sqlx.Tx, user *domain.User) error {
+	if err := tx.QueryRowContext(ctx, queries.UserDisableTOTPQuery, user.ID).Scan(&user.UpdatedAt); err != nil {
+		return err
+	}
+	user.TOTPSecret = nil
+	user.TOTPEnabled = false
+	user.TOTPLastStep = nil
 	return nil
 }
+
+// UseTOTPStep records step as the last accepted TOTP step. It returns false when the step
+// is not newer than the stored one, i.e. the code was already used.
+func (r *UserRepository) UseTOTPStep(ctx context.Context, user *domain.User, step int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, queries.UserUseTOTPStepQuery, step, user.ID)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+
+	user.TOTPLastStep = &step
+	r.cacheService.SetUser(user)
+
+	return true, nil
+}
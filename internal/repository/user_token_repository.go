@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+)
+
+type UserTokenRepository struct {
+	db *database.Postgres
+}
+
+func NewUserTokenRepository(db *database.Postgres) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+func (r *UserTokenRepository) CreateTx(ctx context.Context, tx *sqlx.Tx, token *domain.UserToken) error {
+	return tx.QueryRowContext(
+		ctx, queries.UserTokenCreateQuery,
+		token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+func (r *UserTokenRepository) LockByHashTx(ctx context.Context, tx *sqlx.Tx, hash string, purpose domain.UserTokenPurpose) (*domain.UserToken, error) {
+	var token domain.UserToken
+	err := tx.GetContext(ctx, &token, queries.UserTokenLockByHashQuery, hash, purpose)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *UserTokenRepository) MarkUsedTx(ctx context.Context, tx *sqlx.Tx, token *domain.UserToken) error {
+	return tx.QueryRowContext(ctx, queries.UserTokenMarkUsedQuery, token.ID).Scan(&token.UsedAt)
+}
+
+// RevokeUnusedTx invalidates the user's outstanding tokens for purpose
+func (r *UserTokenRepository) RevokeUnusedTx(ctx context.Context, tx *sqlx.Tx, userID int64, purpose domain.UserTokenPurpose) error {
+	_, err := tx.ExecContext(ctx, queries.UserTokenRevokeUnusedQuery, userID, purpose)
+	return err
+}
+
+// GetLatestCreatedAt returns when the user was last issued a token for purpose, or nil if never
+func (r *UserTokenRepository) GetLatestCreatedAt(ctx context.Context, userID int64, purpose domain.UserTokenPurpose) (*time.Time, error) {
+	var createdAt *time.Time
+	err := r.db.GetContext(ctx, &createdAt, queries.UserTokenGetLatestCreatedQuery, userID, purpose)
+	return createdAt, err
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/caspianex/exchange-backend/internal/domain"
 	"github.com/caspianex/exchange-backend/pkg/cache"
 	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
 )
 
 type WalletRepository struct {
@@ -88,7 +91,7 @@ func (r *WalletRepository) GetUserWallets(ctx context.Context, userID int64) ([]
 	return wallets, nil
 }
 
-func (r *WalletRepository) UpdateBalance(ctx context.Context, walletID int64, balance, locked float64) error {
+func (r *WalletRepository) UpdateBalance(ctx context.Context, walletID int64, balance, locked decimal.Decimal) error {
 	// Get wallet first to know userID and currencyID for cache update
 	var wallet domain.Wallet
 	if err := r.db.GetContext(ctx, &wallet, queries.WalletGetForUpdateQuery, walletID); err != nil {
@@ -109,6 +112,57 @@ func (r *WalletRepository) UpdateBalance(ctx context.Context, walletID int64, ba
 	return nil
 }
 
+// LockByUserAndCurrencies selects the user's wallets for the given currencies with SELECT ... FOR UPDATE.
+// Rows stay locked until the caller's transaction ends.
+func (r *WalletRepository) LockByUserAndCurrencies(ctx context.Context, tx *sqlx.Tx, userID int64, currencyIDs ...int32) (map[int32]*domain.Wallet, error) {
+	var wallets []domain.Wallet
+	if err := tx.SelectContext(ctx, &wallets, queries.WalletLockByUserAndCurrenciesQuery, userID, pq.Array(currencyIDs)); err != nil {
+		return nil, err
+	}
+
+	result := make(map[int32]*domain.Wallet, len(wallets))
+	for i := range wallets {
+		result[wallets[i].CurrencyID] = &wallets[i]
+	}
+
+	for _, currencyID := range currencyIDs {
+		if _, ok := result[currencyID]; !ok {
+			return nil, fmt.Errorf("wallet not found")
+		}
+	}
+
+	return result, nil
+}
+
+// LockByIDTx selects a wallet with SELECT ... FOR UPDATE inside the caller's transaction
+func (r *WalletRepository) LockByIDTx(ctx context.Context, tx *sqlx.Tx, id int64) (*domain.Wallet, error) {
+	var wallet domain.Wallet
+	err := tx.GetContext(ctx, &wallet, queries.WalletLockByIDQuery, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("wallet not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// UpdateBalanceTx writes the wallet's balance and locked amount inside the caller's transaction.
+// The cache is not touched; call RefreshCache once the transaction has committed.
+func (r *WalletRepository) UpdateBalanceTx(ctx context.Context, tx *sqlx.Tx, wallet *domain.Wallet) error {
+	return tx.QueryRowContext(
+		ctx, queries.WalletUpdateBalanceQuery,
+		wallet.Balance, wallet.Locked, wallet.ID,
+	).Scan(&wallet.UpdatedAt)
+}
+
+// RefreshCache stores committed wallet state in the cache
+func (r *WalletRepository) RefreshCache(wallets ...*domain.Wallet) {
+	for _, wallet := range wallets {
+		r.cacheService.SetWallet(wallet)
+	}
+}
+
 func (r *WalletRepository) GetCurrencyByCode(ctx context.Context, code string) (*domain.Currency, error) {
 	// Check cache first - currencies are heavily cached
 	if currency, found := r.cacheService.GetCurrency(code); found {
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+// AddressProvider hands out a deposit address for a user and currency.
+// Allocation runs inside the caller's transaction so it commits together with the request that triggered it.
+type AddressProvider interface {
+	Allocate(ctx context.Context, tx *sqlx.Tx, userID int64, currency *domain.Currency) (*domain.DepositAddress, error)
+}
+
+// PoolAddressProvider allocates from pre-generated addresses imported into deposit_addresses.
+// An xpub-based provider deriving addresses on demand can replace it behind the same interface.
+type PoolAddressProvider struct {
+	depositAddressRepo *repository.DepositAddressRepository
+}
+
+func NewPoolAddressProvider(depositAddressRepo *repository.DepositAddressRepository) *PoolAddressProvider {
+	return &PoolAddressProvider{
+		depositAddressRepo: depositAddressRepo,
+	}
+}
+
+func (p *PoolAddressProvider) Allocate(ctx context.Context, tx *sqlx.Tx, userID int64, currency *domain.Currency) (*domain.DepositAddress, error) {
+	return p.depositAddressRepo.AllocateFromPoolTx(ctx, tx, userID, currency.ID)
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/models"
+	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/caspianex/exchange-backend/pkg/auth"
+	"github.com/lib/pq"
+)
+
+const (
+	maxAPIKeysPerUser = 20
+	// apiKeySignatureWindow is how far a request timestamp may be from the server clock
+	apiKeySignatureWindow = 30 * time.Second
+	// apiKeyTouchInterval limits how often last_used_at is written for a busy key
+	apiKeyTouchInterval  = time.Minute
+	minAPIKeyNonceLength = 8
+	maxAPIKeyNonceLength = 64
+)
+
+// APIKeyService manages API keys and authenticates requests signed with them
+type APIKeyService struct {
+	apiKeyRepo *repository.APIKeyRepository
+	userRepo   *repository.UserRepository
+	twoFactor  *TwoFactorService
+	secrets    *auth.SecretBox
+}
+
+func NewAPIKeyService(
+	apiKeyRepo *repository.APIKeyRepository,
+	userRepo *repository.UserRepository,
+	twoFactor *TwoFactorService,
+	secrets *auth.SecretBox,
+) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+		twoFactor:  twoFactor,
+		secrets:    secrets,
+	}
+}
+
+func (s *APIKeyService) List(ctx context.Context, userID int64) ([]domain.APIKey, error) {
+	keys, err := s.apiKeyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Create issues a key and returns it with its secret. Keys that can withdraw need an IP
+// allowlist and a TOTP code.
+func (s *APIKeyService) Create(ctx context.Context, userID int64, req *models.CreateAPIKeyRequest) (*models.APIKeyCreatedResponse, error) {
+	key := &domain.APIKey{
+		UserID:     userID,
+		Name:       req.Name,
+		Scopes:     pq.StringArray{},
+		AllowedIPs: pq.StringArray{},
+	}
+
+	seen := make(map[string]bool, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			key.Scopes = append(key.Scopes, scope)
+		}
+	}
+
+	for _, entry := range req.AllowedIPs {
+		if net.ParseIP(entry) == nil {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return nil, fmt.Errorf("invalid IP address or CIDR range %q", entry)
+			}
+		}
+		key.AllowedIPs = append(key.AllowedIPs, entry)
+	}
+
+	count, err := s.apiKeyRepo.CountActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count api keys: %w", err)
+	}
+	if count >= maxAPIKeysPerUser {
+		return nil, fmt.Errorf("at most %d api keys can be active", maxAPIKeysPerUser)
+	}
+
+	if key.HasScope(domain.APIKeyScopeWithdraw) {
+		if len(key.AllowedIPs) == 0 {
+			return nil, fmt.Errorf("keys with the withdraw scope need allowed_ips")
+		}
+		// Checked last so a rejected request does not burn the code
+		if err := s.twoFactor.RequireTOTP(ctx, userID, req.TOTPCode); err != nil {
+			return nil, err
+		}
+	}
+
+	keyID, secret, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	key.KeyID = keyID
+	if key.SecretEncrypted, err = s.secrets.Seal(secret); err != nil {
+		return nil, fmt.Errorf("failed to encrypt api key secret: %w", err)
+	}
+
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return &models.APIKeyCreatedResponse{APIKey: key, Secret: secret}, nil
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, userID, id int64) error {
+	return s.apiKeyRepo.Revoke(ctx, userID, id)
+}
+
+// AuthenticateAPIKey checks a signed request and returns its key and user. A nonce is accepted
+// once per key while the timestamp is within apiKeySignatureWindow of the server clock.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, req *domain.APIKeyRequest) (*domain.APIKey, *domain.User, error) {
+	ts, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid request timestamp")
+	}
+
+	now := time.Now()
+	if diff := now.Sub(time.Unix(ts, 0)); diff > apiKeySignatureWindow || diff < -apiKeySignatureWindow {
+		return nil, nil, fmt.Errorf("request timestamp is outside the allowed window")
+	}
+
+	if len(req.Nonce) < minAPIKeyNonceLength || len(req.Nonce) > maxAPIKeyNonceLength {
+		return nil, nil, fmt.Errorf("nonce must be %d to %d characters", minAPIKeyNonceLength, maxAPIKeyNonceLength)
+	}
+
+	key, err := s.apiKeyRepo.GetByKeyID(ctx, req.KeyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	if key == nil || key.RevokedAt != nil {
+		return nil, nil, fmt.Errorf("invalid api key")
+	}
+
+	if !key.AllowsIP(req.IPAddress) {
+		return nil, nil, fmt.Errorf("ip address is not allowed for this api key")
+	}
+
+	secret, err := s.secrets.Open(key.SecretEncrypted)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid api key")
+	}
+
+	payload := auth.APIRequestPayload(req.Method, req.URI, req.Timestamp, req.Nonce, req.Body)
+	if !auth.VerifyAPIRequest(secret, payload, req.Signature) {
+		return nil, nil, fmt.Errorf("invalid signature")
+	}
+
+	// Only signed requests record nonces, so nobody else can use up a client's nonces
+	fresh, err := s.apiKeyRepo.UseNonce(ctx, key.ID, req.Nonce, now.Add(2*apiKeySignatureWindow))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to record nonce: %w", err)
+	}
+	if !fresh {
+		return nil, nil, fmt.Errorf("nonce has already been used")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, key.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.IsActive {
+		return nil, nil, fmt.Errorf("account is deactivated")
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := s.apiKeyRepo.Touch(ctx, key.ID, req.IPAddress); err != nil {
+			return nil, nil, fmt.Errorf("failed to update api key: %w", err)
+		}
+	}
+
+	return key, user, nil
+}
@@ -4,43 +4,89 @@ import (
 	"context"
 	"fmt"
 	"github.com/caspianex/exchange-backend/pkg/logger"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/caspianex/exchange-backend/internal/domain"
 	"github.com/caspianex/exchange-backend/internal/models"
 	"github.com/caspianex/exchange-backend/internal/repository"
 	"github.com/caspianex/exchange-backend/pkg/auth"
+	"github.com/caspianex/exchange-backend/pkg/database"
 	"github.com/caspianex/exchange-backend/pkg/email"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+)
+
+// tokenEmailCooldown is the minimum time between two verification or reset emails to one user
+const tokenEmailCooldown = time.Minute
+
+// mfaPendingTTL is how long the second login step may take after the password was accepted
+const mfaPendingTTL = 5 * time.Minute
+
+const (
+	defaultSessionExpiry    = 7 * 24 * time.Hour
+	rememberMeSessionExpiry = 30 * 24 * time.Hour
 )
 
 type AuthService struct {
-	userRepo     *repository.UserRepository
-	walletRepo   *repository.WalletRepository
-	jwtManager   *auth.JWTManager
-	emailService *email.EmailService
-	bcryptCost   int
-	logger       *logger.Logger
+	db              *database.Postgres
+	userRepo        *repository.UserRepository
+	walletRepo      *repository.WalletRepository
+	tokenRepo       *repository.UserTokenRepository
+	securityEvents  *repository.SecurityEventRepository
+	jwtManager      *auth.JWTManager
+	emailService    *email.EmailService
+	twoFactor       *TwoFactorService
+	loginThrottle   *LoginThrottle
+	bcryptCost      int
+	frontendURL     string
+	verificationTTL time.Duration
+	resetTTL        time.Duration
+	logger          *logger.Logger
+
+	// dummyPasswordHash is checked for unknown emails so they take as long as a wrong password
+	dummyPasswordHash string
 }
 
 func NewAuthService(
+	db *database.Postgres,
 	userRepo *repository.UserRepository,
 	walletRepo *repository.WalletRepository,
+	tokenRepo *repository.UserTokenRepository,
+	securityEvents *repository.SecurityEventRepository,
 	jwtManager *auth.JWTManager,
 	emailService *email.EmailService,
+	twoFactor *TwoFactorService,
+	loginThrottle *LoginThrottle,
 	bcryptCost int,
+	frontendURL string,
+	verificationTTL, resetTTL time.Duration,
 	logger *logger.Logger,
 ) *AuthService {
+	dummyPasswordHash, _ := auth.HashPassword("not a real password", bcryptCost)
+
 	return &AuthService{
-		userRepo:     userRepo,
-		walletRepo:   walletRepo,
-		jwtManager:   jwtManager,
-		emailService: emailService,
-		bcryptCost:   bcryptCost,
-		logger:       logger,
+		db:              db,
+		userRepo:        userRepo,
+		walletRepo:      walletRepo,
+		tokenRepo:       tokenRepo,
+		securityEvents:  securityEvents,
+		jwtManager:      jwtManager,
+		emailService:    emailService,
+		twoFactor:       twoFactor,
+		loginThrottle:   loginThrottle,
+		bcryptCost:      bcryptCost,
+		frontendURL:     strings.TrimRight(frontendURL, "/"),
+		verificationTTL: verificationTTL,
+		resetTTL:        resetTTL,
+		logger:          logger,
+
+		dummyPasswordHash: dummyPasswordHash,
 	}
 }
 
-func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest) (*models.AuthResponse, error) {
+func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest, meta domain.SessionMeta) (*models.AuthResponse, error) {
 	existing, _ := s.userRepo.GetByEmail(ctx, req.Email)
 	if existing != nil {
 		return nil, fmt.Errorf("email already registered")
@@ -71,8 +117,8 @@ func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest)
 			wallet := &domain.Wallet{
 				UserID:     user.ID,
 				CurrencyID: currency.ID,
-				Balance:    0,
-				Locked:     0,
+				Balance:    decimal.Zero,
+				Locked:     decimal.Zero,
 			}
 			s.walletRepo.Create(ctx, wallet)
 		}
@@ -82,31 +128,124 @@ func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest)
 		s.emailService.SendWelcomeEmail(user.Email, user.FirstName)
 	}()
 
-	return s.generateTokens(ctx, user)
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		s.logger.Error("Failed to issue verification token", "user_id", user.ID, "error", err)
+	}
+
+	return s.generateTokens(ctx, user, false, meta)
 }
 
-func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*models.AuthResponse, error) {
+// Login checks the password. Repeated failures slow down and then temporarily lock further
+// attempts for the email and the client IP, whether or not the email is registered.
+func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, meta domain.SessionMeta) (*models.AuthResponse, error) {
+	if err := s.loginThrottle.Reserve(ctx, req.Email, meta.IPAddress); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
-		s.logger.Error("Get by email err:", err)
+		auth.CheckPassword(req.Password, s.dummyPasswordHash)
+		s.loginFailed(ctx, nil, req.Email, meta)
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
-	if !auth.CheckPassword(req.Password, user.PasswordHash) {
+	// A deactivated account answers like a wrong password, so the password is not confirmed
+	if !auth.CheckPassword(req.Password, user.PasswordHash) || !user.IsActive {
+		s.loginFailed(ctx, user, req.Email, meta)
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
+	if user.TOTPEnabled {
+		// The account's failures are kept until the second factor is verified too, so bad codes
+		// keep adding up however often the password is entered again
+		s.loginThrottle.Release(ctx, req.Email, meta.IPAddress)
+
+		mfaToken, err := s.jwtManager.GenerateMFAPendingToken(user.ID, req.RememberMe, mfaPendingTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate mfa token: %w", err)
+		}
+		return &models.AuthResponse{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	s.loginThrottle.Succeeded(ctx, req.Email, meta.IPAddress)
+
+	return s.generateTokens(ctx, user, req.RememberMe, meta)
+}
+
+// loginFailed counts a failed sign-in. When it locks a registered account, the owner is told
+// in the background so the response takes as long as for an unknown email.
+func (s *AuthService) loginFailed(ctx context.Context, user *domain.User, emailAddr string, meta domain.SessionMeta) {
+	lockedUntil := s.loginThrottle.Failed(ctx, emailAddr, meta.IPAddress)
+	if lockedUntil == nil || user == nil || !user.IsActive {
+		return
+	}
+
+	go s.notifyAccountLocked(user, *lockedUntil, meta)
+}
+
+func (s *AuthService) notifyAccountLocked(user *domain.User, lockedUntil time.Time, meta domain.SessionMeta) {
+	err := s.securityEvents.Create(context.Background(), &domain.SecurityEvent{
+		UserID:    &user.ID,
+		Type:      domain.SecurityEventAccountLocked,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Details:   fmt.Sprintf("sign-in locked until %s after repeated failed attempts", lockedUntil.UTC().Format(time.RFC3339)),
+	})
+	if err != nil {
+		s.logger.Error("Failed to record account lock", "user_id", user.ID, "error", err)
+	}
+
+	lockedFor := formatTTL(time.Until(lockedUntil).Round(time.Minute))
+	if err := s.emailService.SendAccountLockedEmail(user.Email, user.FirstName, meta.IPAddress, lockedFor, s.frontendURL+"/forgot-password"); err != nil {
+		s.logger.Error("Failed to send account locked email", "user_id", user.ID, "error", err)
+	}
+}
+
+// LoginMFA completes a login started by Login for a user with 2FA enabled. Bad codes count as
+// failed sign-ins of the account, and a pending token only allows a few codes to be tried.
+func (s *AuthService) LoginMFA(ctx context.Context, req *models.LoginMFARequest, meta domain.SessionMeta) (*models.AuthResponse, error) {
+	claims, err := s.jwtManager.ValidateMFAPendingToken(req.MFAToken)
+	if err != nil || claims.ID == "" {
+		return nil, fmt.Errorf("invalid or expired mfa token")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired mfa token")
+	}
+
 	if !user.IsActive {
 		return nil, fmt.Errorf("account is deactivated")
 	}
 
-	return s.generateTokensWithRememberMe(ctx, user, req.RememberMe)
+	if err := s.loginThrottle.UseMFAToken(ctx, claims.ID, claims.IssuedAt.Time); err != nil {
+		return nil, err
+	}
+
+	if err := s.loginThrottle.Reserve(ctx, user.Email, meta.IPAddress); err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactor.VerifySecondFactor(ctx, user, req.Code); err != nil {
+		s.loginFailed(ctx, user, user.Email, meta)
+		return nil, err
+	}
+
+	s.loginThrottle.Succeeded(ctx, user.Email, meta.IPAddress)
+	s.loginThrottle.SpendMFAToken(ctx, claims.ID, claims.ExpiresAt.Time)
+
+	return s.generateTokens(ctx, user, claims.RememberMe, meta)
 }
 
-func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*models.AuthResponse, error) {
-	session, err := s.userRepo.GetSessionByToken(ctx, refreshToken)
+// RefreshToken rotates the session's refresh token and issues a new access token for it.
+// Each session is a token family: presenting a token the session has already rotated away
+// from revokes the session, since either the client or an attacker holds a copied token.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string, meta domain.SessionMeta) (*models.AuthResponse, error) {
+	oldHash := auth.HashToken(refreshToken)
+
+	session, err := s.userRepo.GetSessionByTokenHash(ctx, oldHash)
 	if err != nil {
-		return nil, fmt.Errorf("invalid or expired refresh token")
+		return nil, s.detectRefreshTokenReuse(ctx, oldHash, meta)
 	}
 
 	user, err := s.userRepo.GetByID(ctx, session.UserID)
@@ -114,60 +253,332 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*m
 		return nil, fmt.Errorf("user not found")
 	}
 
-	if err := s.userRepo.DeleteSession(ctx, refreshToken); err != nil {
-		return nil, err
+	newToken, err := s.jwtManager.GenerateRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	session.RefreshTokenHash = auth.HashToken(newToken)
+	// Each refresh extends the session by the lifetime it was opened with
+	session.ExpiresAt = time.Now().Add(sessionExpiry(session.RememberMe))
+	session.UserAgent = meta.UserAgent
+	session.IPAddress = meta.IPAddress
+
+	var rotated bool
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		rotated, err = s.userRepo.RotateSessionTx(ctx, tx, session, oldHash)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
 	}
+	if !rotated {
+		// Rotated by a concurrent refresh, or the session was revoked meanwhile
+		return nil, s.detectRefreshTokenReuse(ctx, oldHash, meta)
+	}
+
+	s.userRepo.CacheRotatedSession(session, oldHash)
 
-	return s.generateTokens(ctx, user)
+	return s.sessionResponse(user, session, newToken, false)
+}
+
+// detectRefreshTokenReuse returns the error for a refresh token that matches no session. When the
+// token was rotated before, its session is revoked and a security event is recorded.
+func (s *AuthService) detectRefreshTokenReuse(ctx context.Context, tokenHash string, meta domain.SessionMeta) error {
+	invalid := fmt.Errorf("invalid or expired refresh token")
+
+	rotated, err := s.userRepo.GetRotatedRefreshToken(ctx, tokenHash)
+	if err != nil {
+		s.logger.Error("Failed to look up rotated refresh token", "error", err)
+		return invalid
+	}
+	if rotated == nil {
+		return invalid
+	}
+
+	var sessions []domain.UserSession
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		sessions, err = s.userRepo.RevokeSessionTx(ctx, tx, rotated.UserID, rotated.SessionID)
+		if err != nil {
+			return err
+		}
+
+		return s.securityEvents.CreateTx(ctx, tx, &domain.SecurityEvent{
+			UserID:    &rotated.UserID,
+			Type:      domain.SecurityEventRefreshTokenReuse,
+			SessionID: &rotated.SessionID,
+			IPAddress: meta.IPAddress,
+			UserAgent: meta.UserAgent,
+			Details:   fmt.Sprintf("refresh token rotated at %s was presented again", rotated.RotatedAt.Format(time.RFC3339)),
+		})
+	})
+	if err != nil {
+		s.logger.Error("Failed to revoke session after refresh token reuse", "session_id", rotated.SessionID, "error", err)
+		return invalid
+	}
+
+	s.userRepo.ForgetSessions(sessions)
+	s.logger.Warn("Refresh token reuse detected, session revoked",
+		"user_id", rotated.UserID,
+		"session_id", rotated.SessionID,
+		"ip", meta.IPAddress,
+	)
+
+	return fmt.Errorf("refresh token has already been used, session revoked")
 }
 
 func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
-	return s.userRepo.DeleteSession(ctx, refreshToken)
+	var sessions []domain.UserSession
+	err := s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		sessions, err = s.userRepo.RevokeSessionByTokenHashTx(ctx, tx, auth.HashToken(refreshToken))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.userRepo.ForgetSessions(sessions)
+	return nil
 }
 
-func (s *AuthService) generateTokens(ctx context.Context, user *domain.User) (*models.AuthResponse, error) {
-	return s.generateTokensWithRememberMe(ctx, user, false)
+// VerifyEmail consumes an email verification token and marks its user verified
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	var updated domain.User
+	err := s.consumeToken(ctx, token, domain.UserTokenPurposeEmailVerification, func(tx *sqlx.Tx, user *domain.User) error {
+		updated = *user
+		return s.userRepo.MarkVerifiedTx(ctx, tx, &updated)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.userRepo.RefreshCache(&updated)
+	return nil
 }
 
-func (s *AuthService) generateTokensWithRememberMe(ctx context.Context, user *domain.User, rememberMe bool) (*models.AuthResponse, error) {
-	var accessToken string
-	var err error
+// ResendVerification sends a new verification link. Unknown and already verified addresses are
+// ignored so the endpoint does not reveal which emails are registered.
+func (s *AuthService) ResendVerification(ctx context.Context, emailAddr string) error {
+	user, err := s.userRepo.GetByEmail(ctx, emailAddr)
+	if err != nil || user.IsVerified || !user.IsActive {
+		return nil
+	}
 
-	if rememberMe {
-		// Extended access token for 30 days when remember me is enabled
-		accessToken, err = s.jwtManager.GenerateAccessTokenWithExpiry(user.ID, user.Email, string(user.Role), 30*24*time.Hour)
-	} else {
-		// Default access token expiry (configured in JWT manager)
-		accessToken, err = s.jwtManager.GenerateAccessToken(user.ID, user.Email, string(user.Role))
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// ForgotPassword sends a password reset link. Unknown addresses are ignored so the endpoint
+// does not reveal which emails are registered.
+func (s *AuthService) ForgotPassword(ctx context.Context, emailAddr string) error {
+	user, err := s.userRepo.GetByEmail(ctx, emailAddr)
+	if err != nil || !user.IsActive {
+		return nil
 	}
 
+	token, err := s.issueToken(ctx, user, domain.UserTokenPurposePasswordReset, s.resetTTL)
+	if err != nil || token == "" {
+		return err
+	}
+
+	link := s.frontendURL + "/reset-password?token=" + url.QueryEscape(token)
+	go func() {
+		if err := s.emailService.SendPasswordResetEmail(user.Email, user.FirstName, link, formatTTL(s.resetTTL)); err != nil {
+			s.logger.Error("Failed to send password reset email", "user_id", user.ID, "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// ResetPassword consumes a password reset token, sets the new password and signs the user out
+// of every session
+func (s *AuthService) ResetPassword(ctx context.Context, token, password string) error {
+	hashedPassword, err := auth.HashPassword(password, s.bcryptCost)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	refreshToken, err := s.jwtManager.GenerateRefreshToken()
+	var updated domain.User
+	var sessions []domain.UserSession
+	err = s.consumeToken(ctx, token, domain.UserTokenPurposePasswordReset, func(tx *sqlx.Tx, user *domain.User) error {
+		updated = *user
+		updated.PasswordHash = hashedPassword
+		if err := s.userRepo.UpdatePasswordTx(ctx, tx, &updated); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		var err error
+		sessions, err = s.userRepo.RevokeSessionsByUserTx(ctx, tx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+
+		// A reset link proves control of the mailbox as well
+		if !updated.IsVerified {
+			return s.userRepo.MarkVerifiedTx(ctx, tx, &updated)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+		return err
 	}
 
-	// Extend refresh token expiry when remember me is enabled
-	var sessionExpiry time.Duration
-	if rememberMe {
-		sessionExpiry = 30 * 24 * time.Hour // 30 days
-	} else {
-		sessionExpiry = 168 * time.Hour // 7 days (default)
+	s.userRepo.RefreshCache(&updated)
+	s.userRepo.ForgetSessions(sessions)
+	s.loginThrottle.Reset(ctx, updated.Email)
+	return nil
+}
+
+func (s *AuthService) sendVerificationEmail(ctx context.Context, user *domain.User) error {
+	token, err := s.issueToken(ctx, user, domain.UserTokenPurposeEmailVerification, s.verificationTTL)
+	if err != nil || token == "" {
+		return err
+	}
+
+	link := s.frontendURL + "/verify-email?token=" + url.QueryEscape(token)
+	go func() {
+		if err := s.emailService.SendVerificationEmail(user.Email, user.FirstName, link, formatTTL(s.verificationTTL)); err != nil {
+			s.logger.Error("Failed to send verification email", "user_id", user.ID, "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// issueToken replaces the user's outstanding tokens for purpose with a new one. It returns an
+// empty token without error while the previous one is still within tokenEmailCooldown.
+func (s *AuthService) issueToken(ctx context.Context, user *domain.User, purpose domain.UserTokenPurpose, ttl time.Duration) (string, error) {
+	last, err := s.tokenRepo.GetLatestCreatedAt(ctx, user.ID, purpose)
+	if err != nil {
+		return "", fmt.Errorf("failed to get latest token: %w", err)
+	}
+	if last != nil && time.Since(*last) < tokenEmailCooldown {
+		return "", nil
+	}
+
+	token, err := s.jwtManager.GenerateSignedToken(string(purpose))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := s.tokenRepo.RevokeUnusedTx(ctx, tx, user.ID, purpose); err != nil {
+			return err
+		}
+
+		return s.tokenRepo.CreateTx(ctx, tx, &domain.UserToken{
+			UserID:    user.ID,
+			Purpose:   purpose,
+			TokenHash: auth.HashToken(token),
+			ExpiresAt: time.Now().Add(ttl),
+		})
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+
+	return token, nil
+}
+
+// consumeToken locks an unused, unexpired token, marks it used and applies use to its user
+// in the same transaction
+func (s *AuthService) consumeToken(
+	ctx context.Context,
+	token string,
+	purpose domain.UserTokenPurpose,
+	use func(tx *sqlx.Tx, user *domain.User) error,
+) error {
+	if err := s.jwtManager.VerifySignedToken(token, string(purpose)); err != nil {
+		return fmt.Errorf("invalid or expired token")
+	}
+
+	return s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		t, err := s.tokenRepo.LockByHashTx(ctx, tx, auth.HashToken(token), purpose)
+		if err != nil {
+			return err
+		}
+
+		if t.UsedAt != nil || time.Now().After(t.ExpiresAt) {
+			return fmt.Errorf("invalid or expired token")
+		}
+
+		if err := s.tokenRepo.MarkUsedTx(ctx, tx, t); err != nil {
+			return fmt.Errorf("failed to use token: %w", err)
+		}
+
+		user, err := s.userRepo.GetByID(ctx, t.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		if !user.IsActive {
+			return fmt.Errorf("account is deactivated")
+		}
+
+		return use(tx, user)
+	})
+}
+
+// formatTTL renders a link lifetime for emails, e.g. "24 hours" or "30 minutes"
+func formatTTL(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		if d == time.Hour {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", d/time.Hour)
+	}
+	return fmt.Sprintf("%d minutes", d/time.Minute)
+}
+
+// generateTokens opens a session for a new login. Remember me extends the session to 30 days.
+func (s *AuthService) generateTokens(ctx context.Context, user *domain.User, rememberMe bool, meta domain.SessionMeta) (*models.AuthResponse, error) {
+	refreshToken, err := s.jwtManager.GenerateRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
 	session := &domain.UserSession{
-		UserID:       user.ID,
-		RefreshToken: refreshToken,
-		ExpiresAt:    time.Now().Add(sessionExpiry),
+		UserID:           user.ID,
+		RefreshTokenHash: auth.HashToken(refreshToken),
+		ExpiresAt:        time.Now().Add(sessionExpiry(rememberMe)),
+		UserAgent:        meta.UserAgent,
+		IPAddress:        meta.IPAddress,
+		RememberMe:       rememberMe,
 	}
 
 	if err := s.userRepo.CreateSession(ctx, session); err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
+	return s.sessionResponse(user, session, refreshToken, rememberMe)
+}
+
+// sessionExpiry returns how long a session lasts from login or its last refresh
+func sessionExpiry(rememberMe bool) time.Duration {
+	if rememberMe {
+		return rememberMeSessionExpiry
+	}
+	return defaultSessionExpiry
+}
+
+// sessionResponse issues an access token for session. Access tokens never outlive their
+// session, so a revocation only has to be remembered until the session would have expired.
+func (s *AuthService) sessionResponse(user *domain.User, session *domain.UserSession, refreshToken string, rememberMe bool) (*models.AuthResponse, error) {
+	expiry := s.jwtManager.AccessTokenExpiry()
+	if rememberMe {
+		// Extended access token for 30 days when remember me is enabled
+		expiry = rememberMeSessionExpiry
+	}
+	expiry = min(expiry, time.Until(session.ExpiresAt))
+
+	accessToken, err := s.jwtManager.GenerateAccessTokenWithExpiry(user.ID, session.ID, user.Email, string(user.Role), expiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
 	user.PasswordHash = ""
 
 	return &models.AuthResponse{
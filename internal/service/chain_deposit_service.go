@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/caspianex/exchange-backend/pkg/chain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+)
+
+// ChainDepositService books deposits observed on chain and credits them once they are confirmed
+type ChainDepositService struct {
+	db                 *database.Postgres
+	walletRepo         *repository.WalletRepository
+	txRepo             *repository.TransactionRepository
+	depositAddressRepo *repository.DepositAddressRepository
+	cursorRepo         *repository.ChainCursorRepository
+	ledgerService      *LedgerService
+}
+
+func NewChainDepositService(
+	db *database.Postgres,
+	walletRepo *repository.WalletRepository,
+	txRepo *repository.TransactionRepository,
+	depositAddressRepo *repository.DepositAddressRepository,
+	cursorRepo *repository.ChainCursorRepository,
+	ledgerService *LedgerService,
+) *ChainDepositService {
+	return &ChainDepositService{
+		db:                 db,
+		walletRepo:         walletRepo,
+		txRepo:             txRepo,
+		depositAddressRepo: depositAddressRepo,
+		cursorRepo:         cursorRepo,
+		ledgerService:      ledgerService,
+	}
+}
+
+func (s *ChainDepositService) GetWatchedAddresses(ctx context.Context, network string) ([]string, error) {
+	return s.depositAddressRepo.GetAllocatedAddresses(ctx, network)
+}
+
+func (s *ChainDepositService) GetCursor(ctx context.Context, network string) (int64, bool, error) {
+	return s.cursorRepo.Get(ctx, network)
+}
+
+func (s *ChainDepositService) SetCursor(ctx context.Context, network string, block int64) error {
+	return s.cursorRepo.Set(ctx, network, block)
+}
+
+func (s *ChainDepositService) GetPendingDeposits(ctx context.Context, network string) ([]domain.Transaction, error) {
+	return s.txRepo.GetPendingChainDeposits(ctx, network)
+}
+
+// RecordTransfer books a transfer to an allocated address as a pending deposit.
+// Returns nil when the address is not ours or the transfer was already booked.
+func (s *ChainDepositService) RecordTransfer(ctx context.Context, transfer chain.Transfer) (*domain.Transaction, error) {
+	address, err := s.depositAddressRepo.GetByAddress(ctx, transfer.Network, transfer.Address)
+	if err != nil {
+		// Failing the poll keeps the cursor before this block so the transfer is seen again
+		return nil, fmt.Errorf("failed to look up deposit address: %w", err)
+	}
+	if address == nil || address.UserID == nil {
+		return nil, nil
+	}
+
+	// Chains with more precision than the amount columns are truncated
+	amount := transfer.Amount.RoundDown(domain.AmountScale)
+	if !amount.IsPositive() {
+		return nil, nil
+	}
+
+	wallet, err := s.walletRepo.GetByUserAndCurrency(ctx, *address.UserID, address.CurrencyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find wallet: %w", err)
+	}
+
+	blockHeight := transfer.BlockHeight
+	tx := &domain.Transaction{
+		UserID:      wallet.UserID,
+		WalletID:    wallet.ID,
+		Type:        domain.TransactionTypeDeposit,
+		Amount:      amount,
+		Fee:         decimal.Zero,
+		Status:      domain.TransactionStatusPending,
+		TxHash:      transfer.TxHash,
+		OutputIndex: transfer.OutputIndex,
+		Address:     transfer.Address,
+		Network:     transfer.Network,
+		BlockHeight: &blockHeight,
+	}
+
+	created, err := s.txRepo.CreateChainDeposit(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	if !created {
+		return nil, nil
+	}
+
+	return tx, nil
+}
+
+// ConfirmDeposit stores the deposit's confirmation count and credits the wallet once it
+// reaches required. Returns true when this call credited the wallet.
+func (s *ChainDepositService) ConfirmDeposit(ctx context.Context, txID, confirmations, required int64) (bool, error) {
+	var wallet *domain.Wallet
+	credited := false
+	err := s.db.WithTx(ctx, func(sqlTx *sqlx.Tx) error {
+		tx, err := s.txRepo.LockByIDTx(ctx, sqlTx, txID)
+		if err != nil {
+			return err
+		}
+
+		if tx.Status != domain.TransactionStatusPending {
+			return nil
+		}
+
+		tx.Confirmations = confirmations
+		if confirmations < required {
+			return s.txRepo.UpdateConfirmationsTx(ctx, sqlTx, tx)
+		}
+
+		wallet, err = s.walletRepo.LockByIDTx(ctx, sqlTx, tx.WalletID)
+		if err != nil {
+			return err
+		}
+
+		wallet.Balance = wallet.Balance.Add(tx.Amount)
+		if err := s.walletRepo.UpdateBalanceTx(ctx, sqlTx, wallet); err != nil {
+			return fmt.Errorf("failed to update balance: %w", err)
+		}
+
+		if err := s.ledgerService.RecordDepositTx(ctx, sqlTx, wallet, tx); err != nil {
+			return fmt.Errorf("failed to record ledger entry: %w", err)
+		}
+
+		tx.Status = domain.TransactionStatusCompleted
+		if err := s.txRepo.UpdateConfirmationsTx(ctx, sqlTx, tx); err != nil {
+			return fmt.Errorf("failed to update transaction: %w", err)
+		}
+
+		credited = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if credited {
+		s.walletRepo.RefreshCache(wallet)
+	}
+
+	return credited, nil
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/models"
+	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/caspianex/exchange-backend/internal/testdb"
+	"github.com/caspianex/exchange-backend/pkg/auth"
+	"github.com/caspianex/exchange-backend/pkg/cache"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/caspianex/exchange-backend/pkg/email"
+	"github.com/caspianex/exchange-backend/pkg/logger"
+	"github.com/caspianex/exchange-backend/pkg/storage"
+	"github.com/jmoiron/sqlx"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/shopspring/decimal"
+)
+
+// moneyFixture wires the services that move money the way cmd/server does
+type moneyFixture struct {
+	db         *database.Postgres
+	userRepo   *repository.UserRepository
+	walletRepo *repository.WalletRepository
+	ledger     *LedgerService
+	wallets    *WalletService
+	exchanges  *CurrencyExchangeService
+}
+
+func newMoneyFixture(t *testing.T) *moneyFixture {
+	t.Helper()
+
+	db := testdb.Open(t)
+	log := logger.New("test")
+	cacheService := cache.NewCacheService(cache.NewMemoryCache(time.Hour, time.Hour), log)
+
+	blobStorage, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	// Nothing listens on port 1, so notification emails fail fast in the background
+	emailService := email.NewEmailService("127.0.0.1", 1, "", "", "test@example.com")
+
+	userRepo := repository.NewUserRepository(db, cacheService)
+	walletRepo := repository.NewWalletRepository(db, cacheService)
+	exchangeRepo := repository.NewCurrencyExchangeRepository(db, cacheService)
+	txRepo := repository.NewTransactionRepository(db)
+	depositAddressRepo := repository.NewDepositAddressRepository(db)
+
+	auditService := NewAuditService(repository.NewAuditLogRepository(db))
+	twoFactorService := NewTwoFactorService(db, userRepo, repository.NewRecoveryCodeRepository(db), "CaspianEx")
+	ledgerService := NewLedgerService(repository.NewLedgerRepository(db))
+	feeService := NewFeeService(db, repository.NewFeeRuleRepository(db), walletRepo, userRepo, auditService)
+	kycService := NewKYCService(db, repository.NewKYCRepository(db), userRepo, walletRepo, txRepo, exchangeRepo, blobStorage, auditService, 1<<20, log)
+
+	return &moneyFixture{
+		db:         db,
+		userRepo:   userRepo,
+		walletRepo: walletRepo,
+		ledger:     ledgerService,
+		wallets: NewWalletService(db, walletRepo, txRepo, depositAddressRepo, NewPoolAddressProvider(depositAddressRepo),
+			ledgerService, feeService, kycService, twoFactorService, auditService),
+		exchanges: NewCurrencyExchangeService(db, exchangeRepo, repository.NewExchangeQuoteRepository(db), walletRepo, userRepo,
+			ledgerService, feeService, kycService, emailService, time.Minute),
+	}
+}
+
+// createUser registers a client with a wallet per currency and two-factor authentication
+// enabled, and returns it with its TOTP secret
+func (f *moneyFixture) createUser(t *testing.T, email string) (*domain.User, string) {
+	t.Helper()
+	ctx := context.Background()
+
+	user := &domain.User{
+		Email:        email,
+		PasswordHash: "unused",
+		FirstName:    "Test",
+		LastName:     "User",
+		Role:         domain.UserRoleClient,
+		IsActive:     true,
+		IsVerified:   true,
+	}
+	if err := f.userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	currencies, err := f.walletRepo.GetAllCurrencies(ctx)
+	if err != nil {
+		t.Fatalf("GetAllCurrencies: %v", err)
+	}
+	for _, currency := range currencies {
+		wallet := &domain.Wallet{UserID: user.ID, CurrencyID: currency.ID, Balance: decimal.Zero, Locked: decimal.Zero}
+		if err := f.walletRepo.Create(ctx, wallet); err != nil {
+			t.Fatalf("create %s wallet: %v", currency.Code, err)
+		}
+	}
+
+	secret, _, err := auth.GenerateTOTPSecret("CaspianEx", email)
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret: %v", err)
+	}
+	user.TOTPSecret = &secret
+	if err := f.userRepo.SetTOTPSecret(ctx, user); err != nil {
+		t.Fatalf("SetTOTPSecret: %v", err)
+	}
+	// Enabled as of step 0 rather than with a code, so every current code is still unused
+	err = f.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		return f.userRepo.EnableTOTPTx(ctx, tx, user, 0)
+	})
+	if err != nil {
+		t.Fatalf("EnableTOTPTx: %v", err)
+	}
+	f.userRepo.RefreshCache(user)
+
+	return user, secret
+}
+
+// deposit credits the user's wallet as an administrator would
+func (f *moneyFixture) deposit(t *testing.T, userID int64, currencyCode, amount string) {
+	t.Helper()
+
+	_, err := f.wallets.ManualDeposit(context.Background(), domain.AuditMeta{}, &models.AdminDepositRequest{
+		UserID:       userID,
+		CurrencyCode: currencyCode,
+		Amount:       decimal.RequireFromString(amount),
+		Description:  "test deposit",
+	})
+	if err != nil {
+		t.Fatalf("deposit %s %s: %v", amount, currencyCode, err)
+	}
+}
+
+// wallet reads the wallet from the database, bypassing the cache
+func (f *moneyFixture) wallet(t *testing.T, userID int64, currencyCode string) *domain.Wallet {
+	t.Helper()
+	ctx := context.Background()
+
+	currency, err := f.walletRepo.GetCurrencyByCode(ctx, currencyCode)
+	if err != nil {
+		t.Fatalf("GetCurrencyByCode(%s): %v", currencyCode, err)
+	}
+
+	var wallet domain.Wallet
+	if err := f.db.GetContext(ctx, &wallet, queries.WalletGetByUserAndCurrencyQuery, userID, currency.ID); err != nil {
+		t.Fatalf("read %s wallet: %v", currencyCode, err)
+	}
+
+	return &wallet
+}
+
+// totpCode returns the code for the time step offset steps from now
+func totpCode(secret string, offset int) (string, error) {
+	return totp.GenerateCodeCustom(secret, time.Now().Add(time.Duration(offset)*30*time.Second), totp.ValidateOpts{
+		Period:    30,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+}
+
+// TestConcurrentExchangesAndWithdrawals races exchanges in both directions of a pair and
+// withdrawals on the same wallets. More is asked of the USDT wallet than it can cover, so
+// some requests must be turned away; the rest must add up exactly, in the wallets and in
+// the ledger.
+func TestConcurrentExchangesAndWithdrawals(t *testing.T) {
+	f := newMoneyFixture(t)
+	ctx := context.Background()
+
+	user, secret := f.createUser(t, "concurrency@example.com")
+	f.deposit(t, user.ID, "USDT", "1000")
+	f.deposit(t, user.ID, "KZT", "90000")
+
+	const (
+		sells = 40 // of 30 USDT each, 1200 USDT against the 1000 deposited
+		buys  = 20 // of 4500 KZT each, all of the KZT deposited
+	)
+
+	var (
+		mu          sync.Mutex
+		completed   []*domain.CurrencyExchange
+		withdrawals []*domain.Transaction
+		rejected    int
+		failures    []error
+	)
+	record := func(err error) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case err == nil:
+			return true
+		case errors.Is(err, ErrInsufficientBalance):
+			rejected++
+		default:
+			failures = append(failures, err)
+		}
+		return false
+	}
+
+	var wg sync.WaitGroup
+	exchange := func(from, to, amount string) {
+		defer wg.Done()
+		ex, err := f.exchanges.CreateExchange(ctx, user.ID, &models.CreateExchangeRequest{
+			FromCurrencyCode: from,
+			ToCurrencyCode:   to,
+			FromAmount:       decimal.RequireFromString(amount),
+		})
+		if record(err) {
+			mu.Lock()
+			completed = append(completed, ex)
+			mu.Unlock()
+		}
+	}
+
+	for i := 0; i < sells; i++ {
+		wg.Add(1)
+		go exchange("USDT", "KZT", "30")
+	}
+	for i := 0; i < buys; i++ {
+		wg.Add(1)
+		go exchange("KZT", "USDT", "4500")
+	}
+
+	// A TOTP step is accepted once, so the withdrawals use the current step and the next one
+	// in turn while the exchanges run
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for offset := 0; offset <= 1; offset++ {
+			code, err := totpCode(secret, offset)
+			if err != nil {
+				record(err)
+				continue
+			}
+			tx, err := f.wallets.Withdraw(ctx, user.ID, &models.WithdrawRequest{
+				CurrencyCode: "USDT",
+				Amount:       decimal.RequireFromString("100"),
+				Address:      "TXYZ1234567890",
+				TOTPCode:     code,
+			})
+			if record(err) {
+				mu.Lock()
+				withdrawals = append(withdrawals, tx)
+				mu.Unlock()
+			}
+		}
+	}()
+
+	wg.Wait()
+
+	for _, err := range failures {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if rejected == 0 {
+		t.Fatal("no request was rejected for insufficient balance, so the wallets were never contended")
+	}
+
+	usdt := f.wallet(t, user.ID, "USDT")
+	kzt := f.wallet(t, user.ID, "KZT")
+
+	wantUSDT := decimal.RequireFromString("1000")
+	wantKZT := decimal.RequireFromString("90000")
+	wantLocked := decimal.Zero
+	for _, ex := range completed {
+		if ex.FromCurrencyID == usdt.CurrencyID {
+			wantUSDT = wantUSDT.Sub(ex.FromAmount)
+			wantKZT = wantKZT.Add(ex.ToAmountWithFee)
+		} else {
+			wantKZT = wantKZT.Sub(ex.FromAmount)
+			wantUSDT = wantUSDT.Add(ex.ToAmountWithFee)
+		}
+	}
+	for _, tx := range withdrawals {
+		wantUSDT = wantUSDT.Sub(tx.HeldAmount())
+		wantLocked = wantLocked.Add(tx.HeldAmount())
+	}
+
+	if !usdt.Balance.Equal(wantUSDT) || !usdt.Locked.Equal(wantLocked) {
+		t.Errorf("USDT wallet = %s balance, %s locked; want %s, %s", usdt.Balance, usdt.Locked, wantUSDT, wantLocked)
+	}
+	if !kzt.Balance.Equal(wantKZT) || !kzt.Locked.IsZero() {
+		t.Errorf("KZT wallet = %s balance, %s locked; want %s, 0", kzt.Balance, kzt.Locked, wantKZT)
+	}
+	if usdt.Balance.IsNegative() || kzt.Balance.IsNegative() {
+		t.Errorf("negative balance: USDT %s, KZT %s", usdt.Balance, kzt.Balance)
+	}
+
+	var exchangeRows int
+	if err := f.db.GetContext(ctx, &exchangeRows, `SELECT COUNT(*) FROM currency_exchanges WHERE user_id = $1`, user.ID); err != nil {
+		t.Fatalf("count exchanges: %v", err)
+	}
+	if exchangeRows != len(completed) {
+		t.Errorf("%d exchanges stored, want the %d that succeeded", exchangeRows, len(completed))
+	}
+
+	mismatches, err := f.ledger.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	for _, m := range mismatches {
+		t.Errorf("wallet differs from the ledger: %+v", m)
+	}
+
+	// The postings must also add up to the balances the exchanges and withdrawals predict
+	for _, wallet := range []*domain.Wallet{usdt, kzt} {
+		for account, want := range map[string]decimal.Decimal{
+			domain.WalletLedgerAccount(wallet.ID):       wallet.Balance,
+			domain.WalletLockedLedgerAccount(wallet.ID): wallet.Locked,
+		} {
+			got, err := f.ledger.ledgerRepo.GetAccountBalance(ctx, account)
+			if err != nil {
+				t.Fatalf("GetAccountBalance(%s): %v", account, err)
+			}
+			if !got.Equal(want) {
+				t.Errorf("ledger account %s = %s, want %s", account, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package service
+
+import "fmt"
+
+// Error codes returned to clients alongside the message, so they can react without parsing text
+const (
+	ErrCodeInsufficientBalance = "insufficient_balance"
+	ErrCodeAmountBelowMinimum  = "amount_below_minimum"
+	ErrCodeAmountAboveMaximum  = "amount_above_maximum"
+	ErrCodeAmountPrecision     = "amount_precision_exceeded"
+	ErrCodeDailyLimitExceeded  = "daily_limit_exceeded"
+	ErrCodeKYCLimitExceeded    = "kyc_limit_exceeded"
+	ErrCodeTOTPRequired        = "totp_required"
+	ErrCodeInvalidTOTP         = "invalid_totp_code"
+	ErrCodeLoginThrottled      = "too_many_login_attempts"
+)
+
+// ServiceError is an error with a stable, machine-readable code
+type ServiceError struct {
+	Code    string
+	Message string
+}
+
+func (e *ServiceError) Error() string {
+	return e.Message
+}
+
+func newServiceError(code, format string, args ...interface{}) *ServiceError {
+	return &ServiceError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// ErrInsufficientBalance is returned when a wallet cannot cover an exchange or withdrawal
+var ErrInsufficientBalance = &ServiceError{Code: ErrCodeInsufficientBalance, Message: "insufficient balance"}
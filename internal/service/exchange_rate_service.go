@@ -7,18 +7,30 @@ import (
 	"github.com/caspianex/exchange-backend/internal/domain"
 	"github.com/caspianex/exchange-backend/internal/models"
 	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/caspianex/exchange-backend/pkg/database"
 	"github.com/caspianex/exchange-backend/pkg/logger"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
 )
 
 type ExchangeRatesService struct {
-	ratesRepo *repository.ExchangeRateRepository
-	log       *logger.Logger
+	db           *database.Postgres
+	ratesRepo    *repository.ExchangeRateRepository
+	auditService *AuditService
+	log          *logger.Logger
 }
 
-func NewExchangeRatesService(ratesRepo *repository.ExchangeRateRepository, log *logger.Logger) *ExchangeRatesService {
+func NewExchangeRatesService(
+	db *database.Postgres,
+	ratesRepo *repository.ExchangeRateRepository,
+	auditService *AuditService,
+	log *logger.Logger,
+) *ExchangeRatesService {
 	return &ExchangeRatesService{
-		ratesRepo: ratesRepo,
-		log:       log,
+		db:           db,
+		ratesRepo:    ratesRepo,
+		auditService: auditService,
+		log:          log,
 	}
 }
 
@@ -40,7 +52,7 @@ func (s *ExchangeRatesService) GetRateByID(ctx context.Context, id int64) (*doma
 	return s.ratesRepo.GetByID(ctx, id)
 }
 
-func (s *ExchangeRatesService) CreateRate(ctx context.Context, req *models.CreateExchangeRatesRequest) (*domain.ExchangeRate, error) {
+func (s *ExchangeRatesService) CreateRate(ctx context.Context, meta domain.AuditMeta, req *models.CreateExchangeRatesRequest) (*domain.ExchangeRate, error) {
 	if req.FromCurrencyID == req.ToCurrencyID {
 		return nil, fmt.Errorf("base and quote currencies must be different")
 	}
@@ -53,31 +65,107 @@ func (s *ExchangeRatesService) CreateRate(ctx context.Context, req *models.Creat
 		IsActive:       req.IsActive,
 	}
 
-	if err := s.ratesRepo.Create(ctx, rate); err != nil {
-		return nil, fmt.Errorf("failed to create exchange rate: %w", err)
+	if err := applyPairLimits(rate, &req.PairLimits); err != nil {
+		return nil, err
 	}
 
+	err := s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := s.ratesRepo.CreateTx(ctx, tx, rate); err != nil {
+			return fmt.Errorf("failed to create exchange rate: %w", err)
+		}
+
+		return s.auditService.RecordTx(ctx, tx, meta, domain.AuditActionExchangeRateCreate, domain.AuditTargetExchangeRate, rate.ID, nil, rate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.ratesRepo.RefreshCache(rate)
+
 	return rate, nil
 }
 
-func (s *ExchangeRatesService) UpdateRate(ctx context.Context, id int64, req *models.UpdateExchangeRatesRequest) (*domain.ExchangeRate, error) {
+func (s *ExchangeRatesService) UpdateRate(ctx context.Context, meta domain.AuditMeta, id int64, req *models.UpdateExchangeRatesRequest) (*domain.ExchangeRate, error) {
 	rate, err := s.ratesRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	rate.Fee = req.Fee
-	rate.IsActive = req.IsActive
+	updated := *rate
+	updated.Fee = req.Fee
+	updated.IsActive = req.IsActive
 
-	if err := s.ratesRepo.Update(ctx, rate); err != nil {
-		return nil, fmt.Errorf("failed to update exchange rate: %w", err)
+	if err := applyPairLimits(&updated, &req.PairLimits); err != nil {
+		return nil, err
 	}
 
-	return rate, nil
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := s.ratesRepo.UpdateTx(ctx, tx, &updated); err != nil {
+			return fmt.Errorf("failed to update exchange rate: %w", err)
+		}
+
+		return s.auditService.RecordTx(ctx, tx, meta, domain.AuditActionExchangeRateUpdate, domain.AuditTargetExchangeRate, id, rate, &updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.ratesRepo.RefreshCache(&updated)
+
+	return &updated, nil
+}
+
+func (s *ExchangeRatesService) DeleteRate(ctx context.Context, meta domain.AuditMeta, id int64) error {
+	rate, err := s.ratesRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := s.ratesRepo.DeleteTx(ctx, tx, id); err != nil {
+			return err
+		}
+
+		return s.auditService.RecordTx(ctx, tx, meta, domain.AuditActionExchangeRateDelete, domain.AuditTargetExchangeRate, id, rate, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.ratesRepo.InvalidateCache(rate)
+
+	return nil
 }
 
-func (s *ExchangeRatesService) DeleteRate(ctx context.Context, id int64) error {
-	return s.ratesRepo.Delete(ctx, id)
+// applyPairLimits validates the limits and sets them on rate
+func applyPairLimits(rate *domain.ExchangeRate, limits *models.PairLimits) error {
+	for name, amount := range map[string]*decimal.Decimal{
+		"min_amount":  limits.MinAmount,
+		"max_amount":  limits.MaxAmount,
+		"daily_limit": limits.DailyLimit,
+	} {
+		if amount != nil && !amount.IsPositive() {
+			return fmt.Errorf("%s must be positive", name)
+		}
+	}
+
+	if limits.MinAmount != nil && limits.MaxAmount != nil && limits.MinAmount.GreaterThan(*limits.MaxAmount) {
+		return fmt.Errorf("min_amount cannot exceed max_amount")
+	}
+
+	if limits.AmountPrecision != nil && (*limits.AmountPrecision < 0 || *limits.AmountPrecision > domain.AmountScale) {
+		return fmt.Errorf("amount_precision must be between 0 and %d", domain.AmountScale)
+	}
+
+	if limits.MinAmount != nil && limits.DailyLimit != nil && limits.MinAmount.GreaterThan(*limits.DailyLimit) {
+		return fmt.Errorf("min_amount cannot exceed daily_limit")
+	}
+
+	rate.MinAmount = limits.MinAmount
+	rate.MaxAmount = limits.MaxAmount
+	rate.DailyLimit = limits.DailyLimit
+	rate.AmountPrecision = limits.AmountPrecision
+	return nil
 }
 
 // BatchUpdateRates updates multiple exchange rates in a single database transaction
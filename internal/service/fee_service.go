@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/models"
+	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+)
+
+// AppliedFee is a fee priced by the engine. Rule is nil when no rule matched.
+type AppliedFee struct {
+	Amount decimal.Decimal
+	Rule   *domain.FeeRule
+}
+
+// RuleID returns the applied rule's ID for storing alongside the transaction or exchange
+func (f *AppliedFee) RuleID() *int64 {
+	if f.Rule == nil {
+		return nil
+	}
+	id := f.Rule.ID
+	return &id
+}
+
+type FeeService struct {
+	db           *database.Postgres
+	feeRepo      *repository.FeeRuleRepository
+	walletRepo   *repository.WalletRepository
+	userRepo     *repository.UserRepository
+	auditService *AuditService
+}
+
+func NewFeeService(
+	db *database.Postgres,
+	feeRepo *repository.FeeRuleRepository,
+	walletRepo *repository.WalletRepository,
+	userRepo *repository.UserRepository,
+	auditService *AuditService,
+) *FeeService {
+	return &FeeService{
+		db:           db,
+		feeRepo:      feeRepo,
+		walletRepo:   walletRepo,
+		userRepo:     userRepo,
+		auditService: auditService,
+	}
+}
+
+// WithdrawalFee prices a withdrawal in the withdrawn currency. Without a matching rule it is free.
+func (s *FeeService) WithdrawalFee(ctx context.Context, userID int64, currency *domain.Currency, amount decimal.Decimal) (*AppliedFee, error) {
+	tier, err := s.userTier(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := s.match(ctx, &domain.FeeContext{
+		Operation:  domain.FeeOperationWithdrawal,
+		CurrencyID: currency.ID,
+		Network:    currency.Network,
+		UserTier:   tier,
+		Amount:     amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if rule == nil {
+		return &AppliedFee{Amount: decimal.Zero}, nil
+	}
+
+	return &AppliedFee{
+		Amount: currency.RoundUp(rule.Calculate(amount, amount)),
+		Rule:   rule,
+	}, nil
+}
+
+// ExchangeFee prices an exchange in the to-currency. Without a matching rule the pair's own
+// percentage applies. The fee never exceeds toAmount.
+func (s *FeeService) ExchangeFee(
+	ctx context.Context,
+	userID int64,
+	fromCurrency, toCurrency *domain.Currency,
+	fromAmount, toAmount, defaultPercent decimal.Decimal,
+) (*AppliedFee, error) {
+	tier, err := s.userTier(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := s.match(ctx, &domain.FeeContext{
+		Operation:      domain.FeeOperationExchange,
+		FromCurrencyID: fromCurrency.ID,
+		ToCurrencyID:   toCurrency.ID,
+		UserTier:       tier,
+		Amount:         fromAmount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var fee decimal.Decimal
+	if rule != nil {
+		fee = rule.Calculate(toAmount, fromAmount)
+	} else {
+		fee = toAmount.Mul(defaultPercent).Div(hundred)
+	}
+
+	return &AppliedFee{
+		Amount: decimal.Min(toCurrency.RoundUp(fee), toAmount),
+		Rule:   rule,
+	}, nil
+}
+
+// match returns the winning active rule for fc, or nil when none matches
+func (s *FeeService) match(ctx context.Context, fc *domain.FeeContext) (*domain.FeeRule, error) {
+	rules, err := s.feeRepo.GetActiveByOperation(ctx, fc.Operation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load fee rules: %w", err)
+	}
+
+	var best *domain.FeeRule
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Matches(fc) {
+			continue
+		}
+		if best == nil || outranks(rule, best) {
+			best = rule
+		}
+	}
+
+	return best, nil
+}
+
+// outranks orders matching rules by priority, then specificity, then age
+func outranks(a, b *domain.FeeRule) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if sa, sb := a.Specificity(), b.Specificity(); sa != sb {
+		return sa > sb
+	}
+	return a.ID < b.ID
+}
+
+func (s *FeeService) userTier(ctx context.Context, userID int64) (string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.FeeTier == "" {
+		return domain.DefaultFeeTier, nil
+	}
+	return user.FeeTier, nil
+}
+
+func (s *FeeService) ListRules(ctx context.Context, operation string, activeOnly bool, limit, offset int) ([]domain.FeeRule, int64, error) {
+	rules, err := s.feeRepo.List(ctx, operation, activeOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.feeRepo.Count(ctx, operation, activeOnly)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return rules, total, nil
+}
+
+func (s *FeeService) GetRule(ctx context.Context, id int64) (*domain.FeeRule, error) {
+	return s.feeRepo.GetByID(ctx, id)
+}
+
+func (s *FeeService) CreateRule(ctx context.Context, meta domain.AuditMeta, req *models.FeeRuleRequest) (*domain.FeeRule, error) {
+	rule, err := s.buildRule(ctx, meta.ActorID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := s.feeRepo.CreateTx(ctx, tx, rule); err != nil {
+			return fmt.Errorf("failed to create fee rule: %w", err)
+		}
+
+		return s.auditService.RecordTx(ctx, tx, meta, domain.AuditActionFeeRuleCreate, domain.AuditTargetFeeRule, rule.ID, nil, rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// UpdateRule retires the rule and creates its replacement, so transactions keep pointing at
+// the rule as it was when they were charged
+func (s *FeeService) UpdateRule(ctx context.Context, meta domain.AuditMeta, id int64, req *models.FeeRuleRequest) (*domain.FeeRule, error) {
+	rule, err := s.buildRule(ctx, meta.ActorID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		old, err := s.feeRepo.LockByIDTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if !old.IsActive {
+			return fmt.Errorf("fee rule is no longer active")
+		}
+
+		if err := s.feeRepo.CreateTx(ctx, tx, rule); err != nil {
+			return fmt.Errorf("failed to create fee rule: %w", err)
+		}
+
+		before := *old
+		if err := s.feeRepo.RetireTx(ctx, tx, old, &rule.ID); err != nil {
+			return err
+		}
+
+		return s.auditService.RecordTx(ctx, tx, meta, domain.AuditActionFeeRuleUpdate, domain.AuditTargetFeeRule, id, &before, rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// DeleteRule retires the rule. It stays readable for the transactions that reference it.
+func (s *FeeService) DeleteRule(ctx context.Context, meta domain.AuditMeta, id int64) error {
+	return s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		rule, err := s.feeRepo.LockByIDTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if !rule.IsActive {
+			return fmt.Errorf("fee rule is no longer active")
+		}
+
+		before := *rule
+		if err := s.feeRepo.RetireTx(ctx, tx, rule, nil); err != nil {
+			return err
+		}
+
+		return s.auditService.RecordTx(ctx, tx, meta, domain.AuditActionFeeRuleDelete, domain.AuditTargetFeeRule, id, &before, rule)
+	})
+}
+
+// buildRule validates the request and resolves its currency codes
+func (s *FeeService) buildRule(ctx context.Context, adminID int64, req *models.FeeRuleRequest) (*domain.FeeRule, error) {
+	rule := &domain.FeeRule{
+		Name:       req.Name,
+		Operation:  domain.FeeOperation(req.Operation),
+		MinAmount:  req.MinAmount,
+		MaxAmount:  req.MaxAmount,
+		Type:       domain.FeeRuleType(req.Type),
+		Percent:    req.Percent,
+		FlatAmount: req.FlatAmount,
+		MinFee:     req.MinFee,
+		MaxFee:     req.MaxFee,
+		Tiers:      req.Tiers,
+		Priority:   req.Priority,
+		CreatedBy:  &adminID,
+	}
+
+	if req.Network != "" {
+		rule.Network = &req.Network
+	}
+	if req.UserTier != "" {
+		rule.UserTier = &req.UserTier
+	}
+
+	var err error
+	switch rule.Operation {
+	case domain.FeeOperationWithdrawal:
+		if req.FromCurrencyCode != "" || req.ToCurrencyCode != "" {
+			return nil, fmt.Errorf("withdrawal rules cannot match on an exchange pair")
+		}
+		if rule.CurrencyID, err = s.currencyID(ctx, req.CurrencyCode); err != nil {
+			return nil, err
+		}
+	case domain.FeeOperationExchange:
+		if req.CurrencyCode != "" || req.Network != "" {
+			return nil, fmt.Errorf("exchange rules match on from_currency_code and to_currency_code")
+		}
+		if rule.FromCurrencyID, err = s.currencyID(ctx, req.FromCurrencyCode); err != nil {
+			return nil, err
+		}
+		if rule.ToCurrencyID, err = s.currencyID(ctx, req.ToCurrencyCode); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateFeeRule(rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *FeeService) currencyID(ctx context.Context, code string) (*int32, error) {
+	if code == "" {
+		return nil, nil
+	}
+
+	currency, err := s.walletRepo.GetCurrencyByCode(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("currency %s not found", code)
+	}
+
+	return &currency.ID, nil
+}
+
+func validateFeeRule(rule *domain.FeeRule) error {
+	if rule.MinAmount != nil && rule.MinAmount.IsNegative() {
+		return fmt.Errorf("min_amount cannot be negative")
+	}
+	if rule.MinAmount != nil && rule.MaxAmount != nil && rule.MinAmount.GreaterThan(*rule.MaxAmount) {
+		return fmt.Errorf("min_amount cannot exceed max_amount")
+	}
+	if rule.MinFee != nil && rule.MinFee.IsNegative() {
+		return fmt.Errorf("min_fee cannot be negative")
+	}
+	if rule.MinFee != nil && rule.MaxFee != nil && rule.MinFee.GreaterThan(*rule.MaxFee) {
+		return fmt.Errorf("min_fee cannot exceed max_fee")
+	}
+
+	switch rule.Type {
+	case domain.FeeRuleTypePercent:
+		if err := validateFeePercent(rule.Percent); err != nil {
+			return err
+		}
+		rule.FlatAmount = decimal.Zero
+		rule.Tiers = nil
+	case domain.FeeRuleTypeFlat:
+		if rule.FlatAmount.IsNegative() {
+			return fmt.Errorf("flat_amount cannot be negative")
+		}
+		rule.Percent = decimal.Zero
+		rule.Tiers = nil
+	case domain.FeeRuleTypeTiered:
+		if len(rule.Tiers) == 0 {
+			return fmt.Errorf("tiered rules need at least one tier")
+		}
+		for i, tier := range rule.Tiers {
+			if err := validateFeePercent(tier.Percent); err != nil {
+				return fmt.Errorf("tier %d: %w", i+1, err)
+			}
+			if tier.Flat.IsNegative() {
+				return fmt.Errorf("tier %d: flat cannot be negative", i+1)
+			}
+			if tier.UpTo == nil {
+				if i != len(rule.Tiers)-1 {
+					return fmt.Errorf("tier %d: only the last tier can be open-ended", i+1)
+				}
+				continue
+			}
+			if i > 0 && !tier.UpTo.GreaterThan(*rule.Tiers[i-1].UpTo) {
+				return fmt.Errorf("tier %d: up_to must be greater than the previous tier", i+1)
+			}
+		}
+		rule.Percent = decimal.Zero
+		rule.FlatAmount = decimal.Zero
+	}
+
+	// Absolute amounts mean something only in one currency: 5 would be 5 BTC on one
+	// withdrawal and 5 KZT on the next
+	hasFeeAmounts := !rule.FlatAmount.IsZero() || rule.MinFee != nil || rule.MaxFee != nil
+	hasBands := rule.MinAmount != nil || rule.MaxAmount != nil
+	for _, tier := range rule.Tiers {
+		hasFeeAmounts = hasFeeAmounts || !tier.Flat.IsZero()
+		hasBands = hasBands || tier.UpTo != nil
+	}
+
+	chargedField, bandField := "currency_code", "currency_code"
+	if rule.Operation == domain.FeeOperationExchange {
+		chargedField, bandField = "to_currency_code", "from_currency_code"
+	}
+	if hasFeeAmounts && rule.ChargedCurrencyID() == nil {
+		return fmt.Errorf("flat amounts, min_fee and max_fee are in the charged currency, so %s is required", chargedField)
+	}
+	if hasBands && rule.BandCurrencyID() == nil {
+		return fmt.Errorf("min_amount, max_amount and tier limits are in the matched amount's currency, so %s is required", bandField)
+	}
+
+	return nil
+}
+
+func validateFeePercent(percent decimal.Decimal) error {
+	if percent.IsNegative() || percent.GreaterThanOrEqual(hundred) {
+		return fmt.Errorf("percent must be between 0 and 100")
+	}
+	return nil
+}
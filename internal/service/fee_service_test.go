@@ -0,0 +1,94 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+func TestValidateFeeRuleRequiresCurrencyForAbsoluteAmounts(t *testing.T) {
+	usdt, kzt := int32(3), int32(9)
+	five := decimal.NewFromInt(5)
+	thousand := decimal.NewFromInt(1000)
+
+	tests := []struct {
+		name    string
+		rule    domain.FeeRule
+		wantErr bool
+	}{
+		{
+			name: "catch-all percent withdrawal",
+			rule: domain.FeeRule{Operation: domain.FeeOperationWithdrawal, Type: domain.FeeRuleTypePercent, Percent: decimal.RequireFromString("0.1")},
+		},
+		{
+			name:    "catch-all flat withdrawal",
+			rule:    domain.FeeRule{Operation: domain.FeeOperationWithdrawal, Type: domain.FeeRuleTypeFlat, FlatAmount: five},
+			wantErr: true,
+		},
+		{
+			name: "flat withdrawal of one currency",
+			rule: domain.FeeRule{Operation: domain.FeeOperationWithdrawal, CurrencyID: &usdt, Type: domain.FeeRuleTypeFlat, FlatAmount: five},
+		},
+		{
+			name:    "catch-all percent withdrawal with min_fee",
+			rule:    domain.FeeRule{Operation: domain.FeeOperationWithdrawal, Type: domain.FeeRuleTypePercent, Percent: decimal.NewFromInt(1), MinFee: &five},
+			wantErr: true,
+		},
+		{
+			name:    "catch-all percent withdrawal with max_fee",
+			rule:    domain.FeeRule{Operation: domain.FeeOperationWithdrawal, Type: domain.FeeRuleTypePercent, Percent: decimal.NewFromInt(1), MaxFee: &five},
+			wantErr: true,
+		},
+		{
+			name:    "catch-all withdrawal with an amount band",
+			rule:    domain.FeeRule{Operation: domain.FeeOperationWithdrawal, MinAmount: &thousand, Type: domain.FeeRuleTypePercent, Percent: decimal.NewFromInt(1)},
+			wantErr: true,
+		},
+		{
+			name: "exchange min_fee in the to-currency",
+			rule: domain.FeeRule{Operation: domain.FeeOperationExchange, ToCurrencyID: &kzt, Type: domain.FeeRuleTypePercent, Percent: decimal.NewFromInt(1), MinFee: &five},
+		},
+		{
+			name:    "exchange min_fee with only the from-currency set",
+			rule:    domain.FeeRule{Operation: domain.FeeOperationExchange, FromCurrencyID: &usdt, Type: domain.FeeRuleTypePercent, Percent: decimal.NewFromInt(1), MinFee: &five},
+			wantErr: true,
+		},
+		{
+			name: "exchange tiers by from-amount with percent only",
+			rule: domain.FeeRule{Operation: domain.FeeOperationExchange, FromCurrencyID: &usdt, Type: domain.FeeRuleTypeTiered, Tiers: domain.FeeTiers{
+				{UpTo: &thousand, Percent: decimal.NewFromInt(2)},
+				{Percent: decimal.NewFromInt(1)},
+			}},
+		},
+		{
+			name: "exchange tiers without the from-currency",
+			rule: domain.FeeRule{Operation: domain.FeeOperationExchange, ToCurrencyID: &kzt, Type: domain.FeeRuleTypeTiered, Tiers: domain.FeeTiers{
+				{UpTo: &thousand, Percent: decimal.NewFromInt(2)},
+				{Percent: decimal.NewFromInt(1)},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "exchange tier flat without the to-currency",
+			rule: domain.FeeRule{Operation: domain.FeeOperationExchange, FromCurrencyID: &usdt, Type: domain.FeeRuleTypeTiered, Tiers: domain.FeeTiers{
+				{Percent: decimal.NewFromInt(1), Flat: five},
+			}},
+			wantErr: true,
+		},
+		{
+			// validateFeeRule clears the fields the rule type does not use before checking
+			name: "percent rule with a leftover flat_amount",
+			rule: domain.FeeRule{Operation: domain.FeeOperationWithdrawal, Type: domain.FeeRuleTypePercent, Percent: decimal.NewFromInt(1), FlatAmount: five},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFeeRule(&tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateFeeRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,508 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/models"
+	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/caspianex/exchange-backend/pkg/logger"
+	"github.com/caspianex/exchange-backend/pkg/storage"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+)
+
+// kycContentTypes are the sniffed file types accepted as KYC documents
+var kycContentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"application/pdf": true,
+}
+
+// KYCService handles document upload, applications for a higher KYC level, their review,
+// and the daily limits tied to each level
+type KYCService struct {
+	db              *database.Postgres
+	kycRepo         *repository.KYCRepository
+	userRepo        *repository.UserRepository
+	walletRepo      *repository.WalletRepository
+	txRepo          *repository.TransactionRepository
+	exchangeRepo    *repository.CurrencyExchangeRepository
+	storage         storage.BlobStorage
+	auditService    *AuditService
+	maxDocumentSize int64
+	log             *logger.Logger
+}
+
+func NewKYCService(
+	db *database.Postgres,
+	kycRepo *repository.KYCRepository,
+	userRepo *repository.UserRepository,
+	walletRepo *repository.WalletRepository,
+	txRepo *repository.TransactionRepository,
+	exchangeRepo *repository.CurrencyExchangeRepository,
+	storage storage.BlobStorage,
+	auditService *AuditService,
+	maxDocumentSize int64,
+	log *logger.Logger,
+) *KYCService {
+	return &KYCService{
+		db:              db,
+		kycRepo:         kycRepo,
+		userRepo:        userRepo,
+		walletRepo:      walletRepo,
+		txRepo:          txRepo,
+		exchangeRepo:    exchangeRepo,
+		storage:         storage,
+		auditService:    auditService,
+		maxDocumentSize: maxDocumentSize,
+		log:             log,
+	}
+}
+
+// MaxDocumentSize is the largest accepted upload in bytes
+func (s *KYCService) MaxDocumentSize() int64 {
+	return s.maxDocumentSize
+}
+
+// GetStatus returns the user's level, latest application, uploads not yet submitted and current limits
+func (s *KYCService) GetStatus(ctx context.Context, userID int64) (*domain.KYCStatus, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	app, err := s.kycRepo.GetLatestApplication(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if app != nil {
+		if app.Documents, err = s.kycRepo.GetApplicationDocuments(ctx, app.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	docs, err := s.kycRepo.GetUnsubmittedDocuments(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := s.kycRepo.GetLimitsByLevel(ctx, user.KYCLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.KYCStatus{
+		Level:             user.KYCLevel,
+		LatestApplication: app,
+		PendingDocuments:  docs,
+		Limits:            limits,
+	}, nil
+}
+
+// UploadDocument stores a file for the user's next application. The type is taken from the
+// file contents, not the client's Content-Type.
+func (s *KYCService) UploadDocument(
+	ctx context.Context,
+	userID int64,
+	docType domain.KYCDocumentType,
+	fileName string,
+	size int64,
+	r io.Reader,
+) (*domain.KYCDocument, error) {
+	if !isKYCDocumentType(docType) {
+		return nil, fmt.Errorf("unknown document type %s", docType)
+	}
+
+	if size <= 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if size > s.maxDocumentSize {
+		return nil, fmt.Errorf("file exceeds the maximum size of %d bytes", s.maxDocumentSize)
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if !kycContentTypes[contentType] {
+		return nil, fmt.Errorf("file must be a JPEG, PNG or PDF")
+	}
+
+	doc := &domain.KYCDocument{
+		UserID:      userID,
+		Type:        docType,
+		StorageKey:  fmt.Sprintf("kyc/%d/%s", userID, uuid.New().String()),
+		FileName:    filepath.Base(fileName),
+		ContentType: contentType,
+		SizeBytes:   size,
+	}
+
+	if err := s.storage.Put(ctx, doc.StorageKey, io.MultiReader(bytes.NewReader(head), r)); err != nil {
+		return nil, fmt.Errorf("failed to store document: %w", err)
+	}
+
+	if err := s.kycRepo.CreateDocument(ctx, doc); err != nil {
+		s.deleteBlob(ctx, doc.StorageKey)
+		return nil, fmt.Errorf("failed to create document: %w", err)
+	}
+
+	return doc, nil
+}
+
+// DeleteDocument removes an upload that has not been submitted yet
+func (s *KYCService) DeleteDocument(ctx context.Context, userID, id int64) error {
+	key, err := s.kycRepo.DeleteUnsubmittedDocument(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	s.deleteBlob(ctx, key)
+	return nil
+}
+
+// Submit creates an application for level with all of the user's unsubmitted documents. The documents
+// must cover the requirements of every level between the current one and the requested one.
+func (s *KYCService) Submit(ctx context.Context, userID int64, level int16) (*domain.KYCApplication, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if level <= user.KYCLevel {
+		return nil, fmt.Errorf("already verified at level %d", user.KYCLevel)
+	}
+	if level > domain.MaxKYCLevel {
+		return nil, fmt.Errorf("level must be at most %d", domain.MaxKYCLevel)
+	}
+
+	app := &domain.KYCApplication{
+		UserID: userID,
+		Level:  level,
+		Status: domain.KYCApplicationStatusPending,
+	}
+
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		docs, err := s.kycRepo.GetUnsubmittedDocumentsTx(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkKYCRequirements(user.KYCLevel, level, docs); err != nil {
+			return err
+		}
+
+		if err := s.kycRepo.CreateApplicationTx(ctx, tx, app); err != nil {
+			return err
+		}
+
+		if err := s.kycRepo.AttachDocumentsTx(ctx, tx, userID, app.ID); err != nil {
+			return fmt.Errorf("failed to attach documents: %w", err)
+		}
+
+		for i := range docs {
+			docs[i].ApplicationID = &app.ID
+		}
+		app.Documents = docs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+func (s *KYCService) ListApplications(ctx context.Context, status string, limit, offset int) ([]domain.KYCApplication, int64, error) {
+	apps, err := s.kycRepo.ListApplications(ctx, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.kycRepo.CountApplications(ctx, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return apps, total, nil
+}
+
+func (s *KYCService) GetApplication(ctx context.Context, id int64) (*domain.KYCApplication, error) {
+	app, err := s.kycRepo.GetApplicationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if app.Documents, err = s.kycRepo.GetApplicationDocuments(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+// OpenDocument returns a document and its contents for review. The caller must close the reader.
+func (s *KYCService) OpenDocument(ctx context.Context, id int64) (*domain.KYCDocument, io.ReadCloser, error) {
+	doc, err := s.kycRepo.GetDocumentByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, err := s.storage.Get(ctx, doc.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open document: %w", err)
+	}
+
+	return doc, body, nil
+}
+
+// Approve grants the application's level
+func (s *KYCService) Approve(ctx context.Context, meta domain.AuditMeta, id int64, reason string) (*domain.KYCApplication, error) {
+	var updated domain.User
+	app, err := s.review(ctx, meta, domain.AuditActionKYCApprove, id, func(tx *sqlx.Tx, app *domain.KYCApplication) error {
+		user, err := s.userRepo.GetByID(ctx, app.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		// Copy so the cached user only changes once the transaction commits
+		updated = *user
+		updated.KYCLevel = max(updated.KYCLevel, app.Level)
+		if err := s.userRepo.UpdateKYCLevelTx(ctx, tx, &updated); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+
+		app.Status = domain.KYCApplicationStatusApproved
+		app.Reason = reason
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.userRepo.RefreshCache(&updated)
+
+	return app, nil
+}
+
+// Reject closes the application with a reason shown to the user. Its documents stay attached to it.
+func (s *KYCService) Reject(ctx context.Context, meta domain.AuditMeta, id int64, reason string) (*domain.KYCApplication, error) {
+	return s.review(ctx, meta, domain.AuditActionKYCReject, id, func(tx *sqlx.Tx, app *domain.KYCApplication) error {
+		app.Status = domain.KYCApplicationStatusRejected
+		app.Reason = reason
+		return nil
+	})
+}
+
+// review locks a pending application, applies decide, stores the decision and records action
+// in the audit log
+func (s *KYCService) review(
+	ctx context.Context,
+	meta domain.AuditMeta,
+	action domain.AuditAction,
+	id int64,
+	decide func(tx *sqlx.Tx, app *domain.KYCApplication) error,
+) (*domain.KYCApplication, error) {
+	var app *domain.KYCApplication
+	err := s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		app, err = s.kycRepo.LockApplicationByIDTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if app.Status != domain.KYCApplicationStatusPending {
+			return fmt.Errorf("kyc application is already %s", app.Status)
+		}
+
+		before := *app
+		if err := decide(tx, app); err != nil {
+			return err
+		}
+
+		app.ReviewedBy = &meta.ActorID
+		if err := s.kycRepo.ReviewApplicationTx(ctx, tx, app); err != nil {
+			return err
+		}
+
+		return s.auditService.RecordTx(ctx, tx, meta, action, domain.AuditTargetKYCApp, id, &before, app)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+func (s *KYCService) ListLimits(ctx context.Context) ([]domain.KYCLimit, error) {
+	return s.kycRepo.ListLimits(ctx)
+}
+
+func (s *KYCService) SetLimit(ctx context.Context, meta domain.AuditMeta, req *models.SetKYCLimitRequest) (*domain.KYCLimit, error) {
+	currency, err := s.walletRepo.GetCurrencyByCode(ctx, req.CurrencyCode)
+	if err != nil {
+		return nil, fmt.Errorf("currency %s not found", req.CurrencyCode)
+	}
+
+	if req.DailyWithdrawalLimit != nil && req.DailyWithdrawalLimit.IsNegative() {
+		return nil, fmt.Errorf("daily_withdrawal_limit cannot be negative")
+	}
+	if req.DailyExchangeLimit != nil && req.DailyExchangeLimit.IsNegative() {
+		return nil, fmt.Errorf("daily_exchange_limit cannot be negative")
+	}
+
+	limit := &domain.KYCLimit{
+		Level:                req.Level,
+		CurrencyID:           currency.ID,
+		CurrencyCode:         currency.Code,
+		DailyWithdrawalLimit: req.DailyWithdrawalLimit,
+		DailyExchangeLimit:   req.DailyExchangeLimit,
+	}
+
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		before, err := s.kycRepo.LockLimitTx(ctx, tx, limit.Level, limit.CurrencyID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.kycRepo.UpsertLimitTx(ctx, tx, limit); err != nil {
+			return fmt.Errorf("failed to set kyc limit: %w", err)
+		}
+
+		key := fmt.Sprintf("%d/%s", limit.Level, limit.CurrencyCode)
+		return s.auditService.RecordKeyTx(ctx, tx, meta, domain.AuditActionKYCSetLimit, domain.AuditTargetKYCLimit, key, before, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return limit, nil
+}
+
+// CheckWithdrawalLimitTx rejects a withdrawal that would take the day's withdrawals from the
+// wallet past the limit of the user's level. The wallet must be locked by tx.
+func (s *KYCService) CheckWithdrawalLimitTx(ctx context.Context, tx *sqlx.Tx, userID int64, wallet *domain.Wallet, amount decimal.Decimal) error {
+	limit, err := s.userLimit(ctx, userID, wallet.CurrencyID)
+	if err != nil || limit == nil || limit.DailyWithdrawalLimit == nil {
+		return err
+	}
+
+	volume, err := s.txRepo.GetDailyWithdrawalVolumeTx(ctx, tx, wallet.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get daily withdrawal volume: %w", err)
+	}
+
+	return checkKYCLimit("withdrawal", limit, *limit.DailyWithdrawalLimit, volume, amount)
+}
+
+// CheckExchangeLimitTx rejects an exchange that would take the day's exchanges out of the currency
+// past the limit of the user's level. The from-wallet must be locked by tx.
+func (s *KYCService) CheckExchangeLimitTx(ctx context.Context, tx *sqlx.Tx, userID int64, fromCurrencyID int32, amount decimal.Decimal) error {
+	limit, err := s.userLimit(ctx, userID, fromCurrencyID)
+	if err != nil || limit == nil || limit.DailyExchangeLimit == nil {
+		return err
+	}
+
+	volume, err := s.exchangeRepo.GetDailyVolumeByCurrencyTx(ctx, tx, userID, fromCurrencyID)
+	if err != nil {
+		return fmt.Errorf("failed to get daily exchange volume: %w", err)
+	}
+
+	return checkKYCLimit("exchange", limit, *limit.DailyExchangeLimit, volume, amount)
+}
+
+func (s *KYCService) userLimit(ctx context.Context, userID int64, currencyID int32) (*domain.KYCLimit, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	limit, err := s.kycRepo.GetLimit(ctx, user.KYCLevel, currencyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kyc limit: %w", err)
+	}
+
+	return limit, nil
+}
+
+func (s *KYCService) deleteBlob(ctx context.Context, key string) {
+	// Use a fresh context so a canceled request does not leave the file behind
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	if err := s.storage.Delete(ctx, key); err != nil {
+		s.log.Error("Failed to delete kyc document", "key", key, "error", err)
+	}
+}
+
+func checkKYCLimit(operation string, limit *domain.KYCLimit, dailyLimit, volume, amount decimal.Decimal) error {
+	if volume.Add(amount).LessThanOrEqual(dailyLimit) {
+		return nil
+	}
+
+	if dailyLimit.IsZero() {
+		return newServiceError(ErrCodeKYCLimitExceeded,
+			"%s of %s requires a higher verification level", operation, limit.CurrencyCode)
+	}
+
+	remaining := decimal.Max(dailyLimit.Sub(volume), decimal.Zero)
+	return newServiceError(ErrCodeKYCLimitExceeded,
+		"daily %s limit of %s %s for your verification level exceeded, %s remaining today",
+		operation, dailyLimit, limit.CurrencyCode, remaining)
+}
+
+// checkKYCRequirements verifies that docs cover every level above current up to and including level
+func checkKYCRequirements(current, level int16, docs []domain.KYCDocument) error {
+	uploaded := make(map[domain.KYCDocumentType]bool, len(docs))
+	for _, doc := range docs {
+		uploaded[doc.Type] = true
+	}
+
+	for l := current + 1; l <= level; l++ {
+		for _, group := range domain.KYCLevelRequirements[l] {
+			found := false
+			for _, docType := range group {
+				if uploaded[docType] {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("level %d requires a %s document", l, joinDocumentTypes(group))
+			}
+		}
+	}
+
+	return nil
+}
+
+func joinDocumentTypes(types []domain.KYCDocumentType) string {
+	var buf bytes.Buffer
+	for i, t := range types {
+		if i > 0 {
+			buf.WriteString(" or ")
+		}
+		buf.WriteString(string(t))
+	}
+	return buf.String()
+}
+
+func isKYCDocumentType(t domain.KYCDocumentType) bool {
+	switch t {
+	case domain.KYCDocumentTypePassport, domain.KYCDocumentTypeIDCard,
+		domain.KYCDocumentTypeSelfie, domain.KYCDocumentTypeProofOfAddress:
+		return true
+	}
+	return false
+}
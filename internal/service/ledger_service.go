@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+)
+
+type LedgerService struct {
+	ledgerRepo *repository.LedgerRepository
+}
+
+func NewLedgerService(ledgerRepo *repository.LedgerRepository) *LedgerService {
+	return &LedgerService{
+		ledgerRepo: ledgerRepo,
+	}
+}
+
+// Post validates that the journal is balanced per currency and persists it
+func (s *LedgerService) Post(ctx context.Context, journal *domain.LedgerJournal) error {
+	if err := s.prepare(journal); err != nil {
+		return err
+	}
+
+	return s.ledgerRepo.CreateJournal(ctx, journal)
+}
+
+// PostTx is Post inside the caller's transaction, so the journal commits or rolls back with the balance change
+func (s *LedgerService) PostTx(ctx context.Context, tx *sqlx.Tx, journal *domain.LedgerJournal) error {
+	if err := s.prepare(journal); err != nil {
+		return err
+	}
+
+	return s.ledgerRepo.CreateJournalTx(ctx, tx, journal)
+}
+
+// RecordDepositTx posts external -> wallet for a completed deposit
+func (s *LedgerService) RecordDepositTx(ctx context.Context, sqlTx *sqlx.Tx, wallet *domain.Wallet, tx *domain.Transaction) error {
+	journal := &domain.LedgerJournal{
+		Type:          domain.LedgerJournalTypeDeposit,
+		ReferenceType: domain.LedgerReferenceTransaction,
+		ReferenceID:   tx.ID,
+		Description:   fmt.Sprintf("Deposit to wallet %d", wallet.ID),
+		Postings: []domain.LedgerPosting{
+			systemPosting(domain.LedgerAccountExternal, wallet.CurrencyID, tx.Amount.Neg()),
+			walletPosting(wallet, tx.Amount),
+		},
+	}
+
+	return s.PostTx(ctx, sqlTx, journal)
+}
+
+// RecordWithdrawalHoldTx posts wallet -> wallet locked for a withdrawal awaiting review
+func (s *LedgerService) RecordWithdrawalHoldTx(ctx context.Context, sqlTx *sqlx.Tx, wallet *domain.Wallet, tx *domain.Transaction) error {
+	journal := &domain.LedgerJournal{
+		Type:          domain.LedgerJournalTypeWithdrawalHold,
+		ReferenceType: domain.LedgerReferenceTransaction,
+		ReferenceID:   tx.ID,
+		Description:   fmt.Sprintf("Withdrawal hold on wallet %d", wallet.ID),
+		Postings: []domain.LedgerPosting{
+			walletPosting(wallet, tx.HeldAmount().Neg()),
+			lockedPosting(wallet, tx.HeldAmount()),
+		},
+	}
+
+	return s.PostTx(ctx, sqlTx, journal)
+}
+
+// RecordWithdrawalReleaseTx posts wallet locked -> wallet when a withdrawal is rejected
+func (s *LedgerService) RecordWithdrawalReleaseTx(ctx context.Context, sqlTx *sqlx.Tx, wallet *domain.Wallet, tx *domain.Transaction) error {
+	journal := &domain.LedgerJournal{
+		Type:          domain.LedgerJournalTypeWithdrawalRelease,
+		ReferenceType: domain.LedgerReferenceTransaction,
+		ReferenceID:   tx.ID,
+		Description:   fmt.Sprintf("Withdrawal hold released on wallet %d", wallet.ID),
+		Postings: []domain.LedgerPosting{
+			lockedPosting(wallet, tx.HeldAmount().Neg()),
+			walletPosting(wallet, tx.HeldAmount()),
+		},
+	}
+
+	return s.PostTx(ctx, sqlTx, journal)
+}
+
+// RecordWithdrawalTx posts wallet locked -> external (amount) and wallet locked -> fees (fee) once a withdrawal is sent
+func (s *LedgerService) RecordWithdrawalTx(ctx context.Context, sqlTx *sqlx.Tx, wallet *domain.Wallet, tx *domain.Transaction) error {
+	journal := &domain.LedgerJournal{
+		Type:          domain.LedgerJournalTypeWithdrawal,
+		ReferenceType: domain.LedgerReferenceTransaction,
+		ReferenceID:   tx.ID,
+		Description:   fmt.Sprintf("Withdrawal from wallet %d", wallet.ID),
+		Postings: []domain.LedgerPosting{
+			lockedPosting(wallet, tx.HeldAmount().Neg()),
+			systemPosting(domain.LedgerAccountExternal, wallet.CurrencyID, tx.Amount),
+			systemPosting(domain.LedgerAccountFees, wallet.CurrencyID, tx.Fee),
+		},
+	}
+
+	return s.PostTx(ctx, sqlTx, journal)
+}
+
+// RecordExchangeTx posts both legs of a swap through the exchange clearing account.
+// The fee is the difference between the gross and net to-amounts and goes to the fees account.
+// Limit exchanges are paid from the hold placed by RecordExchangeHoldTx.
+func (s *LedgerService) RecordExchangeTx(ctx context.Context, tx *sqlx.Tx, exchange *domain.CurrencyExchange, fromWallet, toWallet *domain.Wallet) error {
+	debit := walletPosting(fromWallet, exchange.FromAmount.Neg())
+	if exchange.IsLimit() {
+		debit = lockedPosting(fromWallet, exchange.FromAmount.Neg())
+	}
+
+	journal := &domain.LedgerJournal{
+		Type:          domain.LedgerJournalTypeExchange,
+		ReferenceType: domain.LedgerReferenceExchange,
+		ReferenceID:   exchange.ID,
+		Description:   fmt.Sprintf("Exchange %s", exchange.UID),
+		Postings: []domain.LedgerPosting{
+			debit,
+			systemPosting(domain.LedgerAccountExchange, fromWallet.CurrencyID, exchange.FromAmount),
+			systemPosting(domain.LedgerAccountExchange, toWallet.CurrencyID, exchange.ToAmount.Neg()),
+			walletPosting(toWallet, exchange.ToAmountWithFee),
+			systemPosting(domain.LedgerAccountFees, toWallet.CurrencyID, exchange.ToAmount.Sub(exchange.ToAmountWithFee)),
+		},
+	}
+
+	return s.PostTx(ctx, tx, journal)
+}
+
+// RecordExchangeHoldTx posts wallet -> wallet locked for a limit exchange waiting for its rate
+func (s *LedgerService) RecordExchangeHoldTx(ctx context.Context, tx *sqlx.Tx, exchange *domain.CurrencyExchange, fromWallet *domain.Wallet) error {
+	journal := &domain.LedgerJournal{
+		Type:          domain.LedgerJournalTypeExchangeHold,
+		ReferenceType: domain.LedgerReferenceExchange,
+		ReferenceID:   exchange.ID,
+		Description:   fmt.Sprintf("Exchange hold %s", exchange.UID),
+		Postings: []domain.LedgerPosting{
+			walletPosting(fromWallet, exchange.FromAmount.Neg()),
+			lockedPosting(fromWallet, exchange.FromAmount),
+		},
+	}
+
+	return s.PostTx(ctx, tx, journal)
+}
+
+// RecordExchangeReleaseTx posts wallet locked -> wallet when a limit exchange is canceled or expires
+func (s *LedgerService) RecordExchangeReleaseTx(ctx context.Context, tx *sqlx.Tx, exchange *domain.CurrencyExchange, fromWallet *domain.Wallet) error {
+	journal := &domain.LedgerJournal{
+		Type:          domain.LedgerJournalTypeExchangeRelease,
+		ReferenceType: domain.LedgerReferenceExchange,
+		ReferenceID:   exchange.ID,
+		Description:   fmt.Sprintf("Exchange hold released %s", exchange.UID),
+		Postings: []domain.LedgerPosting{
+			lockedPosting(fromWallet, exchange.FromAmount.Neg()),
+			walletPosting(fromWallet, exchange.FromAmount),
+		},
+	}
+
+	return s.PostTx(ctx, tx, journal)
+}
+
+func (s *LedgerService) GetJournal(ctx context.Context, id int64) (*domain.LedgerJournal, error) {
+	return s.ledgerRepo.GetJournalByID(ctx, id)
+}
+
+func (s *LedgerService) GetWalletPostings(ctx context.Context, walletID int64, limit, offset int) ([]domain.LedgerPosting, error) {
+	return s.ledgerRepo.GetWalletPostings(ctx, walletID, limit, offset)
+}
+
+// GetWalletBalance derives a wallet's available balance from its ledger postings
+func (s *LedgerService) GetWalletBalance(ctx context.Context, walletID int64) (decimal.Decimal, error) {
+	return s.ledgerRepo.GetAccountBalance(ctx, domain.WalletLedgerAccount(walletID))
+}
+
+// Reconcile returns every wallet whose stored balance disagrees with the ledger
+func (s *LedgerService) Reconcile(ctx context.Context) ([]domain.WalletLedgerMismatch, error) {
+	return s.ledgerRepo.GetWalletMismatches(ctx)
+}
+
+// prepare drops zero legs and rejects journals that do not net to exactly zero per currency
+func (s *LedgerService) prepare(journal *domain.LedgerJournal) error {
+	if journal.UID == "" {
+		journal.UID = uuid.New().String()
+	}
+
+	postings := journal.Postings[:0]
+	sums := make(map[int32]decimal.Decimal)
+	for _, posting := range journal.Postings {
+		if posting.Amount.IsZero() {
+			continue
+		}
+		sums[posting.CurrencyID] = sums[posting.CurrencyID].Add(posting.Amount)
+		postings = append(postings, posting)
+	}
+	journal.Postings = postings
+
+	if len(journal.Postings) < 2 {
+		return fmt.Errorf("ledger journal must have at least two non-zero postings")
+	}
+
+	for currencyID, sum := range sums {
+		if !sum.IsZero() {
+			return fmt.Errorf("ledger journal is not balanced for currency %d: off by %s", currencyID, sum)
+		}
+	}
+
+	return nil
+}
+
+func walletPosting(wallet *domain.Wallet, amount decimal.Decimal) domain.LedgerPosting {
+	walletID := wallet.ID
+	return domain.LedgerPosting{
+		Account:    domain.WalletLedgerAccount(wallet.ID),
+		WalletID:   &walletID,
+		CurrencyID: wallet.CurrencyID,
+		Amount:     amount,
+	}
+}
+
+func lockedPosting(wallet *domain.Wallet, amount decimal.Decimal) domain.LedgerPosting {
+	walletID := wallet.ID
+	return domain.LedgerPosting{
+		Account:    domain.WalletLockedLedgerAccount(wallet.ID),
+		WalletID:   &walletID,
+		CurrencyID: wallet.CurrencyID,
+		Amount:     amount,
+	}
+}
+
+func systemPosting(account string, currencyID int32, amount decimal.Decimal) domain.LedgerPosting {
+	return domain.LedgerPosting{
+		Account:    account,
+		CurrencyID: currencyID,
+		Amount:     amount,
+	}
+}
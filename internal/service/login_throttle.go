@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/caspianex/exchange-backend/pkg/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// loginFreeFailures is how many failed sign-ins go by before attempts are slowed down
+	loginFreeFailures = 3
+	loginBaseDelay    = time.Second
+	loginMaxDelay     = 30 * time.Second
+
+	// mfaTokenMaxAttempts is how many codes may be tried with one mfa_pending token
+	mfaTokenMaxAttempts = 5
+)
+
+// LoginLockoutPolicy sets when failed sign-ins lock an account or client IP. A zero maximum
+// disables locking for that scope; the progressive delay still applies.
+type LoginLockoutPolicy struct {
+	MaxFailures     int // per email address tried
+	IPMaxFailures   int // per client IP
+	LockoutDuration time.Duration
+	// FailureWindow is how long after the last failure a run of failures is forgotten
+	FailureWindow time.Duration
+}
+
+// LoginThrottle slows down and temporarily locks sign-ins after repeated failures. Accounts are
+// tracked by the email address tried, so unknown addresses are treated exactly like real ones.
+type LoginThrottle struct {
+	db       *database.Postgres
+	failures *repository.LoginFailureRepository
+	policy   LoginLockoutPolicy
+	logger   *logger.Logger
+}
+
+func NewLoginThrottle(db *database.Postgres, failures *repository.LoginFailureRepository, policy LoginLockoutPolicy, logger *logger.Logger) *LoginThrottle {
+	return &LoginThrottle{
+		db:       db,
+		failures: failures,
+		policy:   policy,
+		logger:   logger,
+	}
+}
+
+type loginSubject struct {
+	scope       domain.LoginFailureScope
+	subject     string
+	maxFailures int
+}
+
+func (t *LoginThrottle) subjects(email, ip string) []loginSubject {
+	return []loginSubject{
+		{domain.LoginFailureScopeAccount, normalizeLoginEmail(email), t.policy.MaxFailures},
+		{domain.LoginFailureScopeIP, ip, t.policy.IPMaxFailures},
+	}
+}
+
+// Reserve counts an attempt against the email and the IP before the password is compared, so
+// parallel guesses cannot all get in before the first failure is recorded. The attempt is
+// refused while either one must wait. Follow up with Failed or Succeeded. Database errors let
+// the attempt through rather than locking everyone out.
+func (t *LoginThrottle) Reserve(ctx context.Context, email, ip string) error {
+	now := time.Now()
+	var wait time.Duration
+
+	err := t.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		// Rows are always locked account first, so two reservations cannot deadlock
+		var failures []*domain.LoginFailure
+		for _, s := range t.subjects(email, ip) {
+			failure, err := t.failures.GetForUpdateTx(ctx, tx, s.scope, s.subject, now)
+			if err != nil {
+				return err
+			}
+
+			if failure.LastFailureAt.Before(now.Add(-t.policy.FailureWindow)) {
+				failure.Failures = 0
+				failure.LockedUntil = nil
+			}
+			if w := loginWait(failure, now); w > wait {
+				wait = w
+			}
+			failures = append(failures, failure)
+		}
+
+		if wait > 0 {
+			return nil
+		}
+
+		for _, failure := range failures {
+			failure.Failures++
+			failure.LastFailureAt = now
+			if err := t.failures.UpdateTx(ctx, tx, failure); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.logger.Error("Failed to reserve login attempt", "error", err)
+		return nil
+	}
+
+	if wait > 0 {
+		return newServiceError(ErrCodeLoginThrottled, "too many failed login attempts, try again in %s", (wait + time.Second - 1).Truncate(time.Second))
+	}
+	return nil
+}
+
+// Failed turns the attempt Reserve counted into a failure, locking the email or the IP when it
+// reached its maximum. It returns when the account lock ends if this failure locked the account.
+func (t *LoginThrottle) Failed(ctx context.Context, email, ip string) *time.Time {
+	until := time.Now().Add(t.policy.LockoutDuration)
+	var accountLockedUntil *time.Time
+
+	for _, s := range t.subjects(email, ip) {
+		if s.maxFailures <= 0 {
+			continue
+		}
+
+		locked, err := t.failures.LockIfReached(ctx, s.scope, s.subject, s.maxFailures, until)
+		if err != nil {
+			t.logger.Error("Failed to lock login", "scope", s.scope, "error", err)
+			continue
+		}
+		if !locked {
+			continue
+		}
+
+		t.logger.Warn("Login locked after repeated failures", "scope", s.scope, "ip", ip)
+		if s.scope == domain.LoginFailureScopeAccount {
+			accountLockedUntil = &until
+		}
+	}
+
+	return accountLockedUntil
+}
+
+// Succeeded clears the email's failures and gives back the attempt Reserve counted against the IP
+func (t *LoginThrottle) Succeeded(ctx context.Context, email, ip string) {
+	t.Reset(ctx, email)
+	if err := t.failures.Release(ctx, domain.LoginFailureScopeIP, ip); err != nil {
+		t.logger.Error("Failed to release login attempt", "error", err)
+	}
+}
+
+// Release gives back the attempt Reserve counted against the email and the IP without clearing
+// earlier failures. Used when the password was right but a second factor is still to come.
+func (t *LoginThrottle) Release(ctx context.Context, email, ip string) {
+	for _, s := range t.subjects(email, ip) {
+		if err := t.failures.Release(ctx, s.scope, s.subject); err != nil {
+			t.logger.Error("Failed to release login attempt", "scope", s.scope, "error", err)
+		}
+	}
+}
+
+// UseMFAToken counts a second-factor attempt made with the mfa_pending token tokenID. The token
+// is refused once it was used to sign in or mfaTokenMaxAttempts codes were tried with it.
+func (t *LoginThrottle) UseMFAToken(ctx context.Context, tokenID string, issuedAt time.Time) error {
+	// The count is taken before the code is checked, so parallel guesses share it
+	attempts, err := t.failures.RecordFailure(ctx, domain.LoginFailureScopeMFAToken, tokenID, time.Now(), issuedAt)
+	if err != nil {
+		t.logger.Error("Failed to count mfa token attempt", "error", err)
+		return nil
+	}
+
+	if attempts.LockedUntil != nil || attempts.Failures > mfaTokenMaxAttempts {
+		return fmt.Errorf("invalid or expired mfa token")
+	}
+	return nil
+}
+
+// SpendMFAToken refuses any further use of the mfa_pending token tokenID after a sign-in
+func (t *LoginThrottle) SpendMFAToken(ctx context.Context, tokenID string, expiresAt time.Time) {
+	if err := t.failures.Lock(ctx, domain.LoginFailureScopeMFAToken, tokenID, expiresAt); err != nil {
+		t.logger.Error("Failed to spend mfa token", "error", err)
+	}
+}
+
+// Reset clears the email's failures after a successful sign-in or password reset. The IP's
+// failures are kept, otherwise signing in to an own account would reset an attacker's count.
+func (t *LoginThrottle) Reset(ctx context.Context, email string) {
+	if err := t.failures.Delete(ctx, domain.LoginFailureScopeAccount, normalizeLoginEmail(email)); err != nil {
+		t.logger.Error("Failed to reset login failures", "error", err)
+	}
+	if err := t.failures.DeleteStale(ctx, time.Now().Add(-t.policy.FailureWindow)); err != nil {
+		t.logger.Error("Failed to delete stale login failures", "error", err)
+	}
+}
+
+// loginWait is how long until the next attempt is allowed: the rest of a lockout, or a delay
+// that doubles with each failure past the free ones
+func loginWait(failure *domain.LoginFailure, now time.Time) time.Duration {
+	if failure.LockedUntil != nil && failure.LockedUntil.After(now) {
+		return failure.LockedUntil.Sub(now)
+	}
+
+	if failure.Failures < loginFreeFailures {
+		return 0
+	}
+
+	delay := loginMaxDelay
+	if steps := failure.Failures - loginFreeFailures; steps < 10 {
+		delay = min(loginBaseDelay<<steps, loginMaxDelay)
+	}
+
+	return max(failure.LastFailureAt.Add(delay).Sub(now), 0)
+}
+
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -1,39 +1,221 @@
 package service
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/caspianex/exchange-backend/internal/domain"
 	"github.com/caspianex/exchange-backend/internal/models"
 	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/caspianex/exchange-backend/pkg/database"
 	"github.com/caspianex/exchange-backend/pkg/email"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+const (
+	// maxLimitExchangeLifetime caps how far out a limit exchange may expire
+	maxLimitExchangeLifetime = 30 * 24 * time.Hour
+	// matchBatchSize is how many limit exchanges the matcher reads or expires per query
+	matchBatchSize = 500
 )
 
 type CurrencyExchangeService struct {
-	exchangeRepo *repository.CurrencyExchangeRepository
-	walletRepo   *repository.WalletRepository
-	userRepo     *repository.UserRepository
-	emailService *email.EmailService
+	db            *database.Postgres
+	exchangeRepo  *repository.CurrencyExchangeRepository
+	quoteRepo     *repository.ExchangeQuoteRepository
+	walletRepo    *repository.WalletRepository
+	userRepo      *repository.UserRepository
+	ledgerService *LedgerService
+	feeService    *FeeService
+	kycService    *KYCService
+	emailService  *email.EmailService
+	quoteTTL      time.Duration
 }
 
 func NewCurrencyExchangeService(
+	db *database.Postgres,
 	exchangeRepo *repository.CurrencyExchangeRepository,
+	quoteRepo *repository.ExchangeQuoteRepository,
 	walletRepo *repository.WalletRepository,
 	userRepo *repository.UserRepository,
+	ledgerService *LedgerService,
+	feeService *FeeService,
+	kycService *KYCService,
 	emailService *email.EmailService,
+	quoteTTL time.Duration,
 ) *CurrencyExchangeService {
 	return &CurrencyExchangeService{
-		exchangeRepo: exchangeRepo,
-		walletRepo:   walletRepo,
-		userRepo:     userRepo,
-		emailService: emailService,
+		db:            db,
+		exchangeRepo:  exchangeRepo,
+		quoteRepo:     quoteRepo,
+		walletRepo:    walletRepo,
+		userRepo:      userRepo,
+		ledgerService: ledgerService,
+		feeService:    feeService,
+		kycService:    kycService,
+		emailService:  emailService,
+		quoteTTL:      quoteTTL,
 	}
 }
 
+// CreateQuote prices an exchange and stores the price so it can be executed unchanged until it expires
+func (s *CurrencyExchangeService) CreateQuote(ctx context.Context, userID int64, req *models.CreateQuoteRequest) (*domain.ExchangeQuote, error) {
+	quote, err := s.price(ctx, userID, req.FromCurrencyCode, req.ToCurrencyCode, req.FromAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	quote.UID = uuid.New().String()
+	quote.ExpiresAt = time.Now().Add(s.quoteTTL)
+
+	if err := s.quoteRepo.Create(ctx, quote); err != nil {
+		return nil, fmt.Errorf("failed to create quote: %w", err)
+	}
+
+	return quote, nil
+}
+
+// CreateExchange executes an exchange, either at the current rate or exactly as priced by req.QuoteID
 func (s *CurrencyExchangeService) CreateExchange(ctx context.Context, userID int64, req *models.CreateExchangeRequest) (*domain.CurrencyExchange, error) {
-	// Get currency information
+	if req.TargetRate != nil {
+		if req.QuoteID != "" {
+			return nil, fmt.Errorf("a limit exchange cannot execute a quote")
+		}
+		return s.createLimitExchange(ctx, userID, req)
+	}
+
+	var priced *domain.ExchangeQuote
+	if req.QuoteID == "" {
+		var err error
+		priced, err = s.price(ctx, userID, req.FromCurrencyCode, req.ToCurrencyCode, req.FromAmount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var exchange *domain.CurrencyExchange
+
+	// Swap, exchange record and ledger entry commit or roll back together.
+	// Both wallets are row-locked so concurrent swaps serialize on the balance check.
+	var fromWallet, toWallet *domain.Wallet
+	err := s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		var quote *domain.ExchangeQuote
+		if req.QuoteID != "" {
+			var err error
+			quote, err = s.lockQuote(ctx, tx, userID, req)
+			if err != nil {
+				return err
+			}
+			priced = quote
+		}
+
+		exchange = &domain.CurrencyExchange{
+			UID:             uuid.New().String(),
+			UserID:          userID,
+			FromCurrencyID:  priced.FromCurrencyID,
+			ToCurrencyID:    priced.ToCurrencyID,
+			FromAmount:      priced.FromAmount,
+			ToAmount:        priced.ToAmount,
+			ToAmountWithFee: priced.ToAmountWithFee,
+			Fee:             priced.Fee,
+			FeeAmount:       priced.FeeAmount,
+			FeeRuleID:       priced.FeeRuleID,
+			ExchangeRate:    priced.ExchangeRate,
+			Status:          domain.CurrencyExchangeStatusCompleted,
+		}
+
+		wallets, err := s.walletRepo.LockByUserAndCurrencies(ctx, tx, userID, exchange.FromCurrencyID, exchange.ToCurrencyID)
+		if err != nil {
+			return fmt.Errorf("failed to lock wallets: %w", err)
+		}
+		fromWallet = wallets[exchange.FromCurrencyID]
+		toWallet = wallets[exchange.ToCurrencyID]
+
+		// Check sufficient balance
+		if fromWallet.Balance.LessThan(exchange.FromAmount) {
+			return ErrInsufficientBalance
+		}
+
+		// The from-wallet lock serializes the user's exchanges on the pair, so the volume read is stable
+		if err := s.checkDailyLimitTx(ctx, tx, userID, exchange.FromCurrencyID, exchange.ToCurrencyID, exchange.FromAmount); err != nil {
+			return err
+		}
+
+		if err := s.kycService.CheckExchangeLimitTx(ctx, tx, userID, exchange.FromCurrencyID, exchange.FromAmount); err != nil {
+			return err
+		}
+
+		// Perform wallet swap: deduct from fromWallet, credit to toWallet
+		fromWallet.Balance = fromWallet.Balance.Sub(exchange.FromAmount)
+		if err := s.walletRepo.UpdateBalanceTx(ctx, tx, fromWallet); err != nil {
+			return fmt.Errorf("failed to deduct from wallet: %w", err)
+		}
+
+		toWallet.Balance = toWallet.Balance.Add(exchange.ToAmountWithFee)
+		if err := s.walletRepo.UpdateBalanceTx(ctx, tx, toWallet); err != nil {
+			return fmt.Errorf("failed to credit to wallet: %w", err)
+		}
+
+		if err := s.exchangeRepo.CreateTx(ctx, tx, exchange); err != nil {
+			return fmt.Errorf("failed to create exchange: %w", err)
+		}
+
+		if err := s.ledgerService.RecordExchangeTx(ctx, tx, exchange, fromWallet, toWallet); err != nil {
+			return fmt.Errorf("failed to record ledger entry: %w", err)
+		}
+
+		if quote != nil {
+			if err := s.quoteRepo.MarkExecutedTx(ctx, tx, quote, exchange.ID); err != nil {
+				return fmt.Errorf("failed to mark quote executed: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache reflects committed state only
+	s.walletRepo.RefreshCache(fromWallet, toWallet)
+
+	// Send notification email
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err == nil {
+		go s.emailService.SendOrderCreatedEmail(user.Email, user.FirstName, exchange)
+	}
+
+	return exchange, nil
+}
+
+// createLimitExchange holds the from-amount in Wallet.Locked and leaves the exchange pending until
+// MatchPendingExchanges sees a rate at or above the target. The stored amounts are priced at the
+// target rate and are replaced with the actual execution price.
+func (s *CurrencyExchangeService) createLimitExchange(ctx context.Context, userID int64, req *models.CreateExchangeRequest) (*domain.CurrencyExchange, error) {
+	if !req.TargetRate.IsPositive() {
+		return nil, fmt.Errorf("target rate must be positive")
+	}
+
+	if req.ExpiresAt == nil {
+		return nil, fmt.Errorf("limit exchanges require expires_at")
+	}
+
+	now := time.Now()
+	if !req.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("expires_at must be in the future")
+	}
+	if req.ExpiresAt.After(now.Add(maxLimitExchangeLifetime)) {
+		return nil, fmt.Errorf("expires_at must be within %s", maxLimitExchangeLifetime)
+	}
+
 	fromCurrency, err := s.walletRepo.GetCurrencyByCode(ctx, req.FromCurrencyCode)
 	if err != nil {
 		return nil, fmt.Errorf("from currency not found: %w", err)
@@ -44,71 +226,485 @@ func (s *CurrencyExchangeService) CreateExchange(ctx context.Context, userID int
 		return nil, fmt.Errorf("to currency not found: %w", err)
 	}
 
+	if fromCurrency.ID == toCurrency.ID {
+		return nil, fmt.Errorf("from and to currencies must be different")
+	}
+
+	rate, err := s.exchangeRepo.GetExchangeRate(ctx, fromCurrency.ID, toCurrency.ID)
+	if err != nil {
+		return nil, fmt.Errorf("exchange rate not available for this pair: %w", err)
+	}
+
+	if err := checkPairAmount(rate, fromCurrency, req.FromAmount); err != nil {
+		return nil, err
+	}
+
+	priced, err := s.priceAt(ctx, userID, fromCurrency, toCurrency, req.FromAmount, *req.TargetRate, rate.Fee)
+	if err != nil {
+		return nil, err
+	}
+
+	exchange := &domain.CurrencyExchange{
+		UID:             uuid.New().String(),
+		UserID:          userID,
+		FromCurrencyID:  priced.FromCurrencyID,
+		ToCurrencyID:    priced.ToCurrencyID,
+		FromAmount:      priced.FromAmount,
+		ToAmount:        priced.ToAmount,
+		ToAmountWithFee: priced.ToAmountWithFee,
+		Fee:             priced.Fee,
+		FeeAmount:       priced.FeeAmount,
+		FeeRuleID:       priced.FeeRuleID,
+		ExchangeRate:    priced.ExchangeRate,
+		TargetRate:      req.TargetRate,
+		ExpiresAt:       req.ExpiresAt,
+		Status:          domain.CurrencyExchangeStatusPending,
+	}
+
+	var fromWallet *domain.Wallet
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		// Locking the to-wallet too makes sure it exists before funds are held
+		wallets, err := s.walletRepo.LockByUserAndCurrencies(ctx, tx, userID, exchange.FromCurrencyID, exchange.ToCurrencyID)
+		if err != nil {
+			return fmt.Errorf("failed to lock wallets: %w", err)
+		}
+		fromWallet = wallets[exchange.FromCurrencyID]
+
+		if fromWallet.Balance.LessThan(exchange.FromAmount) {
+			return ErrInsufficientBalance
+		}
+
+		if err := s.checkDailyLimitTx(ctx, tx, userID, exchange.FromCurrencyID, exchange.ToCurrencyID, exchange.FromAmount); err != nil {
+			return err
+		}
+
+		if err := s.kycService.CheckExchangeLimitTx(ctx, tx, userID, exchange.FromCurrencyID, exchange.FromAmount); err != nil {
+			return err
+		}
+
+		fromWallet.Balance = fromWallet.Balance.Sub(exchange.FromAmount)
+		fromWallet.Locked = fromWallet.Locked.Add(exchange.FromAmount)
+		if err := s.walletRepo.UpdateBalanceTx(ctx, tx, fromWallet); err != nil {
+			return fmt.Errorf("failed to hold funds: %w", err)
+		}
+
+		if err := s.exchangeRepo.CreateTx(ctx, tx, exchange); err != nil {
+			return fmt.Errorf("failed to create exchange: %w", err)
+		}
+
+		if err := s.ledgerService.RecordExchangeHoldTx(ctx, tx, exchange, fromWallet); err != nil {
+			return fmt.Errorf("failed to record ledger entry: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.walletRepo.RefreshCache(fromWallet)
+
+	return exchange, nil
+}
+
+// MatchPendingExchanges expires overdue limit exchanges and executes those whose pair rate has
+// reached the target, paging through all of them. Called by the rate worker after every update.
+func (s *CurrencyExchangeService) MatchPendingExchanges(ctx context.Context) (int, int, error) {
+	var errs []error
+	expired, err := s.expireLimitExchanges(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to expire exchanges: %w", err))
+	}
+
+	currencies, err := s.walletRepo.GetAllCurrencies(ctx)
+	if err != nil {
+		return 0, expired, errors.Join(append(errs, fmt.Errorf("failed to get currencies: %w", err))...)
+	}
+	currencyByID := make(map[int32]*domain.Currency, len(currencies))
+	for i := range currencies {
+		currencyByID[currencies[i].ID] = &currencies[i]
+	}
+
+	executed := 0
+	var afterID int64
+	for {
+		// Executed exchanges leave the set, so pages are keyed by ID rather than offset
+		matches, err := s.exchangeRepo.GetMatchedLimitExchanges(ctx, afterID, matchBatchSize)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get pending exchanges: %w", err))
+			break
+		}
+
+		for i := range matches {
+			match := &matches[i]
+			afterID = match.ID
+
+			fromCurrency, toCurrency := currencyByID[match.FromCurrencyID], currencyByID[match.ToCurrencyID]
+			if fromCurrency == nil || toCurrency == nil {
+				continue
+			}
+
+			priced, err := s.priceAt(ctx, match.UserID, fromCurrency, toCurrency, match.FromAmount, match.MarketRate, match.MarketFee)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to price exchange %d: %w", match.ID, err))
+				continue
+			}
+
+			if err := s.executeLimit(ctx, match.ID, priced); err != nil {
+				errs = append(errs, fmt.Errorf("failed to execute exchange %d: %w", match.ID, err))
+				continue
+			}
+			executed++
+		}
+
+		if len(matches) < matchBatchSize {
+			break
+		}
+	}
+
+	return executed, expired, errors.Join(errs...)
+}
+
+// expireLimitExchanges expires overdue limit exchanges a batch per transaction and releases
+// their holds. It returns how many were expired.
+func (s *CurrencyExchangeService) expireLimitExchanges(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		var exchanges []domain.CurrencyExchange
+		wallets := make(map[int64]*domain.Wallet)
+		err := s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+			var err error
+			exchanges, err = s.exchangeRepo.ExpireLimitTx(ctx, tx, matchBatchSize)
+			if err != nil {
+				return err
+			}
+
+			// Lock wallets in a fixed order so concurrent passes cannot deadlock
+			slices.SortFunc(exchanges, func(a, b domain.CurrencyExchange) int {
+				return cmp.Or(
+					cmp.Compare(a.UserID, b.UserID),
+					cmp.Compare(a.FromCurrencyID, b.FromCurrencyID),
+					cmp.Compare(a.ID, b.ID),
+				)
+			})
+
+			for i := range exchanges {
+				wallet, err := s.releaseHoldTx(ctx, tx, &exchanges[i])
+				if err != nil {
+					return err
+				}
+				wallets[wallet.ID] = wallet
+			}
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+
+		for _, wallet := range wallets {
+			s.walletRepo.RefreshCache(wallet)
+		}
+
+		total += len(exchanges)
+		if len(exchanges) < matchBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// executeLimit fills a pending limit exchange at priced, paying from the hold
+func (s *CurrencyExchangeService) executeLimit(ctx context.Context, exchangeID int64, priced *domain.ExchangeQuote) error {
+	var exchange *domain.CurrencyExchange
+	var fromWallet, toWallet *domain.Wallet
+	err := s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		exchange, err = s.exchangeRepo.LockByIDTx(ctx, tx, exchangeID)
+		if err != nil {
+			return err
+		}
+
+		// Canceled while it was being priced
+		if exchange.Status != domain.CurrencyExchangeStatusPending {
+			exchange = nil
+			return nil
+		}
+
+		wallets, err := s.walletRepo.LockByUserAndCurrencies(ctx, tx, exchange.UserID, exchange.FromCurrencyID, exchange.ToCurrencyID)
+		if err != nil {
+			return fmt.Errorf("failed to lock wallets: %w", err)
+		}
+		fromWallet = wallets[exchange.FromCurrencyID]
+		toWallet = wallets[exchange.ToCurrencyID]
+
+		exchange.ToAmount = priced.ToAmount
+		exchange.ToAmountWithFee = priced.ToAmountWithFee
+		exchange.ExchangeRate = priced.ExchangeRate
+		exchange.Fee = priced.Fee
+		exchange.FeeAmount = priced.FeeAmount
+		exchange.FeeRuleID = priced.FeeRuleID
+		exchange.Status = domain.CurrencyExchangeStatusCompleted
+
+		fromWallet.Locked = fromWallet.Locked.Sub(exchange.FromAmount)
+		if err := s.walletRepo.UpdateBalanceTx(ctx, tx, fromWallet); err != nil {
+			return fmt.Errorf("failed to settle hold: %w", err)
+		}
+
+		toWallet.Balance = toWallet.Balance.Add(exchange.ToAmountWithFee)
+		if err := s.walletRepo.UpdateBalanceTx(ctx, tx, toWallet); err != nil {
+			return fmt.Errorf("failed to credit to wallet: %w", err)
+		}
+
+		if err := s.exchangeRepo.UpdateExecutionTx(ctx, tx, exchange); err != nil {
+			return fmt.Errorf("failed to update exchange: %w", err)
+		}
+
+		if err := s.ledgerService.RecordExchangeTx(ctx, tx, exchange, fromWallet, toWallet); err != nil {
+			return fmt.Errorf("failed to record ledger entry: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil || exchange == nil {
+		return err
+	}
+
+	s.walletRepo.RefreshCache(fromWallet, toWallet)
+
+	user, err := s.userRepo.GetByID(ctx, exchange.UserID)
+	if err == nil {
+		go s.emailService.SendOrderCreatedEmail(user.Email, user.FirstName, exchange)
+	}
+
+	return nil
+}
+
+// closePending moves a pending exchange to status and releases the hold of a limit exchange.
+// check, when set, may veto the change after the exchange is locked.
+func (s *CurrencyExchangeService) closePending(
+	ctx context.Context,
+	exchangeID int64,
+	status domain.CurrencyExchangeStatus,
+	check func(exchange *domain.CurrencyExchange) error,
+) error {
+	var fromWallet *domain.Wallet
+	err := s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		exchange, err := s.exchangeRepo.LockByIDTx(ctx, tx, exchangeID)
+		if err != nil {
+			return err
+		}
+
+		if check != nil {
+			if err := check(exchange); err != nil {
+				return err
+			}
+		}
+
+		if exchange.Status != domain.CurrencyExchangeStatusPending {
+			return fmt.Errorf("can only cancel pending exchanges")
+		}
+
+		if exchange.IsLimit() {
+			if fromWallet, err = s.releaseHoldTx(ctx, tx, exchange); err != nil {
+				return err
+			}
+		}
+
+		exchange.Status = status
+		return s.exchangeRepo.UpdateTx(ctx, tx, exchange)
+	})
+	if err != nil {
+		return err
+	}
+
+	if fromWallet != nil {
+		s.walletRepo.RefreshCache(fromWallet)
+	}
+
+	return nil
+}
+
+// releaseHoldTx returns the from-amount held by a limit exchange to the balance and returns the
+// updated wallet. The exchange must be locked or already closed by tx.
+func (s *CurrencyExchangeService) releaseHoldTx(ctx context.Context, tx *sqlx.Tx, exchange *domain.CurrencyExchange) (*domain.Wallet, error) {
+	wallets, err := s.walletRepo.LockByUserAndCurrencies(ctx, tx, exchange.UserID, exchange.FromCurrencyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock wallet: %w", err)
+	}
+	wallet := wallets[exchange.FromCurrencyID]
+
+	wallet.Locked = wallet.Locked.Sub(exchange.FromAmount)
+	wallet.Balance = wallet.Balance.Add(exchange.FromAmount)
+	if err := s.walletRepo.UpdateBalanceTx(ctx, tx, wallet); err != nil {
+		return nil, fmt.Errorf("failed to release hold: %w", err)
+	}
+
+	if err := s.ledgerService.RecordExchangeReleaseTx(ctx, tx, exchange, wallet); err != nil {
+		return nil, fmt.Errorf("failed to record ledger entry: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// lockQuote locks the quote named by req and checks it can still be executed by this user.
+// Pair and amount in req are optional but must match the quote when given.
+func (s *CurrencyExchangeService) lockQuote(ctx context.Context, tx *sqlx.Tx, userID int64, req *models.CreateExchangeRequest) (*domain.ExchangeQuote, error) {
+	quote, err := s.quoteRepo.LockByUIDTx(ctx, tx, req.QuoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if quote.UserID != userID {
+		return nil, fmt.Errorf("quote not found")
+	}
+
+	if quote.IsExecuted() {
+		return nil, fmt.Errorf("quote has already been executed")
+	}
+
+	if quote.IsExpired(time.Now()) {
+		return nil, fmt.Errorf("quote has expired")
+	}
+
+	if req.FromCurrencyCode != "" || req.ToCurrencyCode != "" || !req.FromAmount.IsZero() {
+		fromCurrency, err := s.walletRepo.GetCurrencyByCode(ctx, req.FromCurrencyCode)
+		if err != nil || fromCurrency.ID != quote.FromCurrencyID {
+			return nil, fmt.Errorf("request does not match quote")
+		}
+		toCurrency, err := s.walletRepo.GetCurrencyByCode(ctx, req.ToCurrencyCode)
+		if err != nil || toCurrency.ID != quote.ToCurrencyID || !req.FromAmount.Equal(quote.FromAmount) {
+			return nil, fmt.Errorf("request does not match quote")
+		}
+	}
+
+	return quote, nil
+}
+
+// price computes an exchange at the current rate and fee schedule. The result is not persisted.
+func (s *CurrencyExchangeService) price(ctx context.Context, userID int64, fromCode, toCode string, fromAmount decimal.Decimal) (*domain.ExchangeQuote, error) {
+	// Get currency information
+	fromCurrency, err := s.walletRepo.GetCurrencyByCode(ctx, fromCode)
+	if err != nil {
+		return nil, fmt.Errorf("from currency not found: %w", err)
+	}
+
+	toCurrency, err := s.walletRepo.GetCurrencyByCode(ctx, toCode)
+	if err != nil {
+		return nil, fmt.Errorf("to currency not found: %w", err)
+	}
+
+	if fromCurrency.ID == toCurrency.ID {
+		return nil, fmt.Errorf("from and to currencies must be different")
+	}
+
 	// Get exchange rate
 	rate, err := s.exchangeRepo.GetExchangeRate(ctx, fromCurrency.ID, toCurrency.ID)
 	if err != nil {
 		return nil, fmt.Errorf("exchange rate not available for this pair: %w", err)
 	}
 
-	// Calculate amounts
-	toAmount := req.FromAmount * rate.Rate
-	toAmountWithFee := toAmount * (100 - rate.Fee) / 100
+	if err := checkPairAmount(rate, fromCurrency, fromAmount); err != nil {
+		return nil, err
+	}
 
-	// Get user's wallets
-	fromWallet, err := s.walletRepo.GetByUserAndCurrency(ctx, userID, fromCurrency.ID)
+	return s.priceAt(ctx, userID, fromCurrency, toCurrency, fromAmount, rate.Rate, rate.Fee)
+}
+
+// checkPairAmount enforces the pair's precision and min/max bounds on the from-amount
+func checkPairAmount(rate *domain.ExchangeRate, fromCurrency *domain.Currency, amount decimal.Decimal) error {
+	// A pair can only be stricter than its currency
+	precision := fromCurrency.Decimals
+	if rate.AmountPrecision != nil {
+		precision = min(precision, *rate.AmountPrecision)
+	}
+	if !amount.Equal(amount.RoundDown(precision)) {
+		return newServiceError(ErrCodeAmountPrecision, "amount exceeds %d decimal places allowed for %s", precision, fromCurrency.Code)
+	}
+
+	if rate.MinAmount != nil && amount.LessThan(*rate.MinAmount) {
+		return newServiceError(ErrCodeAmountBelowMinimum, "amount is below the minimum of %s %s", rate.MinAmount, fromCurrency.Code)
+	}
+
+	if rate.MaxAmount != nil && amount.GreaterThan(*rate.MaxAmount) {
+		return newServiceError(ErrCodeAmountAboveMaximum, "amount is above the maximum of %s %s", rate.MaxAmount, fromCurrency.Code)
+	}
+
+	return nil
+}
+
+// checkDailyLimitTx rejects an exchange that would take the user's volume on the pair today past its daily limit
+func (s *CurrencyExchangeService) checkDailyLimitTx(ctx context.Context, tx *sqlx.Tx, userID int64, fromCurrencyID, toCurrencyID int32, amount decimal.Decimal) error {
+	rate, err := s.exchangeRepo.GetExchangeRate(ctx, fromCurrencyID, toCurrencyID)
 	if err != nil {
-		return nil, fmt.Errorf("from wallet not found: %w", err)
+		return fmt.Errorf("exchange rate not available for this pair: %w", err)
 	}
 
-	toWallet, err := s.walletRepo.GetByUserAndCurrency(ctx, userID, toCurrency.ID)
+	if rate.DailyLimit == nil {
+		return nil
+	}
+
+	volume, err := s.exchangeRepo.GetDailyVolumeTx(ctx, tx, userID, fromCurrencyID, toCurrencyID)
 	if err != nil {
-		return nil, fmt.Errorf("to wallet not found: %w", err)
+		return fmt.Errorf("failed to get daily volume: %w", err)
 	}
 
-	// Check sufficient balance
-	if fromWallet.Balance < req.FromAmount {
-		return nil, fmt.Errorf("insufficient balance")
+	if volume.Add(amount).GreaterThan(*rate.DailyLimit) {
+		remaining := decimal.Max(rate.DailyLimit.Sub(volume), decimal.Zero)
+		return newServiceError(ErrCodeDailyLimitExceeded, "daily limit of %s exceeded, %s remaining today", rate.DailyLimit, remaining)
 	}
 
-	// Perform wallet swap: deduct from fromWallet, credit to toWallet
-	newFromBalance := fromWallet.Balance - req.FromAmount
-	if err := s.walletRepo.UpdateBalance(ctx, fromWallet.ID, newFromBalance, fromWallet.Locked); err != nil {
-		return nil, fmt.Errorf("failed to deduct from wallet: %w", err)
+	return nil
+}
+
+// priceAt computes an exchange at the given rate. defaultFee is the pair's percentage, used when no fee rule matches.
+func (s *CurrencyExchangeService) priceAt(
+	ctx context.Context,
+	userID int64,
+	fromCurrency, toCurrency *domain.Currency,
+	fromAmount, exchangeRate, defaultFee decimal.Decimal,
+) (*domain.ExchangeQuote, error) {
+	// Calculate amounts, truncated to the precision of the currency being credited
+	toAmount := toCurrency.Round(fromAmount.Mul(exchangeRate))
+
+	// Fee rules take precedence over the pair's flat percentage
+	fee, err := s.feeService.ExchangeFee(ctx, userID, fromCurrency, toCurrency, fromAmount, toAmount, defaultFee)
+	if err != nil {
+		return nil, err
 	}
 
-	newToBalance := toWallet.Balance + toAmountWithFee
-	if err := s.walletRepo.UpdateBalance(ctx, toWallet.ID, newToBalance, toWallet.Locked); err != nil {
-		// Rollback the first update
-		s.walletRepo.UpdateBalance(ctx, fromWallet.ID, fromWallet.Balance, fromWallet.Locked)
-		return nil, fmt.Errorf("failed to credit to wallet: %w", err)
+	// Fee keeps reporting a percentage; FeeAmount is what was actually charged
+	feePercent := defaultFee
+	if toAmount.IsPositive() {
+		feePercent = fee.Amount.Mul(hundred).Div(toAmount).Round(2)
 	}
 
-	// Create exchange record
-	exchange := &domain.CurrencyExchange{
-		UID:             uuid.New().String(),
+	return &domain.ExchangeQuote{
 		UserID:          userID,
 		FromCurrencyID:  fromCurrency.ID,
 		ToCurrencyID:    toCurrency.ID,
-		FromAmount:      req.FromAmount,
+		FromAmount:      fromAmount,
 		ToAmount:        toAmount,
-		ToAmountWithFee: toAmountWithFee,
-		Fee:             rate.Fee,
-		ExchangeRate:    rate.Rate,
-		Status:          domain.CurrencyExchangeStatusCompleted,
-	}
+		ToAmountWithFee: toAmount.Sub(fee.Amount),
+		ExchangeRate:    exchangeRate,
+		Fee:             feePercent,
+		FeeAmount:       fee.Amount,
+		FeeRuleID:       fee.RuleID(),
+	}, nil
+}
 
-	if err := s.exchangeRepo.Create(ctx, exchange); err != nil {
-		// Rollback wallet updates
-		s.walletRepo.UpdateBalance(ctx, fromWallet.ID, fromWallet.Balance, fromWallet.Locked)
-		s.walletRepo.UpdateBalance(ctx, toWallet.ID, toWallet.Balance, toWallet.Locked)
-		return nil, fmt.Errorf("failed to create exchange: %w", err)
+// GetQuoteConversionStats reports quote-to-trade conversion per pair for quotes created in [from, to)
+func (s *CurrencyExchangeService) GetQuoteConversionStats(ctx context.Context, from, to time.Time) ([]domain.QuoteConversionStats, error) {
+	stats, err := s.quoteRepo.GetConversionStats(ctx, from, to)
+	if err != nil {
+		return nil, err
 	}
 
-	// Send notification email
-	user, _ := s.userRepo.GetByID(ctx, userID)
-	go s.emailService.SendOrderCreatedEmail(user.Email, user.FirstName, exchange)
+	for i := range stats {
+		if stats[i].Quotes > 0 {
+			stats[i].ConversionRate = float64(stats[i].Executed) / float64(stats[i].Quotes)
+		}
+	}
 
-	return exchange, nil
+	return stats, nil
 }
 
 func (s *CurrencyExchangeService) GetUserExchanges(ctx context.Context, userID int64, limit, offset int) ([]domain.CurrencyExchangeWithCurrencies, error) {
@@ -141,22 +737,14 @@ func (s *CurrencyExchangeService) GetExchangeByID(ctx context.Context, exchangeI
 	return exchange, nil
 }
 
+// CancelExchange cancels a pending limit exchange and releases its hold
 func (s *CurrencyExchangeService) CancelExchange(ctx context.Context, userID, exchangeID int64) error {
-	exchange, err := s.exchangeRepo.GetByID(ctx, exchangeID)
-	if err != nil {
-		return err
-	}
-
-	if exchange.UserID != userID {
-		return fmt.Errorf("unauthorized")
-	}
-
-	if exchange.Status != domain.CurrencyExchangeStatusPending {
-		return fmt.Errorf("can only cancel pending exchanges")
-	}
-
-	exchange.Status = domain.CurrencyExchangeStatusCanceled
-	return s.exchangeRepo.Update(ctx, exchange.ToCurrencyExchange())
+	return s.closePending(ctx, exchangeID, domain.CurrencyExchangeStatusCanceled, func(exchange *domain.CurrencyExchange) error {
+		if exchange.UserID != userID {
+			return fmt.Errorf("unauthorized")
+		}
+		return nil
+	})
 }
 
 func (s *CurrencyExchangeService) GetAllExchanges(ctx context.Context, status, email string, limit, offset int) ([]domain.CurrencyExchangeWithCurrencies, error) {
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/models"
+	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/robfig/cron/v3"
+	"github.com/shopspring/decimal"
+)
+
+// minRecurringInterval is the shortest allowed gap between two runs of a schedule
+const minRecurringInterval = time.Hour
+
+// scheduleCycle is how far past its first run a schedule is searched for runs closer than
+// minRecurringInterval. The minute and hour fields of a cron expression repeat daily and a
+// week covers every day of the week, so the closest runs fall within it.
+const scheduleCycle = 7 * 24 * time.Hour
+
+// RecurringExchangeService manages exchange schedules and executes their runs
+// through CurrencyExchangeService.CreateExchange
+type RecurringExchangeService struct {
+	db              *database.Postgres
+	recurringRepo   *repository.RecurringExchangeRepository
+	walletRepo      *repository.WalletRepository
+	exchangeRepo    *repository.CurrencyExchangeRepository
+	exchangeService *CurrencyExchangeService
+	maxFailures     int
+}
+
+func NewRecurringExchangeService(
+	db *database.Postgres,
+	recurringRepo *repository.RecurringExchangeRepository,
+	walletRepo *repository.WalletRepository,
+	exchangeRepo *repository.CurrencyExchangeRepository,
+	exchangeService *CurrencyExchangeService,
+	maxFailures int,
+) *RecurringExchangeService {
+	return &RecurringExchangeService{
+		db:              db,
+		recurringRepo:   recurringRepo,
+		walletRepo:      walletRepo,
+		exchangeRepo:    exchangeRepo,
+		exchangeService: exchangeService,
+		maxFailures:     maxFailures,
+	}
+}
+
+func (s *RecurringExchangeService) Create(ctx context.Context, userID int64, req *models.CreateRecurringExchangeRequest) (*domain.RecurringExchange, error) {
+	fromCurrency, err := s.walletRepo.GetCurrencyByCode(ctx, req.FromCurrencyCode)
+	if err != nil {
+		return nil, fmt.Errorf("from currency not found: %w", err)
+	}
+
+	toCurrency, err := s.walletRepo.GetCurrencyByCode(ctx, req.ToCurrencyCode)
+	if err != nil {
+		return nil, fmt.Errorf("to currency not found: %w", err)
+	}
+
+	if fromCurrency.ID == toCurrency.ID {
+		return nil, fmt.Errorf("from and to currencies must be different")
+	}
+
+	if _, err := s.exchangeRepo.GetExchangeRate(ctx, fromCurrency.ID, toCurrency.ID); err != nil {
+		return nil, fmt.Errorf("exchange rate not available for this pair: %w", err)
+	}
+
+	rec := &domain.RecurringExchange{
+		UserID:           userID,
+		FromCurrencyID:   fromCurrency.ID,
+		ToCurrencyID:     toCurrency.ID,
+		FromCurrencyCode: fromCurrency.Code,
+		ToCurrencyCode:   toCurrency.Code,
+		Status:           domain.RecurringExchangeStatusActive,
+	}
+
+	if err := s.applySchedule(rec, fromCurrency, req.FromAmount, req.Cron, req.Interval); err != nil {
+		return nil, err
+	}
+
+	if err := s.recurringRepo.Create(ctx, rec); err != nil {
+		return nil, fmt.Errorf("failed to create recurring exchange: %w", err)
+	}
+
+	return rec, nil
+}
+
+func (s *RecurringExchangeService) List(ctx context.Context, userID int64, limit, offset int) ([]domain.RecurringExchange, int64, error) {
+	recs, err := s.recurringRepo.GetByUser(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.recurringRepo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return recs, total, nil
+}
+
+func (s *RecurringExchangeService) Get(ctx context.Context, userID, id int64) (*domain.RecurringExchange, error) {
+	rec, err := s.recurringRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if rec.UserID != userID || rec.Status == domain.RecurringExchangeStatusCanceled {
+		return nil, fmt.Errorf("recurring exchange not found")
+	}
+
+	return rec, nil
+}
+
+func (s *RecurringExchangeService) GetRuns(ctx context.Context, userID, id int64, limit, offset int) ([]domain.RecurringExchangeRun, int64, error) {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return nil, 0, err
+	}
+
+	runs, err := s.recurringRepo.GetRuns(ctx, id, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.recurringRepo.CountRuns(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return runs, total, nil
+}
+
+// Update replaces the amount and schedule. The next run is recomputed from now.
+func (s *RecurringExchangeService) Update(ctx context.Context, userID, id int64, req *models.UpdateRecurringExchangeRequest) (*domain.RecurringExchange, error) {
+	fromCurrency, err := s.getFromCurrency(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.modify(ctx, userID, id, func(rec *domain.RecurringExchange) error {
+		return s.applySchedule(rec, fromCurrency, req.FromAmount, req.Cron, req.Interval)
+	})
+}
+
+func (s *RecurringExchangeService) Pause(ctx context.Context, userID, id int64) (*domain.RecurringExchange, error) {
+	return s.modify(ctx, userID, id, func(rec *domain.RecurringExchange) error {
+		if rec.Status != domain.RecurringExchangeStatusActive {
+			return fmt.Errorf("only active recurring exchanges can be paused")
+		}
+		rec.Status = domain.RecurringExchangeStatusPaused
+		rec.PauseReason = "paused by user"
+		return nil
+	})
+}
+
+// Resume reactivates a paused schedule, clearing its failure count. Runs missed while paused are skipped.
+func (s *RecurringExchangeService) Resume(ctx context.Context, userID, id int64) (*domain.RecurringExchange, error) {
+	return s.modify(ctx, userID, id, func(rec *domain.RecurringExchange) error {
+		if rec.Status != domain.RecurringExchangeStatusPaused {
+			return fmt.Errorf("only paused recurring exchanges can be resumed")
+		}
+
+		next, err := nextRun(rec, time.Now())
+		if err != nil {
+			return err
+		}
+
+		rec.Status = domain.RecurringExchangeStatusActive
+		rec.NextRunAt = next
+		rec.ConsecutiveFailures = 0
+		rec.PauseReason = ""
+		return nil
+	})
+}
+
+func (s *RecurringExchangeService) Cancel(ctx context.Context, userID, id int64) error {
+	_, err := s.modify(ctx, userID, id, func(rec *domain.RecurringExchange) error {
+		rec.Status = domain.RecurringExchangeStatusCanceled
+		return nil
+	})
+	return err
+}
+
+// GetDue returns the IDs of up to limit schedules that are due to run
+func (s *RecurringExchangeService) GetDue(ctx context.Context, limit int) ([]int64, error) {
+	return s.recurringRepo.GetDueIDs(ctx, limit)
+}
+
+// Run executes one due run of a schedule and records its outcome. It returns nil when the
+// schedule is no longer due, e.g. because another worker claimed the run.
+//
+// The run is claimed by advancing next_run_at before the exchange executes, so a crash
+// in between skips the run instead of repeating it.
+func (s *RecurringExchangeService) Run(ctx context.Context, id int64) (*domain.RecurringExchangeRun, error) {
+	var rec *domain.RecurringExchange
+	var scheduledAt time.Time
+	err := s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		rec, err = s.recurringRepo.LockByIDTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if rec.Status != domain.RecurringExchangeStatusActive || rec.NextRunAt.After(now) {
+			rec = nil
+			return nil
+		}
+
+		// Runs missed during downtime are not caught up; the schedule continues from now
+		next, err := nextRun(rec, now)
+		if err != nil {
+			return err
+		}
+
+		scheduledAt = rec.NextRunAt
+		rec.NextRunAt = next
+		rec.LastRunAt = &now
+		return s.recurringRepo.UpdateTx(ctx, tx, rec)
+	})
+	if err != nil || rec == nil {
+		return nil, err
+	}
+
+	exchange, execErr := s.exchangeService.CreateExchange(ctx, rec.UserID, &models.CreateExchangeRequest{
+		FromCurrencyCode: rec.FromCurrencyCode,
+		ToCurrencyCode:   rec.ToCurrencyCode,
+		FromAmount:       rec.FromAmount,
+	})
+
+	run := &domain.RecurringExchangeRun{
+		RecurringExchangeID: rec.ID,
+		Status:              domain.RecurringExchangeRunStatusSucceeded,
+		ScheduledAt:         scheduledAt,
+	}
+	if execErr != nil {
+		run.Status = domain.RecurringExchangeRunStatusFailed
+		run.Error = execErr.Error()
+	} else {
+		run.ExchangeID = &exchange.ID
+	}
+
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		// Re-read so changes the user made while the exchange ran are kept
+		current, err := s.recurringRepo.LockByIDTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := s.recurringRepo.CreateRunTx(ctx, tx, run); err != nil {
+			return fmt.Errorf("failed to record run: %w", err)
+		}
+
+		switch {
+		case execErr == nil:
+			current.ConsecutiveFailures = 0
+		case errors.Is(execErr, ErrInsufficientBalance):
+			current.ConsecutiveFailures++
+			if current.ConsecutiveFailures >= s.maxFailures && current.Status == domain.RecurringExchangeStatusActive {
+				current.Status = domain.RecurringExchangeStatusPaused
+				current.PauseReason = fmt.Sprintf("paused after %d consecutive runs with insufficient balance", current.ConsecutiveFailures)
+			}
+		default:
+			// Other failures, e.g. a disabled pair, are recorded but do not count towards pausing
+			return nil
+		}
+
+		return s.recurringRepo.UpdateTx(ctx, tx, current)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// modify locks the user's schedule, applies fn and saves the result. Canceled schedules cannot be modified.
+func (s *RecurringExchangeService) modify(ctx context.Context, userID, id int64, fn func(rec *domain.RecurringExchange) error) (*domain.RecurringExchange, error) {
+	var rec *domain.RecurringExchange
+	err := s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		rec, err = s.recurringRepo.LockByIDTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if rec.UserID != userID || rec.Status == domain.RecurringExchangeStatusCanceled {
+			return fmt.Errorf("recurring exchange not found")
+		}
+
+		if err := fn(rec); err != nil {
+			return err
+		}
+
+		return s.recurringRepo.UpdateTx(ctx, tx, rec)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+func (s *RecurringExchangeService) getFromCurrency(ctx context.Context, userID, id int64) (*domain.Currency, error) {
+	rec, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.walletRepo.GetCurrencyByCode(ctx, rec.FromCurrencyCode)
+}
+
+// applySchedule validates and sets the amount and schedule, and computes the next run from now
+func (s *RecurringExchangeService) applySchedule(
+	rec *domain.RecurringExchange,
+	fromCurrency *domain.Currency,
+	amount decimal.Decimal,
+	cronExpression, interval string,
+) error {
+	if !fromCurrency.IsValidAmount(amount) {
+		return fmt.Errorf("amount exceeds %d decimal places allowed for %s", fromCurrency.Decimals, fromCurrency.Code)
+	}
+
+	rec.FromAmount = amount
+	rec.CronExpression = nil
+	rec.IntervalSeconds = nil
+
+	if cronExpression != "" {
+		rec.CronExpression = &cronExpression
+	} else {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return fmt.Errorf("invalid interval: %w", err)
+		}
+		seconds := int64(d / time.Second)
+		rec.IntervalSeconds = &seconds
+	}
+
+	now := time.Now()
+	next, err := nextRun(rec, now)
+	if err != nil {
+		return err
+	}
+
+	gap, err := shortestGap(rec, next)
+	if err != nil {
+		return err
+	}
+
+	if gap < minRecurringInterval {
+		return fmt.Errorf("runs must be at least %s apart", minRecurringInterval)
+	}
+
+	rec.NextRunAt = next
+	return nil
+}
+
+// shortestGap returns the smallest gap between consecutive runs of the schedule from first
+// to scheduleCycle later. It stops early at a gap below minRecurringInterval.
+func shortestGap(rec *domain.RecurringExchange, first time.Time) (time.Duration, error) {
+	shortest := scheduleCycle
+	end := first.Add(scheduleCycle)
+	for run := first; run.Before(end) && shortest >= minRecurringInterval; {
+		following, err := nextRun(rec, run)
+		if err != nil {
+			return 0, err
+		}
+
+		shortest = min(shortest, following.Sub(run))
+		run = following
+	}
+
+	return shortest, nil
+}
+
+// nextRun returns the first run of the schedule strictly after after.
+// Cron expressions are evaluated in UTC unless they carry a CRON_TZ= prefix.
+func nextRun(rec *domain.RecurringExchange, after time.Time) (time.Time, error) {
+	if rec.CronExpression != nil {
+		schedule, err := cron.ParseStandard(*rec.CronExpression)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid cron expression: %w", err)
+		}
+
+		next := schedule.Next(after.UTC())
+		if next.IsZero() {
+			return time.Time{}, fmt.Errorf("cron expression never fires")
+		}
+		return next, nil
+	}
+
+	if rec.IntervalSeconds == nil || *rec.IntervalSeconds <= 0 {
+		return time.Time{}, fmt.Errorf("interval must be positive")
+	}
+
+	return after.Add(time.Duration(*rec.IntervalSeconds) * time.Second), nil
+}
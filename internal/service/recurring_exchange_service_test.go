@@ -0,0 +1,77 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+)
+
+func TestShortestGapCoversTheWholeCycle(t *testing.T) {
+	// A Monday
+	monday := time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC)
+	ninety := int64(90 * 60)
+
+	tests := []struct {
+		name  string
+		cron  string
+		every *int64
+		first time.Time
+		want  time.Duration
+	}{
+		{
+			// The next two runs are 23h10m apart, but 09:00 and 09:50 are 50 minutes apart
+			name:  "second minute of the hour first",
+			cron:  "0,50 9 * * *",
+			first: monday.Add(9*time.Hour + 50*time.Minute),
+			want:  50 * time.Minute,
+		},
+		{
+			name:  "hours either side of midnight",
+			cron:  "0 0,23 * * *",
+			first: monday,
+			want:  time.Hour,
+		},
+		{
+			name:  "every six hours",
+			cron:  "0 */6 * * *",
+			first: monday,
+			want:  6 * time.Hour,
+		},
+		{
+			name:  "weekdays from friday",
+			cron:  "0 9 * * 1-5",
+			first: monday.Add(4*24*time.Hour + 9*time.Hour),
+			want:  24 * time.Hour,
+		},
+		{
+			name:  "monthly",
+			cron:  "0 0 1 * *",
+			first: time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+			want:  scheduleCycle,
+		},
+		{
+			name:  "interval",
+			every: &ninety,
+			first: monday,
+			want:  90 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &domain.RecurringExchange{IntervalSeconds: tt.every}
+			if tt.cron != "" {
+				rec.CronExpression = &tt.cron
+			}
+
+			got, err := shortestGap(rec, tt.first)
+			if err != nil {
+				t.Fatalf("shortestGap() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("shortestGap() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/models"
+	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,19}$`)
+
+// RoleService manages back-office roles and checks permissions. Staff can only grant
+// permissions their own role holds, so roles:manage cannot be used to escalate.
+type RoleService struct {
+	db              *database.Postgres
+	roleRepo        *repository.RoleRepository
+	userRepo        *repository.UserRepository
+	adminActionRepo *repository.UserAdminActionRepository
+	auditService    *AuditService
+}
+
+func NewRoleService(
+	db *database.Postgres,
+	roleRepo *repository.RoleRepository,
+	userRepo *repository.UserRepository,
+	adminActionRepo *repository.UserAdminActionRepository,
+	auditService *AuditService,
+) *RoleService {
+	return &RoleService{
+		db:              db,
+		roleRepo:        roleRepo,
+		userRepo:        userRepo,
+		adminActionRepo: adminActionRepo,
+		auditService:    auditService,
+	}
+}
+
+// HasPermission reports whether the user's current role grants permission. The role is read
+// from the user record, so changes apply to tokens that were already issued.
+func (s *RoleService) HasPermission(ctx context.Context, userID int64, permission domain.Permission) (bool, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.IsActive {
+		return false, nil
+	}
+
+	role, err := s.roleRepo.GetByName(ctx, string(user.Role))
+	if err != nil {
+		return false, err
+	}
+
+	return role.Has(permission), nil
+}
+
+func (s *RoleService) ListPermissions() []domain.PermissionInfo {
+	return domain.Permissions
+}
+
+func (s *RoleService) ListRoles(ctx context.Context) ([]domain.Role, error) {
+	return s.roleRepo.GetAll(ctx)
+}
+
+func (s *RoleService) CreateRole(ctx context.Context, meta domain.AuditMeta, req *models.CreateRoleRequest) (*domain.Role, error) {
+	if !roleNamePattern.MatchString(req.Name) {
+		return nil, fmt.Errorf("role name must be lowercase letters, digits and underscores, starting with a letter")
+	}
+
+	role := &domain.Role{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.setPermissions(ctx, meta.ActorID, role, req.Permissions); err != nil {
+		return nil, err
+	}
+
+	err := s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := s.roleRepo.CreateTx(ctx, tx, role); err != nil {
+			return fmt.Errorf("failed to create role: %w", err)
+		}
+
+		return s.auditService.RecordKeyTx(ctx, tx, meta, domain.AuditActionRoleCreate, domain.AuditTargetRole, role.Name, nil, role)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.roleRepo.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (s *RoleService) UpdateRole(ctx context.Context, meta domain.AuditMeta, name string, req *models.UpdateRoleRequest) (*domain.Role, error) {
+	existing, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing.IsSystem {
+		return nil, fmt.Errorf("system roles cannot be changed")
+	}
+
+	actor, err := s.actorRole(ctx, meta.ActorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Covers(existing) {
+		return nil, fmt.Errorf("cannot change a role with permissions you do not have")
+	}
+
+	role := *existing
+	role.Description = req.Description
+	if err := s.setPermissions(ctx, meta.ActorID, &role, req.Permissions); err != nil {
+		return nil, err
+	}
+
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := s.roleRepo.UpdateTx(ctx, tx, &role); err != nil {
+			return err
+		}
+
+		return s.auditService.RecordKeyTx(ctx, tx, meta, domain.AuditActionRoleUpdate, domain.AuditTargetRole, name, existing, &role)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.roleRepo.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// DeleteRole removes a role that no user holds
+func (s *RoleService) DeleteRole(ctx context.Context, meta domain.AuditMeta, name string) error {
+	role, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return fmt.Errorf("system roles cannot be deleted")
+	}
+
+	count, err := s.roleRepo.CountUsers(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to count users: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("role %s is assigned to %d users", name, count)
+	}
+
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := s.roleRepo.DeleteTx(ctx, tx, name); err != nil {
+			return err
+		}
+
+		return s.auditService.RecordKeyTx(ctx, tx, meta, domain.AuditActionRoleDelete, domain.AuditTargetRole, name, role, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.roleRepo.Reload(ctx)
+}
+
+// AssignRole gives the user a role and records reason. Staff cannot change their own role,
+// nor grant or take away permissions they do not have.
+func (s *RoleService) AssignRole(ctx context.Context, meta domain.AuditMeta, userID int64, roleName, reason string) (*domain.User, error) {
+	if meta.ActorID == userID {
+		return nil, fmt.Errorf("you cannot change your own role")
+	}
+
+	role, err := s.roleRepo.GetByName(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	current, err := s.roleRepo.GetByName(ctx, string(user.Role))
+	if err != nil {
+		return nil, err
+	}
+
+	actor, err := s.actorRole(ctx, meta.ActorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Covers(role) || !actor.Covers(current) {
+		return nil, fmt.Errorf("cannot assign or replace a role with permissions you do not have")
+	}
+
+	if user.Role == domain.UserRole(role.Name) {
+		return nil, fmt.Errorf("user already has role %s", role.Name)
+	}
+
+	updated := *user
+	updated.Role = domain.UserRole(role.Name)
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := s.userRepo.UpdateRoleTx(ctx, tx, &updated); err != nil {
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+
+		if err := s.adminActionRepo.CreateTx(ctx, tx, &domain.UserAdminAction{
+			UserID:  userID,
+			AdminID: &meta.ActorID,
+			Action:  domain.UserAdminActionChangeRole,
+			Reason:  reason,
+			Details: fmt.Sprintf("role %s -> %s", user.Role, updated.Role),
+		}); err != nil {
+			return err
+		}
+
+		return s.auditService.RecordTx(ctx, tx, meta, domain.AuditActionUserChangeRole, domain.AuditTargetUser, userID, user, &updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.userRepo.RefreshCache(&updated)
+
+	result := updated
+	result.PasswordHash = ""
+	return &result, nil
+}
+
+// RequireCovers returns an error unless the actor's role holds every permission of roleName,
+// so staff cannot act on accounts more privileged than their own
+func (s *RoleService) RequireCovers(ctx context.Context, actorID int64, roleName domain.UserRole) error {
+	role, err := s.roleRepo.GetByName(ctx, string(roleName))
+	if err != nil {
+		return err
+	}
+
+	actor, err := s.actorRole(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if !actor.Covers(role) {
+		return fmt.Errorf("cannot change a user whose role has permissions you do not have")
+	}
+
+	return nil
+}
+
+// setPermissions validates permissions and sets them on role if the actor holds all of them
+func (s *RoleService) setPermissions(ctx context.Context, actorID int64, role *domain.Role, permissions []string) error {
+	role.Permissions = pq.StringArray{}
+	seen := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		if !domain.IsPermission(domain.Permission(p)) {
+			return fmt.Errorf("unknown permission %s", p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			role.Permissions = append(role.Permissions, p)
+		}
+	}
+
+	actor, err := s.actorRole(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if !actor.Covers(role) {
+		return fmt.Errorf("cannot grant permissions you do not have")
+	}
+
+	return nil
+}
+
+func (s *RoleService) actorRole(ctx context.Context, actorID int64) (*domain.Role, error) {
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return s.roleRepo.GetByName(ctx, string(actor.Role))
+}
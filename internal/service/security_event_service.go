@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/repository"
+)
+
+type SecurityEventService struct {
+	securityEventRepo *repository.SecurityEventRepository
+}
+
+func NewSecurityEventService(securityEventRepo *repository.SecurityEventRepository) *SecurityEventService {
+	return &SecurityEventService{
+		securityEventRepo: securityEventRepo,
+	}
+}
+
+func (s *SecurityEventService) List(ctx context.Context, userID int64, eventType string, limit, offset int) ([]domain.SecurityEvent, int64, error) {
+	events, err := s.securityEventRepo.List(ctx, userID, eventType, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.securityEventRepo.Count(ctx, userID, eventType)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
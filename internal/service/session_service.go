@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+)
+
+// SessionService lists a user's signed-in devices and revokes them. A revoked session's
+// refresh token stops working and its access tokens are rejected on the next request.
+type SessionService struct {
+	db           *database.Postgres
+	userRepo     *repository.UserRepository
+	auditService *AuditService
+}
+
+func NewSessionService(db *database.Postgres, userRepo *repository.UserRepository, auditService *AuditService) *SessionService {
+	return &SessionService{
+		db:           db,
+		userRepo:     userRepo,
+		auditService: auditService,
+	}
+}
+
+// List returns the user's active sessions, most recently used first. currentID marks the
+// session of the caller and is 0 for admins.
+func (s *SessionService) List(ctx context.Context, userID, currentID int64) ([]domain.UserSession, error) {
+	sessions, err := s.userRepo.ListSessionsByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+
+	return sessions, nil
+}
+
+// Revoke revokes one of the user's sessions. meta is nil when users revoke their own sessions;
+// revocations by staff are recorded in the audit log.
+func (s *SessionService) Revoke(ctx context.Context, meta *domain.AuditMeta, userID, sessionID int64) error {
+	sessions, err := s.revoke(ctx, meta, func(tx *sqlx.Tx) ([]domain.UserSession, error) {
+		return s.userRepo.RevokeSessionTx(ctx, tx, userID, sessionID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(sessions) == 0 {
+		return fmt.Errorf("session not found")
+	}
+
+	return nil
+}
+
+// RevokeAll signs the user out everywhere and returns how many sessions were revoked. meta is
+// as for Revoke.
+func (s *SessionService) RevokeAll(ctx context.Context, meta *domain.AuditMeta, userID int64) (int, error) {
+	sessions, err := s.revoke(ctx, meta, func(tx *sqlx.Tx) ([]domain.UserSession, error) {
+		return s.userRepo.RevokeSessionsByUserTx(ctx, tx, userID)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(sessions), nil
+}
+
+func (s *SessionService) revoke(
+	ctx context.Context,
+	meta *domain.AuditMeta,
+	fn func(tx *sqlx.Tx) ([]domain.UserSession, error),
+) ([]domain.UserSession, error) {
+	var sessions []domain.UserSession
+	err := s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		sessions, err = fn(tx)
+		if err != nil || meta == nil {
+			return err
+		}
+
+		for i := range sessions {
+			if err := s.auditService.RecordTx(ctx, tx, *meta, domain.AuditActionSessionRevoke, domain.AuditTargetSession, sessions[i].ID, &sessions[i], nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	s.userRepo.ForgetSessions(sessions)
+	return sessions, nil
+}
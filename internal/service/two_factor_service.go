@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/models"
+	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/caspianex/exchange-backend/pkg/auth"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+)
+
+const recoveryCodeCount = 10
+
+// TwoFactorService handles TOTP enrollment and verification of second factors
+type TwoFactorService struct {
+	db               *database.Postgres
+	userRepo         *repository.UserRepository
+	recoveryCodeRepo *repository.RecoveryCodeRepository
+	issuer           string
+}
+
+func NewTwoFactorService(
+	db *database.Postgres,
+	userRepo *repository.UserRepository,
+	recoveryCodeRepo *repository.RecoveryCodeRepository,
+	issuer string,
+) *TwoFactorService {
+	return &TwoFactorService{
+		db:               db,
+		userRepo:         userRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		issuer:           issuer,
+	}
+}
+
+func (s *TwoFactorService) GetStatus(ctx context.Context, userID int64) (*models.TwoFactorStatusResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	status := &models.TwoFactorStatusResponse{Enabled: user.TOTPEnabled}
+	if user.TOTPEnabled {
+		if status.RecoveryCodesRemaining, err = s.recoveryCodeRepo.CountUnused(ctx, userID); err != nil {
+			return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+		}
+	}
+
+	return status, nil
+}
+
+// Setup generates a new secret. 2FA stays off until Enable confirms a code from it.
+func (s *TwoFactorService) Setup(ctx context.Context, userID int64) (*models.TOTPSetupResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.TOTPEnabled {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	secret, uri, err := auth.GenerateTOTPSecret(s.issuer, user.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	user.TOTPSecret = &secret
+	if err := s.userRepo.SetTOTPSecret(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to store secret: %w", err)
+	}
+
+	return &models.TOTPSetupResponse{Secret: secret, OTPAuthURI: uri}, nil
+}
+
+// Enable confirms the secret from Setup with a code and returns a fresh set of recovery codes
+func (s *TwoFactorService) Enable(ctx context.Context, userID int64, code string) (*models.RecoveryCodesResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.TOTPEnabled {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == nil {
+		return nil, fmt.Errorf("two-factor authentication has not been set up")
+	}
+
+	step, ok := auth.ValidateTOTP(*user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, newServiceError(ErrCodeInvalidTOTP, "invalid two-factor code")
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *user
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := s.userRepo.EnableTOTPTx(ctx, tx, &updated, step); err != nil {
+			return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+		}
+		return s.recoveryCodeRepo.ReplaceTx(ctx, tx, userID, hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.userRepo.RefreshCache(&updated)
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns 2FA off after checking a TOTP or recovery code
+func (s *TwoFactorService) Disable(ctx context.Context, userID int64, code string) error {
+	user, err := s.enabledUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.VerifySecondFactor(ctx, user, code); err != nil {
+		return err
+	}
+
+	updated := *user
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		if err := s.userRepo.DisableTOTPTx(ctx, tx, &updated); err != nil {
+			return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+		}
+		return s.recoveryCodeRepo.DeleteByUserTx(ctx, tx, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.userRepo.RefreshCache(&updated)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a TOTP code
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) (*models.RecoveryCodesResponse, error) {
+	user, err := s.enabledUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.VerifyTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		return s.recoveryCodeRepo.ReplaceTx(ctx, tx, userID, hashes)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// RequireTOTP checks a fresh TOTP code for a sensitive operation. Recovery codes are not accepted.
+func (s *TwoFactorService) RequireTOTP(ctx context.Context, userID int64, code string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !user.TOTPEnabled {
+		return newServiceError(ErrCodeTOTPRequired, "two-factor authentication must be enabled for this operation")
+	}
+	if code == "" {
+		return newServiceError(ErrCodeTOTPRequired, "totp_code is required")
+	}
+
+	return s.VerifyTOTP(ctx, user, code)
+}
+
+// VerifyTOTP accepts a TOTP code once; a code that was already used is rejected
+func (s *TwoFactorService) VerifyTOTP(ctx context.Context, user *domain.User, code string) error {
+	if !user.TOTPEnabled || user.TOTPSecret == nil {
+		return newServiceError(ErrCodeTOTPRequired, "two-factor authentication is not enabled")
+	}
+
+	step, ok := auth.ValidateTOTP(*user.TOTPSecret, code, time.Now())
+	if !ok {
+		return newServiceError(ErrCodeInvalidTOTP, "invalid two-factor code")
+	}
+
+	fresh, err := s.userRepo.UseTOTPStep(ctx, user, step)
+	if err != nil {
+		return fmt.Errorf("failed to verify two-factor code: %w", err)
+	}
+	if !fresh {
+		return newServiceError(ErrCodeInvalidTOTP, "two-factor code has already been used")
+	}
+
+	return nil
+}
+
+// VerifySecondFactor accepts either a TOTP code or an unused recovery code
+func (s *TwoFactorService) VerifySecondFactor(ctx context.Context, user *domain.User, code string) error {
+	if len(code) == 6 {
+		return s.VerifyTOTP(ctx, user, code)
+	}
+
+	used, err := s.recoveryCodeRepo.Use(ctx, user.ID, auth.HashToken(auth.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return fmt.Errorf("failed to verify recovery code: %w", err)
+	}
+	if !used {
+		return newServiceError(ErrCodeInvalidTOTP, "invalid two-factor code")
+	}
+
+	return nil
+}
+
+func (s *TwoFactorService) enabledUser(ctx context.Context, userID int64) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !user.TOTPEnabled {
+		return nil, fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	return user, nil
+}
+
+// newRecoveryCodes returns codes to show the user and the hashes to store
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashToken(auth.NormalizeRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
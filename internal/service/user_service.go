@@ -2,20 +2,54 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/models"
 	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
 )
 
+// userAuditActions maps account actions to the audit log actions they are recorded as
+var userAuditActions = map[domain.UserAdminActionType]domain.AuditAction{
+	domain.UserAdminActionDeactivate:    domain.AuditActionUserDeactivate,
+	domain.UserAdminActionActivate:      domain.AuditActionUserActivate,
+	domain.UserAdminActionChangeRole:    domain.AuditActionUserChangeRole,
+	domain.UserAdminActionUpdateProfile: domain.AuditActionUserUpdateProfile,
+	domain.UserAdminActionMarkVerified:  domain.AuditActionUserMarkVerified,
+	domain.UserAdminActionForceLogout:   domain.AuditActionUserForceLogout,
+	domain.UserAdminActionUnlock:        domain.AuditActionUserUnlock,
+	domain.UserAdminActionSetFeeTier:    domain.AuditActionUserSetFeeTier,
+}
+
 type UserService struct {
-	userRepo   *repository.UserRepository
-	walletRepo *repository.WalletRepository
+	db              *database.Postgres
+	userRepo        *repository.UserRepository
+	walletRepo      *repository.WalletRepository
+	adminActionRepo *repository.UserAdminActionRepository
+	loginFailures   *repository.LoginFailureRepository
+	roleService     *RoleService
+	auditService    *AuditService
 }
 
-func NewUserService(userRepo *repository.UserRepository, walletRepo *repository.WalletRepository) *UserService {
+func NewUserService(
+	db *database.Postgres,
+	userRepo *repository.UserRepository,
+	walletRepo *repository.WalletRepository,
+	adminActionRepo *repository.UserAdminActionRepository,
+	loginFailures *repository.LoginFailureRepository,
+	roleService *RoleService,
+	auditService *AuditService,
+) *UserService {
 	return &UserService{
-		userRepo:   userRepo,
-		walletRepo: walletRepo,
+		db:              db,
+		userRepo:        userRepo,
+		walletRepo:      walletRepo,
+		adminActionRepo: adminActionRepo,
+		loginFailures:   loginFailures,
+		roleService:     roleService,
+		auditService:    auditService,
 	}
 }
 
@@ -72,3 +106,170 @@ func (s *UserService) ListUsers(ctx context.Context, limit, offset int, email st
 func (s *UserService) UpdateUser(ctx context.Context, user *domain.User) error {
 	return s.userRepo.Update(ctx, user)
 }
+
+// SetFeeTier moves the user to another fee tier, which fee rules can match on
+func (s *UserService) SetFeeTier(ctx context.Context, meta domain.AuditMeta, userID int64, req *models.SetFeeTierRequest) (*domain.User, error) {
+	return s.adminAction(ctx, meta, userID, domain.UserAdminActionSetFeeTier, req.Reason,
+		func(tx *sqlx.Tx, user *domain.User) (string, []domain.UserSession, error) {
+			if user.FeeTier == req.FeeTier {
+				return "", nil, fmt.Errorf("user is already in fee tier %s", req.FeeTier)
+			}
+
+			details := fmt.Sprintf("fee tier %s -> %s", user.FeeTier, req.FeeTier)
+			user.FeeTier = req.FeeTier
+			return details, nil, s.userRepo.UpdateFeeTierTx(ctx, tx, user)
+		})
+}
+
+// UpdateProfile changes the user's names
+func (s *UserService) UpdateProfile(ctx context.Context, meta domain.AuditMeta, userID int64, req *models.AdminUpdateUserRequest) (*domain.User, error) {
+	return s.adminAction(ctx, meta, userID, domain.UserAdminActionUpdateProfile, req.Reason,
+		func(tx *sqlx.Tx, user *domain.User) (string, []domain.UserSession, error) {
+			details := fmt.Sprintf("name %s %s -> %s %s", user.FirstName, user.LastName, req.FirstName, req.LastName)
+			user.FirstName = req.FirstName
+			user.LastName = req.LastName
+			return details, nil, s.userRepo.UpdateTx(ctx, tx, user)
+		})
+}
+
+// Deactivate blocks the account and revokes its sessions, so its access tokens are rejected
+// on the next request
+func (s *UserService) Deactivate(ctx context.Context, meta domain.AuditMeta, userID int64, reason string) (*domain.User, error) {
+	if meta.ActorID == userID {
+		return nil, fmt.Errorf("you cannot deactivate your own account")
+	}
+
+	return s.adminAction(ctx, meta, userID, domain.UserAdminActionDeactivate, reason,
+		func(tx *sqlx.Tx, user *domain.User) (string, []domain.UserSession, error) {
+			if !user.IsActive {
+				return "", nil, fmt.Errorf("user is already deactivated")
+			}
+
+			user.IsActive = false
+			if err := s.userRepo.UpdateTx(ctx, tx, user); err != nil {
+				return "", nil, err
+			}
+
+			sessions, err := s.userRepo.RevokeSessionsByUserTx(ctx, tx, user.ID)
+			return fmt.Sprintf("%d sessions revoked", len(sessions)), sessions, err
+		})
+}
+
+func (s *UserService) Activate(ctx context.Context, meta domain.AuditMeta, userID int64, reason string) (*domain.User, error) {
+	return s.adminAction(ctx, meta, userID, domain.UserAdminActionActivate, reason,
+		func(tx *sqlx.Tx, user *domain.User) (string, []domain.UserSession, error) {
+			if user.IsActive {
+				return "", nil, fmt.Errorf("user is already active")
+			}
+
+			user.IsActive = true
+			return "", nil, s.userRepo.UpdateTx(ctx, tx, user)
+		})
+}
+
+// MarkVerified marks the email address verified without the emailed link
+func (s *UserService) MarkVerified(ctx context.Context, meta domain.AuditMeta, userID int64, reason string) (*domain.User, error) {
+	return s.adminAction(ctx, meta, userID, domain.UserAdminActionMarkVerified, reason,
+		func(tx *sqlx.Tx, user *domain.User) (string, []domain.UserSession, error) {
+			if user.IsVerified {
+				return "", nil, fmt.Errorf("user is already verified")
+			}
+
+			return "", nil, s.userRepo.MarkVerifiedTx(ctx, tx, user)
+		})
+}
+
+// ForceLogout revokes all of the user's sessions
+func (s *UserService) ForceLogout(ctx context.Context, meta domain.AuditMeta, userID int64, reason string) (*domain.User, error) {
+	return s.adminAction(ctx, meta, userID, domain.UserAdminActionForceLogout, reason,
+		func(tx *sqlx.Tx, user *domain.User) (string, []domain.UserSession, error) {
+			sessions, err := s.userRepo.RevokeSessionsByUserTx(ctx, tx, user.ID)
+			return fmt.Sprintf("%d sessions revoked", len(sessions)), sessions, err
+		})
+}
+
+// Unlock clears the failed sign-ins counted against the user's email, lifting any lockout.
+// Failures counted against client IPs are left alone.
+func (s *UserService) Unlock(ctx context.Context, meta domain.AuditMeta, userID int64, reason string) (*domain.User, error) {
+	return s.adminAction(ctx, meta, userID, domain.UserAdminActionUnlock, reason,
+		func(tx *sqlx.Tx, user *domain.User) (string, []domain.UserSession, error) {
+			cleared, err := s.loginFailures.DeleteTx(ctx, tx, domain.LoginFailureScopeAccount, normalizeLoginEmail(user.Email))
+			if err != nil {
+				return "", nil, err
+			}
+			if !cleared {
+				return "", nil, fmt.Errorf("user has no failed logins to clear")
+			}
+			return "", nil, nil
+		})
+}
+
+func (s *UserService) ListAdminActions(ctx context.Context, userID int64, limit, offset int) ([]domain.UserAdminAction, int64, error) {
+	actions, err := s.adminActionRepo.ListByUser(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.adminActionRepo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return actions, total, nil
+}
+
+// adminAction locks the user, applies change to a copy and records it with reason, and in the
+// audit log, in one transaction. Staff can only act on users whose role they cover. change
+// returns details for the record and any sessions it revoked.
+func (s *UserService) adminAction(
+	ctx context.Context,
+	meta domain.AuditMeta,
+	userID int64,
+	action domain.UserAdminActionType,
+	reason string,
+	change func(tx *sqlx.Tx, user *domain.User) (string, []domain.UserSession, error),
+) (*domain.User, error) {
+	var updated domain.User
+	var sessions []domain.UserSession
+	err := s.db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		// Locked so concurrent actions each apply their change to the other's result
+		user, err := s.userRepo.LockByIDTx(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.roleService.RequireCovers(ctx, meta.ActorID, user.Role); err != nil {
+			return err
+		}
+
+		// Copy so the cached user only changes once the transaction commits
+		updated = *user
+		details, revoked, err := change(tx, &updated)
+		if err != nil {
+			return err
+		}
+		sessions = revoked
+
+		if err := s.adminActionRepo.CreateTx(ctx, tx, &domain.UserAdminAction{
+			UserID:  userID,
+			AdminID: &meta.ActorID,
+			Action:  action,
+			Reason:  reason,
+			Details: details,
+		}); err != nil {
+			return err
+		}
+
+		return s.auditService.RecordTx(ctx, tx, meta, userAuditActions[action], domain.AuditTargetUser, userID, user, &updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.userRepo.RefreshCache(&updated)
+	s.userRepo.ForgetSessions(sessions)
+
+	result := updated
+	result.PasswordHash = ""
+	return &result, nil
+}
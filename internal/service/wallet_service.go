@@ -3,24 +3,53 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/caspianex/exchange-backend/internal/domain"
 	"github.com/caspianex/exchange-backend/internal/models"
 	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
 )
 
 type WalletService struct {
-	walletRepo *repository.WalletRepository
-	txRepo     *repository.TransactionRepository
+	db                 *database.Postgres
+	walletRepo         *repository.WalletRepository
+	txRepo             *repository.TransactionRepository
+	depositAddressRepo *repository.DepositAddressRepository
+	addressProvider    AddressProvider
+	ledgerService      *LedgerService
+	feeService         *FeeService
+	kycService         *KYCService
+	twoFactor          *TwoFactorService
+	auditService       *AuditService
 }
 
 func NewWalletService(
+	db *database.Postgres,
 	walletRepo *repository.WalletRepository,
 	txRepo *repository.TransactionRepository,
+	depositAddressRepo *repository.DepositAddressRepository,
+	addressProvider AddressProvider,
+	ledgerService *LedgerService,
+	feeService *FeeService,
+	kycService *KYCService,
+	twoFactor *TwoFactorService,
+	auditService *AuditService,
 ) *WalletService {
 	return &WalletService{
-		walletRepo: walletRepo,
-		txRepo:     txRepo,
+		db:                 db,
+		walletRepo:         walletRepo,
+		txRepo:             txRepo,
+		depositAddressRepo: depositAddressRepo,
+		addressProvider:    addressProvider,
+		ledgerService:      ledgerService,
+		feeService:         feeService,
+		kycService:         kycService,
+		twoFactor:          twoFactor,
+		auditService:       auditService,
 	}
 }
 
@@ -28,83 +57,319 @@ func (s *WalletService) GetUserWallets(ctx context.Context, userID int64) ([]dom
 	return s.walletRepo.GetUserWallets(ctx, userID)
 }
 
-func (s *WalletService) Deposit(ctx context.Context, userID int64, req *models.DepositRequest) (*domain.Transaction, error) {
-	currency, err := s.walletRepo.GetCurrencyByCode(ctx, req.CurrencyCode)
+// GetDepositAddress returns the user's deposit address for a crypto currency, allocating one on first use
+func (s *WalletService) GetDepositAddress(ctx context.Context, userID int64, currencyCode string) (*domain.DepositAddress, error) {
+	currency, err := s.walletRepo.GetCurrencyByCode(ctx, currencyCode)
 	if err != nil {
 		return nil, fmt.Errorf("currency not found: %w", err)
 	}
 
-	wallet, err := s.walletRepo.GetByUserAndCurrency(ctx, userID, currency.ID)
+	if !currency.IsCrypto {
+		return nil, fmt.Errorf("%s deposits do not use addresses", currency.Code)
+	}
+
+	var address *domain.DepositAddress
+	err = s.db.WithTx(ctx, func(sqlTx *sqlx.Tx) error {
+		// Locking the wallet keeps two first requests from allocating two addresses
+		if _, err := s.walletRepo.LockByUserAndCurrencies(ctx, sqlTx, userID, currency.ID); err != nil {
+			return fmt.Errorf("failed to find wallet: %w", err)
+		}
 
+		address, err = s.depositAddressRepo.GetByUserAndCurrencyTx(ctx, sqlTx, userID, currency.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get deposit address: %w", err)
+		}
+		if address != nil {
+			return nil
+		}
+
+		address, err = s.addressProvider.Allocate(ctx, sqlTx, userID, currency)
+		if err != nil {
+			return fmt.Errorf("failed to allocate deposit address: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to find wallet: %w", err)
+		return nil, err
 	}
 
-	tx := &domain.Transaction{
-		UserID:   userID,
-		WalletID: wallet.ID,
-		Type:     domain.TransactionTypeDeposit,
-		Amount:   req.Amount,
-		Fee:      0,
-		Status:   domain.TransactionStatusPending,
-		TxHash:   req.TxHash,
+	return address, nil
+}
+
+// ImportDepositAddresses adds pre-generated addresses to the allocation pool.
+// Returns how many were new and how many free addresses the currency now has.
+func (s *WalletService) ImportDepositAddresses(ctx context.Context, req *models.ImportDepositAddressesRequest) (int64, int64, error) {
+	currency, err := s.walletRepo.GetCurrencyByCode(ctx, req.CurrencyCode)
+	if err != nil {
+		return 0, 0, fmt.Errorf("currency not found: %w", err)
+	}
+
+	if !currency.IsCrypto {
+		return 0, 0, fmt.Errorf("%s deposits do not use addresses", currency.Code)
+	}
+
+	added, err := s.depositAddressRepo.AddToPool(ctx, currency, req.Addresses)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	free, err := s.depositAddressRepo.CountFree(ctx, currency.ID)
+	if err != nil {
+		return 0, 0, err
 	}
 
-	if err := s.txRepo.Create(ctx, tx); err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	return added, free, nil
+}
+
+// ManualDeposit credits a wallet on an administrator's say-so, e.g. for a bank transfer, and
+// records it in the audit log. Crypto deposits are credited only by the chain watcher.
+func (s *WalletService) ManualDeposit(ctx context.Context, meta domain.AuditMeta, req *models.AdminDepositRequest) (*domain.Transaction, error) {
+	currency, err := s.walletRepo.GetCurrencyByCode(ctx, req.CurrencyCode)
+	if err != nil {
+		return nil, fmt.Errorf("currency not found: %w", err)
 	}
 
-	newBalance := wallet.Balance + req.Amount
-	if err := s.walletRepo.UpdateBalance(ctx, wallet.ID, newBalance, wallet.Locked); err != nil {
-		return nil, fmt.Errorf("failed to update balance: %w", err)
+	if !currency.IsValidAmount(req.Amount) {
+		return nil, fmt.Errorf("amount exceeds %d decimal places allowed for %s", currency.Decimals, currency.Code)
 	}
 
-	tx.Status = domain.TransactionStatusCompleted
-	if err := s.txRepo.Update(ctx, tx); err != nil {
+	var wallet *domain.Wallet
+	var tx *domain.Transaction
+	err = s.db.WithTx(ctx, func(sqlTx *sqlx.Tx) error {
+		wallets, err := s.walletRepo.LockByUserAndCurrencies(ctx, sqlTx, req.UserID, currency.ID)
+		if err != nil {
+			return fmt.Errorf("failed to find wallet: %w", err)
+		}
+		wallet = wallets[currency.ID]
+
+		tx = &domain.Transaction{
+			UserID:      req.UserID,
+			WalletID:    wallet.ID,
+			Type:        domain.TransactionTypeDeposit,
+			Amount:      req.Amount,
+			Fee:         decimal.Zero,
+			Status:      domain.TransactionStatusCompleted,
+			TxHash:      req.TxHash,
+			Description: req.Description,
+		}
+
+		if err := s.txRepo.CreateTx(ctx, sqlTx, tx); err != nil {
+			return fmt.Errorf("failed to create transaction: %w", err)
+		}
+
+		wallet.Balance = wallet.Balance.Add(req.Amount)
+		if err := s.walletRepo.UpdateBalanceTx(ctx, sqlTx, wallet); err != nil {
+			return fmt.Errorf("failed to update balance: %w", err)
+		}
+
+		if err := s.ledgerService.RecordDepositTx(ctx, sqlTx, wallet, tx); err != nil {
+			return fmt.Errorf("failed to record ledger entry: %w", err)
+		}
+
+		return s.auditService.RecordTx(ctx, sqlTx, meta, domain.AuditActionManualDeposit, domain.AuditTargetTransaction, tx.ID, nil, tx)
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	s.walletRepo.RefreshCache(wallet)
+
 	return tx, nil
 }
 
+// Withdraw creates a pending withdrawal request and moves the amount plus fee into Wallet.Locked
+// until an admin rejects it or marks it sent
 func (s *WalletService) Withdraw(ctx context.Context, userID int64, req *models.WithdrawRequest) (*domain.Transaction, error) {
 	currency, err := s.walletRepo.GetCurrencyByCode(ctx, req.CurrencyCode)
 	if err != nil {
 		return nil, fmt.Errorf("currency not found: %w", err)
 	}
 
-	wallet, err := s.walletRepo.GetByUserAndCurrency(ctx, userID, currency.ID)
-	if err != nil {
-		return nil, fmt.Errorf("wallet not found: %w", err)
+	if !currency.IsValidAmount(req.Amount) {
+		return nil, fmt.Errorf("amount exceeds %d decimal places allowed for %s", currency.Decimals, currency.Code)
 	}
 
-	if wallet.Balance < req.Amount {
-		return nil, fmt.Errorf("insufficient balance")
+	if req.Network != "" && req.Network != currency.Network {
+		return nil, fmt.Errorf("%s cannot be withdrawn on network %s", currency.Code, req.Network)
 	}
 
-	tx := &domain.Transaction{
-		UserID:   userID,
-		WalletID: wallet.ID,
-		Type:     domain.TransactionTypeWithdrawal,
-		Amount:   req.Amount,
-		Fee:      req.Amount * 0.001,
-		Status:   domain.TransactionStatusPending,
+	// Checked last among the request checks so a rejected request does not burn the code
+	if err := s.twoFactor.RequireTOTP(ctx, userID, req.TOTPCode); err != nil {
+		return nil, err
 	}
 
-	if err := s.txRepo.Create(ctx, tx); err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	fee, err := s.feeService.WithdrawalFee(ctx, userID, currency, req.Amount)
+	if err != nil {
+		return nil, err
 	}
 
-	newBalance := wallet.Balance - req.Amount - tx.Fee
-	if err := s.walletRepo.UpdateBalance(ctx, wallet.ID, newBalance, wallet.Locked); err != nil {
-		return nil, fmt.Errorf("failed to update balance: %w", err)
+	var wallet *domain.Wallet
+	var tx *domain.Transaction
+	err = s.db.WithTx(ctx, func(sqlTx *sqlx.Tx) error {
+		wallets, err := s.walletRepo.LockByUserAndCurrencies(ctx, sqlTx, userID, currency.ID)
+		if err != nil {
+			return fmt.Errorf("wallet not found: %w", err)
+		}
+		wallet = wallets[currency.ID]
+
+		tx = &domain.Transaction{
+			UserID:    userID,
+			WalletID:  wallet.ID,
+			Type:      domain.TransactionTypeWithdrawal,
+			Amount:    req.Amount,
+			Fee:       fee.Amount,
+			FeeRuleID: fee.RuleID(),
+			Status:    domain.TransactionStatusPending,
+			Address:   req.Address,
+			Network:   currency.Network,
+		}
+
+		held := tx.HeldAmount()
+		if wallet.Balance.LessThan(held) {
+			return ErrInsufficientBalance
+		}
+
+		if err := s.kycService.CheckWithdrawalLimitTx(ctx, sqlTx, userID, wallet, tx.Amount); err != nil {
+			return err
+		}
+
+		if err := s.txRepo.CreateTx(ctx, sqlTx, tx); err != nil {
+			return fmt.Errorf("failed to create transaction: %w", err)
+		}
+
+		wallet.Balance = wallet.Balance.Sub(held)
+		wallet.Locked = wallet.Locked.Add(held)
+		if err := s.walletRepo.UpdateBalanceTx(ctx, sqlTx, wallet); err != nil {
+			return fmt.Errorf("failed to update balance: %w", err)
+		}
+
+		if err := s.ledgerService.RecordWithdrawalHoldTx(ctx, sqlTx, wallet, tx); err != nil {
+			return fmt.Errorf("failed to record ledger entry: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	tx.Status = domain.TransactionStatusCompleted
-	if err := s.txRepo.Update(ctx, tx); err != nil {
+	s.walletRepo.RefreshCache(wallet)
+
+	return tx, nil
+}
+
+func (s *WalletService) GetWithdrawals(ctx context.Context, status string, limit, offset int) ([]domain.Transaction, error) {
+	return s.txRepo.GetTransactionsByType(ctx, domain.TransactionTypeWithdrawal, status, limit, offset)
+}
+
+func (s *WalletService) GetWithdrawalsCount(ctx context.Context, status string) (int64, error) {
+	return s.txRepo.GetTransactionsByTypeCount(ctx, domain.TransactionTypeWithdrawal, status)
+}
+
+// ApproveWithdrawal clears a pending withdrawal for sending. Funds stay locked.
+func (s *WalletService) ApproveWithdrawal(ctx context.Context, meta domain.AuditMeta, txID int64) (*domain.Transaction, error) {
+	return s.reviewWithdrawal(ctx, meta, domain.AuditActionWithdrawalApprove, txID,
+		[]domain.TransactionStatus{domain.TransactionStatusPending},
+		func(sqlTx *sqlx.Tx, tx *domain.Transaction, wallet *domain.Wallet) error {
+			tx.Status = domain.TransactionStatusApproved
+			return nil
+		})
+}
+
+// RejectWithdrawal cancels a withdrawal that has not been sent and returns the held funds to the balance
+func (s *WalletService) RejectWithdrawal(ctx context.Context, meta domain.AuditMeta, txID int64, reason string) (*domain.Transaction, error) {
+	return s.reviewWithdrawal(ctx, meta, domain.AuditActionWithdrawalReject, txID,
+		[]domain.TransactionStatus{domain.TransactionStatusPending, domain.TransactionStatusApproved},
+		func(sqlTx *sqlx.Tx, tx *domain.Transaction, wallet *domain.Wallet) error {
+			held := tx.HeldAmount()
+			wallet.Locked = wallet.Locked.Sub(held)
+			wallet.Balance = wallet.Balance.Add(held)
+			if err := s.walletRepo.UpdateBalanceTx(ctx, sqlTx, wallet); err != nil {
+				return fmt.Errorf("failed to update balance: %w", err)
+			}
+
+			if err := s.ledgerService.RecordWithdrawalReleaseTx(ctx, sqlTx, wallet, tx); err != nil {
+				return fmt.Errorf("failed to record ledger entry: %w", err)
+			}
+
+			tx.Status = domain.TransactionStatusRejected
+			tx.RejectReason = reason
+			return nil
+		})
+}
+
+// MarkWithdrawalSent records the on-chain hash of an approved withdrawal and settles the held funds
+func (s *WalletService) MarkWithdrawalSent(ctx context.Context, meta domain.AuditMeta, txID int64, txHash string) (*domain.Transaction, error) {
+	return s.reviewWithdrawal(ctx, meta, domain.AuditActionWithdrawalSent, txID,
+		[]domain.TransactionStatus{domain.TransactionStatusApproved},
+		func(sqlTx *sqlx.Tx, tx *domain.Transaction, wallet *domain.Wallet) error {
+			wallet.Locked = wallet.Locked.Sub(tx.HeldAmount())
+			if err := s.walletRepo.UpdateBalanceTx(ctx, sqlTx, wallet); err != nil {
+				return fmt.Errorf("failed to update balance: %w", err)
+			}
+
+			if err := s.ledgerService.RecordWithdrawalTx(ctx, sqlTx, wallet, tx); err != nil {
+				return fmt.Errorf("failed to record ledger entry: %w", err)
+			}
+
+			tx.Status = domain.TransactionStatusCompleted
+			tx.TxHash = txHash
+			return nil
+		})
+}
+
+// reviewWithdrawal locks the withdrawal and its wallet, checks the current status is one of from,
+// applies the transition, stamps the reviewing admin and records action in the audit log
+func (s *WalletService) reviewWithdrawal(
+	ctx context.Context,
+	meta domain.AuditMeta,
+	action domain.AuditAction,
+	txID int64,
+	from []domain.TransactionStatus,
+	apply func(sqlTx *sqlx.Tx, tx *domain.Transaction, wallet *domain.Wallet) error,
+) (*domain.Transaction, error) {
+	var wallet *domain.Wallet
+	var tx *domain.Transaction
+	err := s.db.WithTx(ctx, func(sqlTx *sqlx.Tx) error {
+		var err error
+		tx, err = s.txRepo.LockByIDTx(ctx, sqlTx, txID)
+		if err != nil {
+			return err
+		}
+
+		if tx.Type != domain.TransactionTypeWithdrawal {
+			return fmt.Errorf("transaction is not a withdrawal")
+		}
+
+		if !slices.Contains(from, tx.Status) {
+			return fmt.Errorf("withdrawal cannot be changed in status %s", tx.Status)
+		}
+
+		wallet, err = s.walletRepo.LockByIDTx(ctx, sqlTx, tx.WalletID)
+		if err != nil {
+			return err
+		}
+
+		before := *tx
+		if err := apply(sqlTx, tx, wallet); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		tx.ReviewedBy = &meta.ActorID
+		tx.ReviewedAt = &now
+		if err := s.txRepo.UpdateReviewTx(ctx, sqlTx, tx); err != nil {
+			return fmt.Errorf("failed to update transaction: %w", err)
+		}
+
+		return s.auditService.RecordTx(ctx, sqlTx, meta, action, domain.AuditTargetTransaction, tx.ID, &before, tx)
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	s.walletRepo.RefreshCache(wallet)
+
 	return tx, nil
 }
 
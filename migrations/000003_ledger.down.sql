@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS ledger_postings;
+DROP TABLE IF EXISTS ledger_journals;
+DROP FUNCTION IF EXISTS ledger_check_journal_balanced();
+DROP FUNCTION IF EXISTS ledger_forbid_mutation();
@@ -0,0 +1,87 @@
+-- Create ledger_journals table (one row per business event that moves money)
+CREATE TABLE IF NOT EXISTS ledger_journals (
+    id BIGSERIAL PRIMARY KEY,
+    uid VARCHAR(255) UNIQUE NOT NULL,
+    type VARCHAR(30) NOT NULL,
+    reference_type VARCHAR(30) NOT NULL,
+    reference_id BIGINT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ledger_journals_type ON ledger_journals(type);
+CREATE INDEX idx_ledger_journals_reference ON ledger_journals(reference_type, reference_id);
+CREATE INDEX idx_ledger_journals_created ON ledger_journals(created_at);
+
+-- Create ledger_postings table (double-entry legs, signed amounts)
+CREATE TABLE IF NOT EXISTS ledger_postings (
+    id BIGSERIAL PRIMARY KEY,
+    journal_id BIGINT NOT NULL REFERENCES ledger_journals(id),
+    account VARCHAR(100) NOT NULL,
+    wallet_id BIGINT REFERENCES wallets(id),
+    currency_id BIGINT NOT NULL REFERENCES currencies(id),
+    amount DECIMAL(20, 8) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ledger_postings_journal_id ON ledger_postings(journal_id);
+CREATE INDEX idx_ledger_postings_account ON ledger_postings(account);
+CREATE INDEX idx_ledger_postings_wallet_id ON ledger_postings(wallet_id);
+
+-- Every journal must net to zero per currency. Checked at commit so all legs
+-- of a journal can be inserted before the check runs.
+CREATE OR REPLACE FUNCTION ledger_check_journal_balanced() RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM ledger_postings
+        WHERE journal_id = NEW.journal_id
+        GROUP BY currency_id
+        HAVING SUM(amount) <> 0
+    ) THEN
+        RAISE EXCEPTION 'ledger journal % is not balanced', NEW.journal_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER trg_ledger_postings_balanced
+    AFTER INSERT ON ledger_postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_check_journal_balanced();
+
+-- Ledger rows are append-only
+CREATE OR REPLACE FUNCTION ledger_forbid_mutation() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger tables are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_ledger_journals_immutable
+    BEFORE UPDATE OR DELETE ON ledger_journals
+    FOR EACH ROW EXECUTE FUNCTION ledger_forbid_mutation();
+
+CREATE TRIGGER trg_ledger_postings_immutable
+    BEFORE UPDATE OR DELETE ON ledger_postings
+    FOR EACH ROW EXECUTE FUNCTION ledger_forbid_mutation();
+
+-- Seed opening balances for wallets that already hold funds
+INSERT INTO ledger_journals (uid, type, reference_type, reference_id, description)
+SELECT 'opening-balance-' || w.id, 'opening_balance', 'wallet', w.id, 'Opening balance migrated from wallets table'
+FROM wallets w
+WHERE w.balance <> 0 OR w.locked <> 0;
+
+INSERT INTO ledger_postings (journal_id, account, wallet_id, currency_id, amount)
+SELECT j.id, 'wallet:' || w.id, w.id, w.currency_id, w.balance
+FROM ledger_journals j
+JOIN wallets w ON j.reference_type = 'wallet' AND j.reference_id = w.id
+WHERE j.type = 'opening_balance' AND w.balance <> 0
+UNION ALL
+SELECT j.id, 'wallet:' || w.id || ':locked', w.id, w.currency_id, w.locked
+FROM ledger_journals j
+JOIN wallets w ON j.reference_type = 'wallet' AND j.reference_id = w.id
+WHERE j.type = 'opening_balance' AND w.locked <> 0
+UNION ALL
+SELECT j.id, 'system:opening_balance', NULL, w.currency_id, -(w.balance + w.locked)
+FROM ledger_journals j
+JOIN wallets w ON j.reference_type = 'wallet' AND j.reference_id = w.id
+WHERE j.type = 'opening_balance';
@@ -0,0 +1,2 @@
+ALTER TABLE transactions ALTER COLUMN fee TYPE DECIMAL(5, 2);
+ALTER TABLE currencies DROP COLUMN IF EXISTS decimals;
@@ -0,0 +1,9 @@
+-- Per-currency precision used to round amounts explicitly
+ALTER TABLE currencies ADD COLUMN decimals SMALLINT NOT NULL DEFAULT 8 CHECK (decimals BETWEEN 0 AND 8);
+
+UPDATE currencies SET decimals = 8 WHERE code IN ('BTC', 'ETH', 'BNB', 'SOL', 'DOGE');
+UPDATE currencies SET decimals = 6 WHERE code IN ('USDT', 'XRP', 'ADA');
+UPDATE currencies SET decimals = 2 WHERE code IN ('KZT', 'USD', 'EUR');
+
+-- Withdrawal fees are amounts, not percentages; DECIMAL(5, 2) truncated them
+ALTER TABLE transactions ALTER COLUMN fee TYPE DECIMAL(20, 8);
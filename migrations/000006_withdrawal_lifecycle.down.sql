@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_transactions_type_status;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS reject_reason;
+ALTER TABLE transactions DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS reviewed_by;
+ALTER TABLE transactions DROP COLUMN IF EXISTS network;
+ALTER TABLE transactions DROP COLUMN IF EXISTS address;
+
+ALTER TABLE currencies DROP COLUMN IF EXISTS network;
@@ -0,0 +1,20 @@
+-- Blockchain network a crypto currency is withdrawn on; empty for fiat
+ALTER TABLE currencies ADD COLUMN network VARCHAR(30) NOT NULL DEFAULT '';
+
+UPDATE currencies SET network = 'bitcoin' WHERE code = 'BTC';
+UPDATE currencies SET network = 'ethereum' WHERE code = 'ETH';
+UPDATE currencies SET network = 'tron' WHERE code = 'USDT';
+UPDATE currencies SET network = 'bsc' WHERE code = 'BNB';
+UPDATE currencies SET network = 'solana' WHERE code = 'SOL';
+UPDATE currencies SET network = 'ripple' WHERE code = 'XRP';
+UPDATE currencies SET network = 'cardano' WHERE code = 'ADA';
+UPDATE currencies SET network = 'dogecoin' WHERE code = 'DOGE';
+
+-- Withdrawal destination and admin review trail
+ALTER TABLE transactions ADD COLUMN address VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN network VARCHAR(30) NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN reviewed_by BIGINT REFERENCES users(id);
+ALTER TABLE transactions ADD COLUMN reviewed_at TIMESTAMP;
+ALTER TABLE transactions ADD COLUMN reject_reason TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_transactions_type_status ON transactions(type, status);
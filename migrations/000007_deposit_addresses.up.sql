@@ -0,0 +1,14 @@
+-- Create deposit_addresses table (pre-generated pool, assigned to one user per currency)
+CREATE TABLE IF NOT EXISTS deposit_addresses (
+    id BIGSERIAL PRIMARY KEY,
+    currency_id BIGINT NOT NULL REFERENCES currencies(id),
+    network VARCHAR(30) NOT NULL,
+    address VARCHAR(255) NOT NULL,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    allocated_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(network, address)
+);
+
+CREATE UNIQUE INDEX idx_deposit_addresses_user_currency ON deposit_addresses(user_id, currency_id) WHERE user_id IS NOT NULL;
+CREATE INDEX idx_deposit_addresses_free ON deposit_addresses(currency_id, id) WHERE user_id IS NULL;
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS chain_cursors;
+
+DROP INDEX IF EXISTS idx_transactions_pending_chain;
+DROP INDEX IF EXISTS idx_transactions_network_tx_hash;
+
+ALTER TABLE transactions ALTER COLUMN tx_hash DROP NOT NULL;
+ALTER TABLE transactions ALTER COLUMN tx_hash DROP DEFAULT;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS confirmations;
+ALTER TABLE transactions DROP COLUMN IF EXISTS block_height;
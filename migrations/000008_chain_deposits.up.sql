@@ -0,0 +1,19 @@
+-- On-chain position of a deposit while it gathers confirmations
+ALTER TABLE transactions ADD COLUMN block_height BIGINT;
+ALTER TABLE transactions ADD COLUMN confirmations INTEGER NOT NULL DEFAULT 0;
+
+UPDATE transactions SET tx_hash = '' WHERE tx_hash IS NULL;
+ALTER TABLE transactions ALTER COLUMN tx_hash SET DEFAULT '';
+ALTER TABLE transactions ALTER COLUMN tx_hash SET NOT NULL;
+
+-- A transaction hash can only be booked once per network, so a rescanned block cannot credit twice.
+-- Only rows the watcher books have a block height; older rows may repeat hashes typed in by hand.
+CREATE UNIQUE INDEX idx_transactions_network_tx_hash ON transactions(network, tx_hash) WHERE block_height IS NOT NULL;
+CREATE INDEX idx_transactions_pending_chain ON transactions(network, status) WHERE block_height IS NOT NULL;
+
+-- Create chain_cursors table (last block the deposit watcher scanned per network)
+CREATE TABLE IF NOT EXISTS chain_cursors (
+    network VARCHAR(30) PRIMARY KEY,
+    last_block BIGINT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,8 @@
+ALTER TABLE currency_exchanges DROP COLUMN IF EXISTS fee_amount;
+ALTER TABLE currency_exchanges DROP COLUMN IF EXISTS fee_rule_id;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS fee_rule_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS fee_tier;
+
+DROP TABLE IF EXISTS fee_rules;
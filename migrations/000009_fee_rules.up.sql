@@ -0,0 +1,40 @@
+-- Create fee_rules table. Rules are never edited in place: an update retires the rule
+-- and inserts its replacement, so the rule referenced by a transaction stays as applied.
+CREATE TABLE IF NOT EXISTS fee_rules (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    operation VARCHAR(20) NOT NULL,
+    currency_id BIGINT REFERENCES currencies(id),
+    network VARCHAR(30),
+    from_currency_id BIGINT REFERENCES currencies(id),
+    to_currency_id BIGINT REFERENCES currencies(id),
+    user_tier VARCHAR(20),
+    min_amount DECIMAL(20, 8),
+    max_amount DECIMAL(20, 8),
+    type VARCHAR(20) NOT NULL,
+    percent DECIMAL(10, 6) NOT NULL DEFAULT 0,
+    flat_amount DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    min_fee DECIMAL(20, 8),
+    max_fee DECIMAL(20, 8),
+    tiers JSONB NOT NULL DEFAULT '[]',
+    priority INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    replaced_by BIGINT REFERENCES fee_rules(id),
+    created_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    retired_at TIMESTAMP
+);
+
+CREATE INDEX idx_fee_rules_active_operation ON fee_rules(operation) WHERE is_active = true;
+
+-- Keep the previously hardcoded 0.1% withdrawal fee as the catch-all rule
+INSERT INTO fee_rules (name, operation, type, percent) VALUES
+    ('Default withdrawal fee', 'withdrawal', 'percent', 0.1);
+
+ALTER TABLE users ADD COLUMN fee_tier VARCHAR(20) NOT NULL DEFAULT 'standard';
+
+ALTER TABLE transactions ADD COLUMN fee_rule_id BIGINT REFERENCES fee_rules(id);
+
+ALTER TABLE currency_exchanges ADD COLUMN fee_rule_id BIGINT REFERENCES fee_rules(id);
+ALTER TABLE currency_exchanges ADD COLUMN fee_amount DECIMAL(20, 8) NOT NULL DEFAULT 0;
+UPDATE currency_exchanges SET fee_amount = to_amount - to_amount_with_fee;
@@ -0,0 +1,21 @@
+-- Create exchange_quotes table. A quote is firm until expires_at; exchange_id is set once it is executed.
+CREATE TABLE IF NOT EXISTS exchange_quotes (
+    id BIGSERIAL PRIMARY KEY,
+    uid VARCHAR(36) UNIQUE NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_currency_id BIGINT NOT NULL REFERENCES currencies(id),
+    to_currency_id BIGINT NOT NULL REFERENCES currencies(id),
+    from_amount DECIMAL(20, 8) NOT NULL,
+    to_amount DECIMAL(20, 8) NOT NULL,
+    to_amount_with_fee DECIMAL(20, 8) NOT NULL,
+    exchange_rate DECIMAL(20, 8) NOT NULL,
+    fee DECIMAL(5, 2) NOT NULL,
+    fee_amount DECIMAL(20, 8) NOT NULL,
+    fee_rule_id BIGINT REFERENCES fee_rules(id),
+    expires_at TIMESTAMP NOT NULL,
+    exchange_id BIGINT REFERENCES currency_exchanges(id),
+    executed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_exchange_quotes_created_at ON exchange_quotes(created_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_currency_exchanges_pending_limit;
+
+ALTER TABLE currency_exchanges DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE currency_exchanges DROP COLUMN IF EXISTS target_rate;
@@ -0,0 +1,7 @@
+-- Limit exchanges stay pending with the from-amount held in wallets.locked until the
+-- rate reaches target_rate, or until expires_at
+ALTER TABLE currency_exchanges ADD COLUMN target_rate DECIMAL(20, 8);
+ALTER TABLE currency_exchanges ADD COLUMN expires_at TIMESTAMP;
+
+CREATE INDEX idx_currency_exchanges_pending_limit ON currency_exchanges(from_currency_id, to_currency_id)
+    WHERE status = 'pending' AND target_rate IS NOT NULL;
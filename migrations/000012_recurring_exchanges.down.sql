@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS recurring_exchange_runs;
+DROP TABLE IF EXISTS recurring_exchanges;
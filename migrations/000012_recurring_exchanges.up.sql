@@ -0,0 +1,34 @@
+-- Create recurring_exchanges table. Exactly one of cron_expression and interval_seconds is set.
+CREATE TABLE IF NOT EXISTS recurring_exchanges (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_currency_id BIGINT NOT NULL REFERENCES currencies(id),
+    to_currency_id BIGINT NOT NULL REFERENCES currencies(id),
+    from_amount DECIMAL(20, 8) NOT NULL,
+    cron_expression VARCHAR(100),
+    interval_seconds BIGINT,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    next_run_at TIMESTAMP NOT NULL,
+    last_run_at TIMESTAMP,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    pause_reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK ((cron_expression IS NULL) <> (interval_seconds IS NULL))
+);
+
+CREATE INDEX idx_recurring_exchanges_user_id ON recurring_exchanges(user_id);
+CREATE INDEX idx_recurring_exchanges_due ON recurring_exchanges(next_run_at) WHERE status = 'active';
+
+-- One row per scheduled execution attempt
+CREATE TABLE IF NOT EXISTS recurring_exchange_runs (
+    id BIGSERIAL PRIMARY KEY,
+    recurring_exchange_id BIGINT NOT NULL REFERENCES recurring_exchanges(id) ON DELETE CASCADE,
+    exchange_id BIGINT REFERENCES currency_exchanges(id),
+    status VARCHAR(20) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    scheduled_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_recurring_exchange_runs_recurring_exchange_id ON recurring_exchange_runs(recurring_exchange_id, created_at DESC);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_currency_exchanges_user_pair_created;
+
+ALTER TABLE exchange_rates DROP COLUMN IF EXISTS amount_precision;
+ALTER TABLE exchange_rates DROP COLUMN IF EXISTS daily_limit;
+ALTER TABLE exchange_rates DROP COLUMN IF EXISTS max_amount;
+ALTER TABLE exchange_rates DROP COLUMN IF EXISTS min_amount;
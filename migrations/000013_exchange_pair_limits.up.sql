@@ -0,0 +1,8 @@
+-- Per-pair trading limits. NULL means unlimited; amount_precision NULL falls back to the
+-- from-currency's decimals. daily_limit caps a user's from-amount on the pair per calendar day.
+ALTER TABLE exchange_rates ADD COLUMN min_amount DECIMAL(20, 8);
+ALTER TABLE exchange_rates ADD COLUMN max_amount DECIMAL(20, 8);
+ALTER TABLE exchange_rates ADD COLUMN daily_limit DECIMAL(20, 8);
+ALTER TABLE exchange_rates ADD COLUMN amount_precision SMALLINT;
+
+CREATE INDEX idx_currency_exchanges_user_pair_created ON currency_exchanges(user_id, from_currency_id, to_currency_id, created_at);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS kyc_limits;
+DROP TABLE IF EXISTS kyc_documents;
+DROP TABLE IF EXISTS kyc_applications;
+
+ALTER TABLE users DROP COLUMN IF EXISTS kyc_level;
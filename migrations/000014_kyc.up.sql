@@ -0,0 +1,46 @@
+-- KYC level granted to the user by the last approved application. 0 means unverified.
+ALTER TABLE users ADD COLUMN kyc_level SMALLINT NOT NULL DEFAULT 0;
+
+-- Create kyc_applications table. A user has at most one pending application.
+CREATE TABLE IF NOT EXISTS kyc_applications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    level SMALLINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reason VARCHAR(500) NOT NULL DEFAULT '',
+    reviewed_by BIGINT REFERENCES users(id),
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_kyc_applications_user_id ON kyc_applications(user_id);
+CREATE INDEX idx_kyc_applications_status ON kyc_applications(status, created_at);
+CREATE UNIQUE INDEX idx_kyc_applications_one_pending ON kyc_applications(user_id) WHERE status = 'pending';
+
+-- Create kyc_documents table. Files live in blob storage under storage_key; documents are
+-- attached to an application when it is submitted.
+CREATE TABLE IF NOT EXISTS kyc_documents (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    application_id BIGINT REFERENCES kyc_applications(id) ON DELETE SET NULL,
+    type VARCHAR(30) NOT NULL,
+    storage_key VARCHAR(255) UNIQUE NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_kyc_documents_user_id ON kyc_documents(user_id);
+CREATE INDEX idx_kyc_documents_application_id ON kyc_documents(application_id);
+
+-- Create kyc_limits table. Daily limits per level and currency; NULL or a missing row is unlimited.
+CREATE TABLE IF NOT EXISTS kyc_limits (
+    level SMALLINT NOT NULL,
+    currency_id BIGINT NOT NULL REFERENCES currencies(id) ON DELETE CASCADE,
+    daily_withdrawal_limit DECIMAL(20, 8),
+    daily_exchange_limit DECIMAL(20, 8),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (level, currency_id)
+);
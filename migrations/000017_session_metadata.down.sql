@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS revoked_sessions;
+
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS last_used_at;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS ip_address;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS user_agent;
@@ -0,0 +1,15 @@
+-- Record where each session was opened and when it was last refreshed
+ALTER TABLE user_sessions ADD COLUMN user_agent VARCHAR(512) NOT NULL DEFAULT '';
+ALTER TABLE user_sessions ADD COLUMN ip_address VARCHAR(45) NOT NULL DEFAULT '';
+ALTER TABLE user_sessions ADD COLUMN last_used_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- Revoked sessions whose access tokens may still be unexpired. Access tokens carry the
+-- session ID, so they are rejected as soon as their session is listed here. Rows can be
+-- dropped once expires_at, the latest expiry of any access token of the session, has passed.
+CREATE TABLE IF NOT EXISTS revoked_sessions (
+    session_id BIGINT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_revoked_sessions_expires ON revoked_sessions(expires_at);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS security_events;
+DROP TABLE IF EXISTS rotated_refresh_tokens;
+
+-- Hashed tokens cannot be restored, so every session is signed out
+DELETE FROM user_sessions;
+ALTER INDEX idx_user_sessions_token_hash RENAME TO idx_user_sessions_token;
+ALTER TABLE user_sessions RENAME COLUMN refresh_token_hash TO refresh_token;
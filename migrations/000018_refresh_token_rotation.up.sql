@@ -0,0 +1,31 @@
+-- Store only SHA-256 hashes of refresh tokens. Existing tokens keep working since
+-- they are looked up by the hash of what the client presents.
+UPDATE user_sessions SET refresh_token = encode(sha256(refresh_token::bytea), 'hex');
+ALTER TABLE user_sessions RENAME COLUMN refresh_token TO refresh_token_hash;
+ALTER INDEX idx_user_sessions_token RENAME TO idx_user_sessions_token_hash;
+
+-- Refresh tokens a session has already rotated away from. A session is a token family:
+-- presenting one of these again means the token was copied, so the whole session is revoked.
+CREATE TABLE IF NOT EXISTS rotated_refresh_tokens (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    session_id BIGINT NOT NULL REFERENCES user_sessions(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rotated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_rotated_refresh_tokens_session ON rotated_refresh_tokens(session_id);
+
+-- Security-relevant events such as refresh token reuse
+CREATE TABLE IF NOT EXISTS security_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    type VARCHAR(50) NOT NULL,
+    session_id BIGINT,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    details VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_security_events_user ON security_events(user_id, created_at);
+CREATE INDEX idx_security_events_type ON security_events(type, created_at);
@@ -0,0 +1,27 @@
+-- API keys for programmatic clients. Requests are signed with HMAC-SHA256 under the key's
+-- secret, so the secret is stored encrypted rather than hashed. allowed_ips holds IPs or
+-- CIDR ranges; an empty list allows any address.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    key_id VARCHAR(64) NOT NULL UNIQUE,
+    secret_encrypted TEXT NOT NULL,
+    scopes TEXT[] NOT NULL,
+    allowed_ips TEXT[] NOT NULL DEFAULT '{}',
+    last_used_at TIMESTAMP,
+    last_used_ip VARCHAR(45),
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_keys_user ON api_keys(user_id);
+
+-- Nonces seen per key while their request timestamp is still accepted, so a signed
+-- request cannot be replayed
+CREATE TABLE IF NOT EXISTS api_key_nonces (
+    api_key_id BIGINT NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    nonce VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (api_key_id, nonce)
+);
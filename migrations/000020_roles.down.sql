@@ -0,0 +1,7 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_role;
+
+-- Staff roles have no equivalent before this migration, so they lose back-office access
+UPDATE users SET role = 'admin' WHERE role = 'super_admin';
+UPDATE users SET role = 'client' WHERE role NOT IN ('client', 'admin');
+
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,24 @@
+-- Back-office roles built from named permissions. users.role references a role by name.
+-- System roles cannot be edited or deleted; '*' grants every permission.
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(20) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    is_system BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO roles (name, description, permissions, is_system) VALUES
+    ('client', 'Exchange customer without back-office access', '{}', true),
+    ('super_admin', 'Full back-office access', '{*}', true),
+    ('support', 'Read-only access to users and exchanges',
+        '{users:read,exchanges:read}', false),
+    ('finance', 'Deposits, withdrawals and the ledger',
+        '{users:read,deposits:create,deposit_addresses:import,withdrawals:read,withdrawals:manage,ledger:read}', false),
+    ('compliance', 'KYC review, limits and account investigations',
+        '{users:read,exchanges:read,withdrawals:read,kyc:read,kyc:review,kyc_limits:write,security_events:read}', false);
+
+UPDATE users SET role = 'super_admin' WHERE role = 'admin';
+
+ALTER TABLE users ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles(name);
@@ -0,0 +1,12 @@
+-- Changes staff make to a user account, each with the reason given for it
+CREATE TABLE IF NOT EXISTS user_admin_actions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    admin_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(30) NOT NULL,
+    reason VARCHAR(500) NOT NULL,
+    details VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_admin_actions_user ON user_admin_actions(user_id, created_at);
@@ -0,0 +1,6 @@
+UPDATE roles SET permissions = array_remove(permissions, 'audit_log:read');
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS description;
+
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS audit_log_forbid_mutation();
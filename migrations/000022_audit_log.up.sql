@@ -0,0 +1,42 @@
+-- Append-only record of back-office changes. Each row's hash covers its contents and the
+-- previous row's hash, so editing or removing a row breaks the chain from that row on.
+-- before_state and after_state are JSON rather than JSONB so the stored text is exactly what
+-- was hashed.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id BIGINT REFERENCES users(id) ON DELETE RESTRICT,
+    action VARCHAR(50) NOT NULL,
+    target_type VARCHAR(30) NOT NULL,
+    target_id VARCHAR(64) NOT NULL,
+    before_state JSON,
+    after_state JSON,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_audit_log_actor ON audit_log(actor_id, created_at DESC);
+CREATE INDEX idx_audit_log_target ON audit_log(target_type, target_id, created_at DESC);
+CREATE INDEX idx_audit_log_action ON audit_log(action, created_at DESC);
+
+CREATE OR REPLACE FUNCTION audit_log_forbid_mutation() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_log_immutable
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_forbid_mutation();
+
+CREATE TRIGGER trg_audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_forbid_mutation();
+
+-- Manual deposits keep the note the admin gave
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS description VARCHAR(500) NOT NULL DEFAULT '';
+
+UPDATE roles SET permissions = array_append(permissions, 'audit_log:read')
+WHERE name = 'compliance' AND NOT ('audit_log:read' = ANY(permissions));
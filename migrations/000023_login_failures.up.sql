@@ -0,0 +1,12 @@
+-- Failed sign-in attempts per account and per client IP. Accounts are tracked by the email
+-- that was tried rather than by user, so unknown emails are throttled exactly like real ones.
+CREATE TABLE IF NOT EXISTS login_failures (
+    scope VARCHAR(10) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    PRIMARY KEY (scope, subject)
+);
+
+CREATE INDEX idx_login_failures_last_failure ON login_failures(last_failure_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_transactions_deposit_output;
+CREATE UNIQUE INDEX idx_transactions_network_tx_hash ON transactions(network, tx_hash) WHERE block_height IS NOT NULL;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS output_index;
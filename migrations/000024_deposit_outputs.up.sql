@@ -0,0 +1,10 @@
+-- Position of the credited output within its chain transaction (vout, log index). One
+-- transaction can pay several allocated addresses, or the same address more than once.
+ALTER TABLE transactions ADD COLUMN output_index INTEGER NOT NULL DEFAULT 0;
+
+-- Each deposit output can only be booked once per network, so a rescanned block cannot
+-- credit twice. Withdrawals are left out: a batched payout shares one hash. So are deposits
+-- without a block height, whose hashes were entered by hand and may repeat.
+DROP INDEX IF EXISTS idx_transactions_network_tx_hash;
+CREATE UNIQUE INDEX idx_transactions_deposit_output ON transactions(network, tx_hash, output_index)
+    WHERE type = 'deposit' AND block_height IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_currency_exchanges_pending_limit_expiry;
+DROP INDEX IF EXISTS idx_currency_exchanges_pending_limit;
+CREATE INDEX idx_currency_exchanges_pending_limit ON currency_exchanges(from_currency_id, to_currency_id)
+    WHERE status = 'pending' AND target_rate IS NOT NULL;
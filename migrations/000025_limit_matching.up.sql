@@ -0,0 +1,7 @@
+-- The matcher selects pending limit exchanges by pair and target rate, and expires overdue
+-- ones in bulk
+DROP INDEX IF EXISTS idx_currency_exchanges_pending_limit;
+CREATE INDEX idx_currency_exchanges_pending_limit ON currency_exchanges(from_currency_id, to_currency_id, target_rate)
+    WHERE status = 'pending' AND target_rate IS NOT NULL;
+CREATE INDEX idx_currency_exchanges_pending_limit_expiry ON currency_exchanges(expires_at)
+    WHERE status = 'pending' AND target_rate IS NOT NULL;
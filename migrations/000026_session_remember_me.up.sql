@@ -0,0 +1,5 @@
+-- Whether the session was opened with remember me, so refreshing it keeps its longer lifetime
+ALTER TABLE user_sessions ADD COLUMN remember_me BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Sessions expiring more than the default 7 days after their last use were opened with remember me
+UPDATE user_sessions SET remember_me = TRUE WHERE expires_at > last_used_at + INTERVAL '7 days';
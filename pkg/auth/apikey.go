@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// apiKeyIDPrefix marks key IDs so they are recognizable in logs and leaked-secret scanners
+const apiKeyIDPrefix = "ck_"
+
+// GenerateAPIKey returns a new public key ID and the secret requests are signed with
+func GenerateAPIKey() (keyID, secret string, err error) {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+
+	s := make([]byte, 32)
+	if _, err := rand.Read(s); err != nil {
+		return "", "", err
+	}
+
+	return apiKeyIDPrefix + hex.EncodeToString(id), base64.RawURLEncoding.EncodeToString(s), nil
+}
+
+// APIRequestPayload is the string an API request signature covers: method, path with query,
+// timestamp, nonce and the hex SHA-256 of the body, separated by newlines
+func APIRequestPayload(method, uri, timestamp, nonce string, body []byte) string {
+	sum := sha256.Sum256(body)
+	return strings.Join([]string{method, uri, timestamp, nonce, hex.EncodeToString(sum[:])}, "\n")
+}
+
+// SignAPIRequest returns the hex HMAC-SHA256 of payload under secret
+func SignAPIRequest(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyAPIRequest checks signature against payload in constant time
+func VerifyAPIRequest(secret, payload, signature string) bool {
+	return hmac.Equal([]byte(SignAPIRequest(secret, payload)), []byte(strings.ToLower(signature)))
+}
+
+// SecretBox encrypts secrets the server has to read back, such as API key secrets,
+// with AES-256-GCM
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox derives the encryption key from key, which should be a long random string
+func NewSecretBox(key string) (*SecretBox, error) {
+	if key == "" {
+		return nil, fmt.Errorf("encryption key is empty")
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal returns plaintext encrypted under a random nonce, base64 encoded
+func (b *SecretBox) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value produced by Seal
+func (b *SecretBox) Open(sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("invalid sealed value")
+	}
+
+	size := b.aead.NonceSize()
+	if len(data) < size {
+		return "", fmt.Errorf("invalid sealed value")
+	}
+
+	plaintext, err := b.aead.Open(nil, data[:size], data[size:], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt sealed value")
+	}
+
+	return string(plaintext), nil
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type TokenType string
@@ -14,6 +15,16 @@ type TokenType string
 const (
 	AccessToken  TokenType = "access"
 	RefreshToken TokenType = "refresh"
+	// MFAPendingToken proves the password step of a login and is exchanged for real
+	// tokens once the second factor is verified
+	MFAPendingToken TokenType = "mfa_pending"
+)
+
+// Audiences tell access tokens apart from mfa_pending tokens for verifiers that only check
+// the signature and the registered claims
+const (
+	AccessTokenAudience     = "api"
+	MFAPendingTokenAudience = "mfa"
 )
 
 type Claims struct {
@@ -21,42 +32,88 @@ type Claims struct {
 	Email  string `json:"email"`
 	Role   string `json:"role"`
 	Type   string `json:"type"`
+	// SessionID ties an access token to the user_sessions row it was issued for
+	SessionID int64 `json:"sid,omitempty"`
+	// RememberMe is carried by mfa_pending tokens to the second login step
+	RememberMe bool `json:"remember_me,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// JWTManager signs access tokens with the active key of keys, identified by the kid header.
+// Without keys it signs HS256 with secret. mfa_pending tokens are always signed HS256 with
+// secret, so the keys published in the JWKS never vouch for a password-only login. The secret
+// also signs the opaque tokens in token.go.
 type JWTManager struct {
 	secret             []byte
+	issuer             string
+	keys               *KeySet
 	accessTokenExpiry  time.Duration
 	refreshTokenExpiry time.Duration
+	// legacyUntil is when HS256 tokens issued before keys were configured stop being accepted
+	legacyUntil time.Time
 }
 
-func NewJWTManager(secret string, accessExpiry, refreshExpiry time.Duration) *JWTManager {
+func NewJWTManager(secret, issuer string, keys *KeySet, accessExpiry, refreshExpiry time.Duration) *JWTManager {
 	return &JWTManager{
 		secret:             []byte(secret),
+		issuer:             issuer,
+		keys:               keys,
 		accessTokenExpiry:  accessExpiry,
 		refreshTokenExpiry: refreshExpiry,
+		legacyUntil:        time.Now().Add(accessExpiry),
 	}
 }
 
-func (j *JWTManager) GenerateAccessToken(userID int64, email, role string) (string, error) {
-	return j.GenerateAccessTokenWithExpiry(userID, email, role, j.accessTokenExpiry)
+// Keys returns the asymmetric key set, or nil when tokens are signed HS256
+func (j *JWTManager) Keys() *KeySet {
+	return j.keys
+}
+
+// AccessTokenExpiry is the configured lifetime of access tokens
+func (j *JWTManager) AccessTokenExpiry() time.Duration {
+	return j.accessTokenExpiry
+}
+
+func (j *JWTManager) GenerateAccessToken(userID, sessionID int64, email, role string) (string, error) {
+	return j.GenerateAccessTokenWithExpiry(userID, sessionID, email, role, j.accessTokenExpiry)
 }
 
 // GenerateAccessTokenWithExpiry generates an access token with a custom expiry duration
-func (j *JWTManager) GenerateAccessTokenWithExpiry(userID int64, email, role string, expiry time.Duration) (string, error) {
+func (j *JWTManager) GenerateAccessTokenWithExpiry(userID, sessionID int64, email, role string, expiry time.Duration) (string, error) {
+	claims := Claims{
+		UserID:    userID,
+		SessionID: sessionID,
+		Email:     email,
+		Role:      role,
+		Type:      string(AccessToken),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    j.issuer,
+			Audience:  jwt.ClaimStrings{AccessTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return j.signClaims(claims)
+}
+
+// GenerateMFAPendingToken issues the short-lived token returned by the password step of a login.
+// Its jti lets the attempts made with it be counted.
+func (j *JWTManager) GenerateMFAPendingToken(userID int64, rememberMe bool, expiry time.Duration) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
-		Type:   string(AccessToken),
+		UserID:     userID,
+		Type:       string(MFAPendingToken),
+		RememberMe: rememberMe,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Issuer:    j.issuer,
+			Audience:  jwt.ClaimStrings{MFAPendingTokenAudience},
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.secret)
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(j.secret)
 }
 
 func (j *JWTManager) GenerateRefreshToken() (string, error) {
@@ -67,21 +124,72 @@ func (j *JWTManager) GenerateRefreshToken() (string, error) {
 	return base64.URLEncoding.EncodeToString(b), nil
 }
 
+// ValidateToken verifies an access token
 func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
+	return j.parse(tokenString, AccessToken, AccessTokenAudience, j.verificationKey)
+}
+
+// ValidateMFAPendingToken verifies a token issued by GenerateMFAPendingToken
+func (j *JWTManager) ValidateMFAPendingToken(tokenString string) (*Claims, error) {
+	return j.parse(tokenString, MFAPendingToken, MFAPendingTokenAudience, func(token *jwt.Token) (interface{}, error) {
 		return j.secret, nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+}
 
+func (j *JWTManager) parse(tokenString string, tokenType TokenType, audience string, keyFunc jwt.Keyfunc, opts ...jwt.ParserOption) (*Claims, error) {
+	opts = append(opts, jwt.WithIssuer(j.issuer), jwt.WithAudience(audience))
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, keyFunc, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid && claims.Type == string(tokenType) {
 		return claims, nil
 	}
 
 	return nil, fmt.Errorf("invalid token")
 }
+
+func (j *JWTManager) signClaims(claims Claims) (string, error) {
+	if j.keys == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(j.secret)
+	}
+
+	key, err := j.keys.Signing(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.private)
+}
+
+// verificationKey picks the key a token is checked with. The algorithm must be the one of the
+// key named by kid, so a token cannot choose how it is verified.
+func (j *JWTManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if j.keys != nil && time.Now().After(j.legacyUntil) {
+			return nil, fmt.Errorf("token has no kid")
+		}
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return j.secret, nil
+	}
+
+	if j.keys == nil {
+		return nil, fmt.Errorf("unexpected kid %q", kid)
+	}
+
+	key, ok := j.keys.Verification(kid, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("unknown or retired key %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.public, nil
+}
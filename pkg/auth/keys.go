@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSAKeyBits is the smallest RSA modulus accepted for signing keys
+const minRSAKeyBits = 2048
+
+// KeyManifest lists the signing keys and when each one is used. Example:
+//
+//	{"keys": [
+//	  {"kid": "2026-01", "algorithm": "RS256", "private_key_file": "2026-01.pem",
+//	   "active_from": "2026-01-01T00:00:00Z", "retire_at": "2026-05-01T00:00:00Z"},
+//	  {"kid": "2026-04", "algorithm": "EdDSA", "private_key_file": "2026-04.pem",
+//	   "active_from": "2026-04-01T00:00:00Z"}
+//	]}
+//
+// The key with the latest active_from that has passed signs new tokens. Every key that is
+// not yet retired is published in the JWKS and accepted for verification, so a key can be
+// published ahead of its active_from and kept after it stops signing until the tokens it
+// signed have expired.
+type KeyManifest struct {
+	Keys []KeyManifestEntry `json:"keys"`
+}
+
+type KeyManifestEntry struct {
+	ID             string     `json:"kid"`
+	Algorithm      string     `json:"algorithm"`        // RS256 or EdDSA
+	PrivateKeyFile string     `json:"private_key_file"` // PKCS#8 or PKCS#1 PEM, relative to the manifest
+	ActiveFrom     time.Time  `json:"active_from"`
+	RetireAt       *time.Time `json:"retire_at,omitempty"`
+}
+
+// SigningKey is one asymmetric key of a KeySet
+type SigningKey struct {
+	ID         string
+	ActiveFrom time.Time
+	RetireAt   *time.Time
+
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+func (k *SigningKey) retired(now time.Time) bool {
+	return k.RetireAt != nil && !now.Before(*k.RetireAt)
+}
+
+// KeySet holds the asymmetric keys tokens are signed and verified with
+type KeySet struct {
+	keys []*SigningKey // by ActiveFrom, oldest first
+}
+
+// LoadKeySet reads a KeyManifest and the private keys it references
+func LoadKeySet(manifestPath string) (*KeySet, error) {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key manifest: %w", err)
+	}
+
+	var manifest KeyManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse key manifest: %w", err)
+	}
+
+	if len(manifest.Keys) == 0 {
+		return nil, fmt.Errorf("key manifest lists no keys")
+	}
+
+	dir := filepath.Dir(manifestPath)
+	seen := make(map[string]bool, len(manifest.Keys))
+	ks := &KeySet{}
+
+	for _, entry := range manifest.Keys {
+		if entry.ID == "" {
+			return nil, fmt.Errorf("key manifest entry without kid")
+		}
+		if seen[entry.ID] {
+			return nil, fmt.Errorf("duplicate kid %q", entry.ID)
+		}
+		seen[entry.ID] = true
+
+		if entry.RetireAt != nil && !entry.RetireAt.After(entry.ActiveFrom) {
+			return nil, fmt.Errorf("key %q: retire_at must be after active_from", entry.ID)
+		}
+
+		path := entry.PrivateKeyFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+
+		key, err := loadSigningKey(entry, path)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", entry.ID, err)
+		}
+		ks.keys = append(ks.keys, key)
+	}
+
+	sort.Slice(ks.keys, func(i, j int) bool {
+		return ks.keys[i].ActiveFrom.Before(ks.keys[j].ActiveFrom)
+	})
+
+	return ks, nil
+}
+
+func loadSigningKey(entry KeyManifestEntry, path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("private key is not PEM encoded")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	key := &SigningKey{
+		ID:         entry.ID,
+		ActiveFrom: entry.ActiveFrom,
+		RetireAt:   entry.RetireAt,
+	}
+
+	switch entry.Algorithm {
+	case "RS256":
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("RS256 needs an RSA key")
+		}
+		if rsaKey.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+		}
+		key.method = jwt.SigningMethodRS256
+		key.private = rsaKey
+		key.public = &rsaKey.PublicKey
+	case "EdDSA":
+		edKey, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("EdDSA needs an Ed25519 key")
+		}
+		key.method = jwt.SigningMethodEdDSA
+		key.private = edKey
+		key.public = edKey.Public()
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", entry.Algorithm)
+	}
+
+	return key, nil
+}
+
+// Signing returns the key that signs tokens at now
+func (ks *KeySet) Signing(now time.Time) (*SigningKey, error) {
+	for i := len(ks.keys) - 1; i >= 0; i-- {
+		key := ks.keys[i]
+		if !key.ActiveFrom.After(now) && !key.retired(now) {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("no signing key is active")
+}
+
+// Verification returns the key with kid if it may still verify tokens at now
+func (ks *KeySet) Verification(kid string, now time.Time) (*SigningKey, bool) {
+	for _, key := range ks.keys {
+		if key.ID == kid {
+			return key, !key.retired(now)
+		}
+	}
+	return nil, false
+}
+
+// JWK is the public half of a signing key as published in a JWKS (RFC 7517, RFC 8037)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns every key that is not retired at now, including keys not yet signing
+func (ks *KeySet) JWKS(now time.Time) JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		if key.retired(now) {
+			continue
+		}
+
+		jwk := JWK{
+			Use:       "sig",
+			Algorithm: key.method.Alg(),
+			KeyID:     key.ID,
+		}
+
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
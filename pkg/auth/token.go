@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// GenerateSignedToken returns a random single-use token bound to purpose, e.g. for email links.
+// The token itself is never stored; look it up by HashToken.
+func (j *JWTManager) GenerateSignedToken(purpose string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(b)
+	return payload + "." + j.sign(purpose, payload), nil
+}
+
+// VerifySignedToken checks that token was issued by GenerateSignedToken for purpose.
+// Forged or mistyped tokens are rejected without a database lookup.
+func (j *JWTManager) VerifySignedToken(token, purpose string) error {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || payload == "" {
+		return fmt.Errorf("invalid token")
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(j.sign(purpose, payload))) {
+		return fmt.Errorf("invalid token")
+	}
+
+	return nil
+}
+
+func (j *JWTManager) sign(purpose, payload string) string {
+	mac := hmac.New(sha256.New, j.secret)
+	mac.Write([]byte(purpose + ":" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// HashToken returns the SHA-256 hex digest under which a token is stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
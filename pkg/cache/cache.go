@@ -0,0 +1,12 @@
+package cache
+
+import "time"
+
+// Cache is the key-value store behind CacheService. A ttl of NoExpiration or 0 keeps the value
+// until it is overwritten or deleted.
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, ttl time.Duration) error
+	Delete(key string) error
+	Flush() error
+}
@@ -40,6 +40,14 @@ func (cl *CacheLoader) WarmUpCache(ctx context.Context) error {
 		return err
 	}
 
+	if err := cl.loadRoles(ctx); err != nil {
+		return err
+	}
+
+	if err := cl.loadRevokedSessions(ctx); err != nil {
+		return err
+	}
+
 	cl.logger.Info("Cache warm-up completed", "duration_ms", time.Since(start).Milliseconds())
 	return nil
 }
@@ -93,6 +101,28 @@ func (cl *CacheLoader) loadExchangeRates(ctx context.Context) error {
 	return nil
 }
 
+// loadRevokedSessions restores the revocation list, which must be complete before the
+// server accepts requests
+func (cl *CacheLoader) loadRevokedSessions(ctx context.Context) error {
+	if _, err := cl.db.ExecContext(ctx, queries.RevokedSessionDeleteExpiredQuery); err != nil {
+		cl.logger.Error("Failed to delete expired session revocations", "error", err)
+		return err
+	}
+
+	var revoked []domain.RevokedSession
+	if err := cl.db.SelectContext(ctx, &revoked, queries.RevokedSessionGetActiveQuery); err != nil {
+		cl.logger.Error("Failed to load revoked sessions", "error", err)
+		return err
+	}
+
+	for _, r := range revoked {
+		cl.cacheService.RevokeSession(r.SessionID, r.ExpiresAt)
+	}
+
+	cl.logger.Info("Loaded revoked sessions into cache", "count", len(revoked))
+	return nil
+}
+
 func (cl *CacheLoader) loadUsers(ctx context.Context) error {
 	var users []domain.User
 	query := `SELECT * FROM users`
@@ -110,6 +140,20 @@ func (cl *CacheLoader) loadUsers(ctx context.Context) error {
 	return nil
 }
 
+func (cl *CacheLoader) loadRoles(ctx context.Context) error {
+	var roles []domain.Role
+
+	if err := cl.db.SelectContext(ctx, &roles, queries.RoleGetAllQuery); err != nil {
+		cl.logger.Error("Failed to load roles", "error", err)
+		return err
+	}
+
+	cl.cacheService.SetRoles(roles)
+	cl.logger.Info("Loaded roles into cache", "count", len(roles))
+
+	return nil
+}
+
 // Optional: Load recently active users
 func (cl *CacheLoader) LoadRecentUsers(ctx context.Context, limit int) error {
 	var users []domain.User
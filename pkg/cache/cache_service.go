@@ -18,7 +18,7 @@ type WriteOperation struct {
 }
 
 type CacheService struct {
-	cache       *MemoryCache
+	cache       Cache
 	logger      *logger.Logger
 	wg          sync.WaitGroup
 	ctx         context.Context
@@ -28,7 +28,7 @@ type CacheService struct {
 
 const NoExpiration time.Duration = -1
 
-func NewCacheService(cache *MemoryCache, logger *logger.Logger) *CacheService {
+func NewCacheService(cache Cache, logger *logger.Logger) *CacheService {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &CacheService{
 		cache:  cache,
@@ -248,9 +248,9 @@ func (cs *CacheService) SetUserWallets(userID int64, wallets []domain.WalletWith
 	cs.cache.Set(key, wallets, 0)
 }
 
-// Session cache operations
-func (cs *CacheService) GetSession(token string) (*domain.UserSession, bool) {
-	key := fmt.Sprintf("session:%s", token)
+// Session cache operations, keyed by refresh token hash
+func (cs *CacheService) GetSession(tokenHash string) (*domain.UserSession, bool) {
+	key := fmt.Sprintf("session:%s", tokenHash)
 	val, found := cs.cache.Get(key)
 	if !found {
 		return nil, false
@@ -265,15 +265,49 @@ func (cs *CacheService) GetSession(token string) (*domain.UserSession, bool) {
 }
 
 func (cs *CacheService) SetSession(session *domain.UserSession, ttl time.Duration) {
-	key := fmt.Sprintf("session:%s", session.RefreshToken)
+	key := fmt.Sprintf("session:%s", session.RefreshTokenHash)
 	cs.cache.Set(key, session, ttl)
 }
 
-func (cs *CacheService) DeleteSession(token string) {
-	key := fmt.Sprintf("session:%s", token)
+func (cs *CacheService) DeleteSession(tokenHash string) {
+	key := fmt.Sprintf("session:%s", tokenHash)
 	cs.cache.Delete(key)
 }
 
+// RevokeSession rejects access tokens of the session until they have all expired
+func (cs *CacheService) RevokeSession(sessionID int64, until time.Time) {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return
+	}
+	key := fmt.Sprintf("revoked_session:%d", sessionID)
+	cs.cache.Set(key, true, ttl)
+}
+
+func (cs *CacheService) IsSessionRevoked(sessionID int64) bool {
+	key := fmt.Sprintf("revoked_session:%d", sessionID)
+	_, found := cs.cache.Get(key)
+	return found
+}
+
+// GetRoles returns all roles. Roles are few, so they are cached as one list.
+func (cs *CacheService) GetRoles() ([]domain.Role, bool) {
+	val, found := cs.cache.Get("roles:all")
+	if !found {
+		return nil, false
+	}
+	roles, ok := val.([]domain.Role)
+	if !ok {
+		return nil, false
+	}
+	// Return a copy to prevent mutations affecting the cache
+	return append([]domain.Role(nil), roles...), true
+}
+
+func (cs *CacheService) SetRoles(roles []domain.Role) {
+	cs.cache.Set("roles:all", roles, NoExpiration)
+}
+
 func (cs *CacheService) GetAllExchangeRates() ([]domain.ExchangeRateWithCurrencies, bool) {
 	val, found := cs.cache.Get("exchange_rates:all")
 	if !found {
@@ -106,17 +106,17 @@ func (cw *CacheWriter) writeSession(ctx context.Context, op WriteOperation) erro
 	switch op.Action {
 	case "insert":
 		query := `
-			INSERT INTO user_sessions (id, user_id, refresh_token, expires_at, created_at)
+			INSERT INTO user_sessions (id, user_id, refresh_token_hash, expires_at, created_at)
 			VALUES ($1, $2, $3, $4, $5)
-			ON CONFLICT (refresh_token) DO NOTHING
+			ON CONFLICT (refresh_token_hash) DO NOTHING
 		`
 		_, err := cw.db.ExecContext(ctx, query,
-			session.ID, session.UserID, session.RefreshToken, session.ExpiresAt, session.CreatedAt)
+			session.ID, session.UserID, session.RefreshTokenHash, session.ExpiresAt, session.CreatedAt)
 		return err
 
 	case "delete":
-		query := `DELETE FROM user_sessions WHERE refresh_token = $1`
-		_, err := cw.db.ExecContext(ctx, query, session.RefreshToken)
+		query := `DELETE FROM user_sessions WHERE refresh_token_hash = $1`
+		_, err := cw.db.ExecContext(ctx, query, session.RefreshTokenHash)
 		return err
 
 	default:
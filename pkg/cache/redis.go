@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTimeout bounds each cache round trip. The cache methods take no context, and a hung
+// Redis must not hang the request.
+const redisTimeout = 2 * time.Second
+
+// flushBatchSize is how many keys Flush scans or deletes per round trip
+const flushBatchSize = 500
+
+func init() {
+	// Every type CacheService stores must be registered to be decoded from Redis
+	gob.Register(&domain.User{})
+	gob.Register(&domain.Currency{})
+	gob.Register([]domain.Currency{})
+	gob.Register(&domain.ExchangeRate{})
+	gob.Register([]domain.ExchangeRateWithCurrencies{})
+	gob.Register(&domain.Wallet{})
+	gob.Register([]domain.WalletWithCurrency{})
+	gob.Register(&domain.UserSession{})
+	gob.Register([]domain.Role{})
+}
+
+// redisEntry wraps a value so gob records its concrete type
+type redisEntry struct {
+	Value interface{}
+}
+
+// RedisCache keeps the cache in Redis so every server sees the same data. Values are gob
+// encoded; a value that fails to decode is treated as a miss.
+type RedisCache struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+func NewRedisCache(client redis.UniversalClient, prefix string) *RedisCache {
+	return &RedisCache{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (r *RedisCache) Get(key string) (interface{}, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	data, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if err != nil {
+		return nil, false
+	}
+
+	var entry redisEntry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil {
+		return nil, false
+	}
+	return entry.Value, true
+}
+
+func (r *RedisCache) Set(key string, value interface{}, ttl time.Duration) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(redisEntry{Value: value}); err != nil {
+		return fmt.Errorf("failed to encode cache value for %s: %w", key, err)
+	}
+
+	// go-redis reads a negative ttl as "keep the current TTL"
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return r.client.Set(ctx, r.prefix+key, buf.Bytes(), ttl).Err()
+}
+
+func (r *RedisCache) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return r.client.Del(ctx, r.prefix+key).Err()
+}
+
+// Flush deletes the keys under the cache's prefix, leaving other data in the Redis database alone.
+// Keys are collected before any are deleted, so the scan does not depend on how the server
+// handles keys removed while it iterates.
+func (r *RedisCache) Flush() error {
+	ctx := context.Background()
+	iter := r.client.Scan(ctx, 0, r.prefix+"*", flushBatchSize).Iterator()
+
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	for len(keys) > 0 {
+		batch := keys[:min(len(keys), flushBatchSize)]
+		if err := r.client.Del(ctx, batch...).Err(); err != nil {
+			return err
+		}
+		keys = keys[len(batch):]
+	}
+	return nil
+}
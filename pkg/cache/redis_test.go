@@ -0,0 +1,235 @@
+package cache
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/pkg/logger"
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+)
+
+func newTestRedisCache(t *testing.T, mr *miniredis.Miniredis, prefix string) *RedisCache {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisCache(client, prefix)
+}
+
+func testDecimal(s string) *decimal.Decimal {
+	d := decimal.RequireFromString(s)
+	return &d
+}
+
+// TestCacheServiceRedisRoundTrip stores every type CacheService caches through one server's
+// cache and reads it back through another's, as servers sharing Redis do
+func TestCacheServiceRedisRoundTrip(t *testing.T) {
+	mr := miniredis.RunT(t)
+	log := logger.New("test")
+	writer := NewCacheService(newTestRedisCache(t, mr, "c:"), log)
+	reader := NewCacheService(newTestRedisCache(t, mr, "c:"), log)
+
+	created := time.Date(2025, 3, 14, 9, 26, 53, 589793000, time.UTC)
+	secret := "JBSWY3DPEHPK3PXP"
+	step := int64(58_000_123)
+	precision := int32(4)
+
+	usdt := domain.Currency{
+		ID: 1, Code: "USDT", Name: "Tether", Symbol: "₮", IsActive: true, IsCrypto: true,
+		Decimals: 6, Network: "tron", CreatedAt: created, UpdatedAt: created,
+	}
+	kzt := domain.Currency{
+		ID: 2, Code: "KZT", Name: "Tenge", Symbol: "₸", IsActive: true,
+		Decimals: 2, CreatedAt: created, UpdatedAt: created,
+	}
+	user := &domain.User{
+		ID: 7, Email: "user@example.com", PasswordHash: "$2a$10$hash", FirstName: "Aigerim", LastName: "Sadykova",
+		Role: "client", IsActive: true, IsVerified: true, FeeTier: "vip", KYCLevel: 2,
+		CreatedAt: created, UpdatedAt: created,
+		TOTPSecret: &secret, TOTPEnabled: true, TOTPLastStep: &step,
+	}
+	rate := &domain.ExchangeRate{
+		ID: 3, FromCurrencyID: usdt.ID, ToCurrencyID: kzt.ID,
+		Rate: decimal.RequireFromString("512.34567891"), Fee: decimal.RequireFromString("0.5"), IsActive: true,
+		MinAmount: testDecimal("10"), MaxAmount: testDecimal("100000.00000001"), DailyLimit: testDecimal("250000"),
+		AmountPrecision: &precision,
+	}
+	wallet := &domain.Wallet{
+		ID: 11, UserID: user.ID, CurrencyID: usdt.ID,
+		Balance: decimal.RequireFromString("1234.56789012"), Locked: decimal.RequireFromString("0.00000001"),
+		CreatedAt: created, UpdatedAt: created,
+	}
+	session := &domain.UserSession{
+		ID: 21, UserID: user.ID, RefreshTokenHash: "9f86d081884c7d65", ExpiresAt: created.Add(30 * 24 * time.Hour),
+		CreatedAt: created, UserAgent: "curl/8.5.0", IPAddress: "203.0.113.7", LastUsedAt: created,
+	}
+	currencies := []domain.Currency{usdt, kzt}
+	rates := []domain.ExchangeRateWithCurrencies{{ExchangeRate: *rate, FromCurrency: usdt, ToCurrency: kzt}}
+	wallets := []domain.WalletWithCurrency{{Wallet: *wallet, Currency: usdt}}
+	roles := []domain.Role{
+		{Name: "super_admin", Description: "Everything", Permissions: pq.StringArray{"*"}, IsSystem: true, CreatedAt: created, UpdatedAt: created},
+		{Name: "support", Description: "Reads users", Permissions: pq.StringArray{"users:read", "kyc:read"}, CreatedAt: created, UpdatedAt: created},
+	}
+
+	writer.SetUser(user)
+	writer.SetAllCurrencies(currencies)
+	writer.UpdateExchangeRateCache(rate)
+	writer.SetAllExchangeRates(rates)
+	writer.SetActiveExchangeRates(rates)
+	writer.SetWallet(wallet)
+	writer.SetUserWallets(user.ID, wallets)
+	writer.SetSession(session, time.Hour)
+	writer.SetRoles(roles)
+	writer.RevokeSession(session.ID, time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name string
+		get  func() (interface{}, bool)
+		want interface{}
+	}{
+		{"user by id", func() (interface{}, bool) { return reader.GetUser(user.ID) }, user},
+		{"user by email", func() (interface{}, bool) { return reader.GetUserByEmail(user.Email) }, user},
+		{"currency", func() (interface{}, bool) { return reader.GetCurrency("KZT") }, &kzt},
+		{"all currencies", func() (interface{}, bool) { return reader.GetAllCurrencies() }, currencies},
+		{"exchange rate by pair", func() (interface{}, bool) { return reader.GetExchangeRate(usdt.ID, kzt.ID) }, rate},
+		{"exchange rate by id", func() (interface{}, bool) { return reader.GetExchangeRateById(rate.ID) }, rate},
+		{"all exchange rates", func() (interface{}, bool) { return reader.GetAllExchangeRates() }, rates},
+		{"active exchange rates", func() (interface{}, bool) { return reader.GetActiveExchangeRates() }, rates},
+		{"wallet", func() (interface{}, bool) { return reader.GetWallet(user.ID, usdt.ID) }, wallet},
+		{"user wallets", func() (interface{}, bool) { return reader.GetUserWallets(user.ID) }, wallets},
+		{"session", func() (interface{}, bool) { return reader.GetSession(session.RefreshTokenHash) }, session},
+		{"roles", func() (interface{}, bool) { return reader.GetRoles() }, roles},
+		{"revoked session", func() (interface{}, bool) { return reader.IsSessionRevoked(session.ID), true }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := tt.get()
+			if !found {
+				t.Fatal("not found")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	// The revoked_session marker is a bare bool rather than one of the registered types
+	value, found := newTestRedisCache(t, mr, "c:").Get(fmt.Sprintf("revoked_session:%d", session.ID))
+	if !found || value != true {
+		t.Fatalf("revoked_session value = %#v, %v; want true", value, found)
+	}
+}
+
+func TestRedisCacheTTL(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cs := NewCacheService(newTestRedisCache(t, mr, "c:"), logger.New("test"))
+
+	user := &domain.User{ID: 1, Email: "user@example.com"}
+	session := &domain.UserSession{ID: 2, UserID: 1, RefreshTokenHash: "hash"}
+
+	// NoExpiration and 0 both keep the value until it is replaced
+	cs.SetUser(user)
+	cs.SetCurrency(&domain.Currency{ID: 1, Code: "USDT"})
+	cs.SetSession(session, time.Minute)
+	cs.RevokeSession(session.ID, time.Now().Add(time.Minute))
+	cs.RevokeSession(3, time.Now().Add(-time.Second))
+
+	for _, key := range []string{"c:user:1", "c:user:email:user@example.com", "c:currency:USDT"} {
+		if ttl := mr.TTL(key); ttl != 0 {
+			t.Errorf("TTL of %s = %v, want none", key, ttl)
+		}
+	}
+	if ttl := mr.TTL("c:session:hash"); ttl != time.Minute {
+		t.Errorf("TTL of session = %v, want %v", ttl, time.Minute)
+	}
+	if ttl := mr.TTL("c:revoked_session:2"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("TTL of revoked session = %v, want up to %v", ttl, time.Minute)
+	}
+	if cs.IsSessionRevoked(3) {
+		t.Error("session revoked until a time already past is marked revoked")
+	}
+
+	mr.FastForward(time.Minute)
+
+	if _, found := cs.GetSession("hash"); found {
+		t.Error("session found after its TTL")
+	}
+	if cs.IsSessionRevoked(session.ID) {
+		t.Error("session still marked revoked after its access tokens expired")
+	}
+	if _, found := cs.GetUser(user.ID); !found {
+		t.Error("user without TTL expired")
+	}
+}
+
+func TestRedisCacheNoExpirationClearsTTL(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cache := newTestRedisCache(t, mr, "c:")
+
+	if err := cache.Set("key", "first", time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	// A negative ttl must not keep the old expiry, as go-redis's KeepTTL would
+	if err := cache.Set("key", "second", NoExpiration); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if ttl := mr.TTL("c:key"); ttl != 0 {
+		t.Fatalf("TTL = %v, want none", ttl)
+	}
+	if value, found := cache.Get("key"); !found || value != "second" {
+		t.Fatalf("Get = %#v, %v; want second", value, found)
+	}
+}
+
+func TestRedisCacheUndecodableValueIsMiss(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cs := NewCacheService(newTestRedisCache(t, mr, "c:"), logger.New("test"))
+
+	if err := mr.Set("c:user:1", "not gob"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if _, found := cs.GetUser(1); found {
+		t.Fatal("undecodable value returned as a hit")
+	}
+}
+
+func TestRedisCacheFlushKeepsOtherPrefixes(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cache := newTestRedisCache(t, mr, "c:")
+	other := newTestRedisCache(t, mr, "d:")
+
+	// More than one SCAN and DEL batch
+	const keys = 1234
+	for i := 0; i < keys; i++ {
+		if err := cache.Set(fmt.Sprintf("key:%d", i), i, 0); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+	if err := other.Set("key:0", 0, 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := mr.Set("ratelimit:ip:203.0.113.7", "1"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if err := cache.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if got := mr.Keys(); !reflect.DeepEqual(got, []string{"d:key:0", "ratelimit:ip:203.0.113.7"}) {
+		t.Fatalf("keys after Flush = %v", got)
+	}
+	if _, found := other.Get("key:0"); !found {
+		t.Fatal("value under another prefix was flushed")
+	}
+}
@@ -0,0 +1,38 @@
+package chain
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+)
+
+// Transfer is an incoming payment to one address observed in a block. A transaction that
+// pays several outputs yields one Transfer per output, told apart by OutputIndex.
+type Transfer struct {
+	Network     string
+	TxHash      string
+	OutputIndex int
+	Address     string
+	Amount      decimal.Decimal
+	BlockHeight int64
+}
+
+// ChainClient reads incoming transfers from a single blockchain network
+type ChainClient interface {
+	// Network returns the network name as stored in currencies.network
+	Network() string
+
+	// LatestBlock returns the height of the current chain tip
+	LatestBlock(ctx context.Context) (int64, error)
+
+	// GetTransfers returns transfers to any of the addresses in blocks fromBlock..toBlock inclusive
+	GetTransfers(ctx context.Context, fromBlock, toBlock int64, addresses []string) ([]Transfer, error)
+}
+
+// Confirmations returns how many blocks deep a transfer is at the given tip, counting its own block
+func Confirmations(blockHeight, tip int64) int64 {
+	if blockHeight <= 0 || tip < blockHeight {
+		return 0
+	}
+	return tip - blockHeight + 1
+}
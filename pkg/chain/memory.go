@@ -0,0 +1,109 @@
+package chain
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// MemoryChain is an in-process fake chain. Transfers are queued into the next block and
+// become visible once it is mined, so deposit flows can be exercised without a node.
+type MemoryChain struct {
+	network string
+
+	mu      sync.RWMutex
+	height  int64
+	mempool []Transfer
+	mined   []Transfer
+}
+
+func NewMemoryChain(network string) *MemoryChain {
+	return &MemoryChain{network: network}
+}
+
+func (c *MemoryChain) Network() string {
+	return c.network
+}
+
+func (c *MemoryChain) LatestBlock(ctx context.Context) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.height, nil
+}
+
+func (c *MemoryChain) GetTransfers(ctx context.Context, fromBlock, toBlock int64, addresses []string) ([]Transfer, error) {
+	watched := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		watched[address] = struct{}{}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var transfers []Transfer
+	for _, transfer := range c.mined {
+		if transfer.BlockHeight < fromBlock || transfer.BlockHeight > toBlock {
+			continue
+		}
+		if _, ok := watched[transfer.Address]; ok {
+			transfers = append(transfers, transfer)
+		}
+	}
+
+	return transfers, nil
+}
+
+// Send queues a transfer to address for the next block and returns its hash
+func (c *MemoryChain) Send(address string, amount decimal.Decimal) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	transfer := Transfer{
+		Network: c.network,
+		TxHash:  uuid.New().String(),
+		Address: address,
+		Amount:  amount,
+	}
+	c.mempool = append(c.mempool, transfer)
+
+	return transfer.TxHash
+}
+
+// SendBatch queues one transaction paying every output for the next block and returns its hash.
+// Only Address and Amount of each output are used.
+func (c *MemoryChain) SendBatch(outputs ...Transfer) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	txHash := uuid.New().String()
+	for i, output := range outputs {
+		c.mempool = append(c.mempool, Transfer{
+			Network:     c.network,
+			TxHash:      txHash,
+			OutputIndex: i,
+			Address:     output.Address,
+			Amount:      output.Amount,
+		})
+	}
+
+	return txHash
+}
+
+// Mine produces n blocks. Queued transfers land in the first one. Returns the new tip.
+func (c *MemoryChain) Mine(n int) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		c.height++
+		for _, transfer := range c.mempool {
+			transfer.BlockHeight = c.height
+			c.mined = append(c.mined, transfer)
+		}
+		c.mempool = nil
+	}
+
+	return c.height
+}
@@ -18,6 +18,7 @@ type Config struct {
 	App       AppConfig
 	Payment   PaymentConfig
 	Worker    WorkerConfig
+	Storage   StorageConfig
 }
 
 type ServerConfig struct {
@@ -48,8 +49,11 @@ type RedisConfig struct {
 
 type JWTConfig struct {
 	Secret             string
+	Issuer             string // iss claim of every token, checked on verification
 	AccessTokenExpiry  time.Duration
 	RefreshTokenExpiry time.Duration
+	// KeysFile is the manifest of asymmetric signing keys. Tokens are signed HS256 with Secret when empty.
+	KeysFile string
 }
 
 type EmailConfig struct {
@@ -62,9 +66,43 @@ type EmailConfig struct {
 
 type AppConfig struct {
 	BcryptCost         int
-	RateLimitRequests  int
+	RateLimitRequests  int // per client IP across the API
 	RateLimitWindow    time.Duration
 	CORSAllowedOrigins []string
+	IdempotencyKeyTTL  time.Duration
+	ExchangeQuoteTTL   time.Duration
+	KYCMaxDocumentSize int64 // bytes
+
+	FrontendURL          string // base URL for links in emails
+	EmailVerificationTTL time.Duration
+	PasswordResetTTL     time.Duration
+	TOTPIssuer           string // shown in authenticator apps
+	TrustProxyHeaders    bool   // take the client IP from X-Forwarded-For / X-Real-IP
+	// APIKeyEncryptionKey encrypts API key secrets at rest. Derived from JWT_SECRET when empty,
+	// in which case changing JWT_SECRET invalidates every API key.
+	APIKeyEncryptionKey string
+
+	CacheBackend string // "memory" (per server) or "redis" (shared by all servers)
+
+	RateLimitBackend   string // "memory" or "redis"
+	RateLimitAlgorithm string // "sliding_window" or "token_bucket"
+	// RateLimitAuth* limit sign-in, registration and password reset attempts per client IP
+	RateLimitAuthRequests int
+	RateLimitAuthWindow   time.Duration
+	// RateLimitTrade* limit exchanges, quotes and withdrawals per user
+	RateLimitTradeRequests int
+	RateLimitTradeWindow   time.Duration
+
+	// Failed sign-ins lock the email tried, or the client IP, after this many in a row
+	LoginMaxFailures     int
+	LoginIPMaxFailures   int
+	LoginLockoutDuration time.Duration
+	LoginFailureWindow   time.Duration // failures older than this are forgotten
+}
+
+type StorageConfig struct {
+	Driver    string // only "local" is supported
+	LocalPath string
 }
 
 type PaymentConfig struct {
@@ -78,10 +116,19 @@ type PaymentConfig struct {
 }
 
 type WorkerConfig struct {
-	RateUpdateInterval time.Duration
-	RateUpdateTimeout  time.Duration
-	RateUpdateRetries  int
-	RateRetryBackoff   time.Duration
+	RateUpdateInterval    time.Duration
+	RateUpdateTimeout     time.Duration
+	RateUpdateRetries     int
+	RateRetryBackoff      time.Duration
+	ChainPollInterval     time.Duration
+	ChainConfirmations    map[string]int // required confirmations per network
+	ChainMock             bool           // watch in-memory fake chains instead of real nodes
+	RecurringPollInterval time.Duration
+	RecurringMaxFailures  int // consecutive insufficient-balance runs before a schedule is paused
+}
+
+func (c *Config) GetRedisAddr() string {
+	return c.Redis.Host + ":" + c.Redis.Port
 }
 
 func (c *Config) GetDSN() string {
@@ -128,8 +175,10 @@ func Load() (*Config, error) {
 		},
 		JWT: JWTConfig{
 			Secret:             getEnv("JWT_SECRET", ""),
+			Issuer:             getEnv("JWT_ISSUER", "caspianex"),
 			AccessTokenExpiry:  parseDuration(getEnv("JWT_ACCESS_TOKEN_EXPIRY", "15m"), 15*time.Minute),
 			RefreshTokenExpiry: parseDuration(getEnv("JWT_REFRESH_TOKEN_EXPIRY", "168h"), 168*time.Hour),
+			KeysFile:           getEnv("JWT_KEYS_FILE", ""),
 		},
 		Email: EmailConfig{
 			SMTPHost:     getEnv("SMTP_HOST", ""),
@@ -143,6 +192,30 @@ func Load() (*Config, error) {
 			RateLimitRequests:  parseInt(getEnv("RATE_LIMIT_REQUESTS", "100"), 100),
 			RateLimitWindow:    parseDuration(getEnv("RATE_LIMIT_WINDOW", "1m"), 1*time.Minute),
 			CORSAllowedOrigins: parseStringSlice(getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:5173")),
+			IdempotencyKeyTTL:  parseDuration(getEnv("IDEMPOTENCY_KEY_TTL", "24h"), 24*time.Hour),
+			ExchangeQuoteTTL:   parseDuration(getEnv("EXCHANGE_QUOTE_TTL", "15s"), 15*time.Second),
+			KYCMaxDocumentSize: int64(parseInt(getEnv("KYC_MAX_DOCUMENT_SIZE", "10485760"), 10485760)),
+
+			FrontendURL:          getEnv("FRONTEND_URL", "http://localhost:5173"),
+			EmailVerificationTTL: parseDuration(getEnv("EMAIL_VERIFICATION_TTL", "24h"), 24*time.Hour),
+			PasswordResetTTL:     parseDuration(getEnv("PASSWORD_RESET_TTL", "1h"), 1*time.Hour),
+			TOTPIssuer:           getEnv("TOTP_ISSUER", "CaspianEx"),
+			TrustProxyHeaders:    parseBool(getEnv("TRUST_PROXY_HEADERS", "false"), false),
+			APIKeyEncryptionKey:  getEnv("API_KEY_ENCRYPTION_KEY", ""),
+
+			CacheBackend: getEnv("CACHE_BACKEND", "memory"),
+
+			RateLimitBackend:       getEnv("RATE_LIMIT_BACKEND", "memory"),
+			RateLimitAlgorithm:     getEnv("RATE_LIMIT_ALGORITHM", "sliding_window"),
+			RateLimitAuthRequests:  parseInt(getEnv("RATE_LIMIT_AUTH_REQUESTS", "10"), 10),
+			RateLimitAuthWindow:    parseDuration(getEnv("RATE_LIMIT_AUTH_WINDOW", "1m"), 1*time.Minute),
+			RateLimitTradeRequests: parseInt(getEnv("RATE_LIMIT_TRADE_REQUESTS", "30"), 30),
+			RateLimitTradeWindow:   parseDuration(getEnv("RATE_LIMIT_TRADE_WINDOW", "1m"), 1*time.Minute),
+
+			LoginMaxFailures:     parseInt(getEnv("LOGIN_MAX_FAILURES", "10"), 10),
+			LoginIPMaxFailures:   parseInt(getEnv("LOGIN_IP_MAX_FAILURES", "50"), 50),
+			LoginLockoutDuration: parseDuration(getEnv("LOGIN_LOCKOUT_DURATION", "15m"), 15*time.Minute),
+			LoginFailureWindow:   parseDuration(getEnv("LOGIN_FAILURE_WINDOW", "1h"), 1*time.Hour),
 		},
 		Payment: PaymentConfig{
 			CompanyBTCWallet:   getEnv("COMPANY_BTC_WALLET", ""),
@@ -159,10 +232,19 @@ func Load() (*Config, error) {
 			WriteBufferSize: parseInt(getEnv("WEBSOCKET_WRITE_BUFFER_SIZE", "1024"), 1024),
 		},
 		Worker: WorkerConfig{
-			RateUpdateInterval: parseDuration(getEnv("WORKER_RATE_UPDATE_INTERVAL", "2m"), 2*time.Minute),
-			RateUpdateTimeout:  parseDuration(getEnv("WORKER_RATE_UPDATE_TIMEOUT", "30s"), 30*time.Second),
-			RateUpdateRetries:  parseInt(getEnv("WORKER_RATE_UPDATE_RETRIES", "3"), 3),
-			RateRetryBackoff:   parseDuration(getEnv("WORKER_RATE_RETRY_BACKOFF", "5s"), 5*time.Second),
+			RateUpdateInterval:    parseDuration(getEnv("WORKER_RATE_UPDATE_INTERVAL", "2m"), 2*time.Minute),
+			RateUpdateTimeout:     parseDuration(getEnv("WORKER_RATE_UPDATE_TIMEOUT", "30s"), 30*time.Second),
+			RateUpdateRetries:     parseInt(getEnv("WORKER_RATE_UPDATE_RETRIES", "3"), 3),
+			RateRetryBackoff:      parseDuration(getEnv("WORKER_RATE_RETRY_BACKOFF", "5s"), 5*time.Second),
+			ChainPollInterval:     parseDuration(getEnv("WORKER_CHAIN_POLL_INTERVAL", "30s"), 30*time.Second),
+			ChainConfirmations:    parseIntMap(getEnv("CHAIN_CONFIRMATIONS", "bitcoin:2,ethereum:12,tron:20,bsc:15,solana:32,ripple:1,cardano:15,dogecoin:6")),
+			ChainMock:             parseBool(getEnv("CHAIN_MOCK", "false"), false),
+			RecurringPollInterval: parseDuration(getEnv("WORKER_RECURRING_POLL_INTERVAL", "1m"), 1*time.Minute),
+			RecurringMaxFailures:  parseInt(getEnv("RECURRING_EXCHANGE_MAX_FAILURES", "3"), 3),
+		},
+		Storage: StorageConfig{
+			Driver:    getEnv("STORAGE_DRIVER", "local"),
+			LocalPath: getEnv("STORAGE_LOCAL_PATH", "./data/uploads"),
 		},
 	}
 
@@ -214,3 +296,25 @@ func parseStringSlice(value string) []string {
 	}
 	return result
 }
+
+func parseBool(value string, defaultValue bool) bool {
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+	return defaultValue
+}
+
+// parseIntMap parses "key:1,other:2". Malformed entries are skipped.
+func parseIntMap(value string) map[string]int {
+	result := make(map[string]int)
+	for _, part := range parseStringSlice(value) {
+		key, raw, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		if i, err := strconv.Atoi(strings.TrimSpace(raw)); err == nil {
+			result[strings.TrimSpace(key)] = i
+		}
+	}
+	return result
+}
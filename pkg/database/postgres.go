@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -40,3 +41,42 @@ func (p *Postgres) Close() error {
 func (p *Postgres) BeginTx(ctx context.Context) (*sqlx.Tx, error) {
 	return p.DB.BeginTxx(ctx, nil)
 }
+
+// commitTrackerKey carries the flag WithTx sets on commit, see TrackCommits
+type commitTrackerKey struct{}
+
+// TrackCommits returns a context in which WithTx records that it committed, and a function
+// reporting whether it did. A caller that fails after the work was saved can tell it must
+// not be retried. Code committing a transaction from BeginTx calls MarkCommitted itself.
+func TrackCommits(ctx context.Context) (context.Context, func() bool) {
+	committed := new(atomic.Bool)
+	return context.WithValue(ctx, commitTrackerKey{}, committed), committed.Load
+}
+
+// MarkCommitted records a commit for TrackCommits. It does nothing when ctx is not tracked.
+func MarkCommitted(ctx context.Context) {
+	if committed, ok := ctx.Value(commitTrackerKey{}).(*atomic.Bool); ok {
+		committed.Store(true)
+	}
+}
+
+// WithTx runs fn inside a transaction, committing if fn succeeds and rolling back otherwise
+func (p *Postgres) WithTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := p.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	MarkCommitted(ctx)
+
+	return nil
+}
@@ -106,9 +106,9 @@ func (e *EmailService) SendOrderCreatedEmail(to, firstName string, exchange *dom
             <p>Your currency exchange has been completed successfully!</p>
             <div class="info-box">
                 <p><strong>Exchange UID:</strong> <span class="uid">%s</span></p>
-                <p><strong>Amount Exchanged:</strong> %.8f</p>
-                <p><strong>Amount Received:</strong> %.8f (after %.2f%% fee)</p>
-                <p><strong>Exchange Rate:</strong> %.8f</p>
+                <p><strong>Amount Exchanged:</strong> %s</p>
+                <p><strong>Amount Received:</strong> %s (after %s%% fee)</p>
+                <p><strong>Exchange Rate:</strong> %s</p>
             </div>
             <p>The funds have been credited to your wallet.</p>
             <p>Thank you for using CaspianEx!</p>
@@ -117,7 +117,132 @@ func (e *EmailService) SendOrderCreatedEmail(to, firstName string, exchange *dom
     </div>
 </body>
 </html>
-	`, firstName, exchange.UID, exchange.FromAmount, exchange.ToAmountWithFee, exchange.Fee, exchange.ExchangeRate)
+	`, firstName, exchange.UID, exchange.FromAmount.String(), exchange.ToAmountWithFee.String(), exchange.Fee.StringFixed(2), exchange.ExchangeRate.String())
 
 	return e.SendEmail(to, subject, body)
 }
+
+// linkEmailTemplate renders a message with a single call-to-action link
+const linkEmailTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #4CAF50; color: white; padding: 10px; text-align: center; }
+        .content { padding: 20px; background-color: #f9f9f9; }
+        .button { display: inline-block; padding: 10px 20px; background-color: #4CAF50; color: white; text-decoration: none; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>{{.Title}}</h1>
+        </div>
+        <div class="content">
+            <h2>Hello {{.FirstName}},</h2>
+            <p>{{.Message}}</p>
+            <p><a class="button" href="{{.Link}}">{{.Action}}</a></p>
+            <p>This link expires in {{.ExpiresIn}}. If you did not request it, you can ignore this email.</p>
+            <p>Best regards,<br>The CaspianEx Team</p>
+        </div>
+    </div>
+</body>
+</html>
+`
+
+type linkEmail struct {
+	Title     string
+	FirstName string
+	Message   string
+	Action    string
+	Link      string
+	ExpiresIn string
+}
+
+func (e *EmailService) sendLinkEmail(to, subject string, data linkEmail) error {
+	t, err := template.New("link").Parse(linkEmailTemplate)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	if err := t.Execute(&body, data); err != nil {
+		return err
+	}
+
+	return e.SendEmail(to, subject, body.String())
+}
+
+func (e *EmailService) SendVerificationEmail(to, firstName, link, expiresIn string) error {
+	return e.sendLinkEmail(to, "Verify your CaspianEx email", linkEmail{
+		Title:     "Verify your email",
+		FirstName: firstName,
+		Message:   "Please confirm your email address to finish setting up your CaspianEx account.",
+		Action:    "Verify email",
+		Link:      link,
+		ExpiresIn: expiresIn,
+	})
+}
+
+func (e *EmailService) SendPasswordResetEmail(to, firstName, link, expiresIn string) error {
+	return e.sendLinkEmail(to, "Reset your CaspianEx password", linkEmail{
+		Title:     "Reset your password",
+		FirstName: firstName,
+		Message:   "We received a request to reset your password. Resetting it signs you out on all devices.",
+		Action:    "Reset password",
+		Link:      link,
+		ExpiresIn: expiresIn,
+	})
+}
+
+// SendAccountLockedEmail tells the owner that sign-in was locked after repeated failed attempts
+func (e *EmailService) SendAccountLockedEmail(to, firstName, ipAddress, lockedFor, resetLink string) error {
+	tmpl := `
+<!DOCTYPE html>
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #d9534f; color: white; padding: 10px; text-align: center; }
+        .content { padding: 20px; background-color: #f9f9f9; }
+        .button { display: inline-block; padding: 10px 20px; background-color: #4CAF50; color: white; text-decoration: none; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Sign-in temporarily locked</h1>
+        </div>
+        <div class="content">
+            <h2>Hello {{.FirstName}},</h2>
+            <p>We saw several failed attempts to sign in to your CaspianEx account, most recently from {{.IPAddress}}. To protect your account, signing in is locked for {{.LockedFor}}.</p>
+            <p>If this was you, wait and try again. If it was not, we recommend resetting your password.</p>
+            <p><a class="button" href="{{.ResetLink}}">Reset password</a></p>
+            <p>Best regards,<br>The CaspianEx Team</p>
+        </div>
+    </div>
+</body>
+</html>
+`
+	t, err := template.New("account_locked").Parse(tmpl)
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		FirstName string
+		IPAddress string
+		LockedFor string
+		ResetLink string
+	}{firstName, ipAddress, lockedFor, resetLink}
+
+	var body bytes.Buffer
+	if err := t.Execute(&body, data); err != nil {
+		return err
+	}
+
+	return e.SendEmail(to, "Your CaspianEx sign-in has been locked", body.String())
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is how often idle keys are dropped
+const memorySweepInterval = time.Minute
+
+// MemoryStore keeps limiter state in process. Each server enforces its own limits, so use
+// RedisStore when running more than one.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	nextSweep time.Time
+}
+
+type memoryEntry struct {
+	bucket  bucketState
+	window  windowState
+	expires time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]*memoryEntry),
+	}
+}
+
+func (s *MemoryStore) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.After(s.nextSweep) {
+		for k, entry := range s.entries {
+			if now.After(entry.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.nextSweep = now.Add(memorySweepInterval)
+	}
+
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &memoryEntry{}
+		s.entries[key] = entry
+	}
+
+	// A full bucket or a key idle for two windows is the same as no state at all
+	if limit.Algorithm == TokenBucket {
+		entry.expires = now.Add(limit.Window)
+		return entry.bucket.take(limit, now), nil
+	}
+	entry.expires = now.Add(2 * limit.Window)
+	return entry.window.count(limit, now), nil
+}
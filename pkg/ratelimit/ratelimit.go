@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Algorithm decides how requests are counted against a Limit
+type Algorithm string
+
+const (
+	// TokenBucket refills Requests tokens evenly over Window and allows bursts up to Requests
+	TokenBucket Algorithm = "token_bucket"
+	// SlidingWindow counts requests in the last Window, estimated from the current and
+	// previous fixed windows
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+func ParseAlgorithm(s string) (Algorithm, error) {
+	switch Algorithm(s) {
+	case TokenBucket, SlidingWindow:
+		return Algorithm(s), nil
+	}
+	return "", fmt.Errorf("unknown rate limit algorithm %q", s)
+}
+
+// Limit allows Requests per Window
+type Limit struct {
+	Requests  int
+	Window    time.Duration
+	Algorithm Algorithm
+}
+
+// Result is the outcome of counting one request
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is how long until the bucket is full again, or until the current window ends
+	ResetAfter time.Duration
+	// RetryAfter is how long a rejected caller has to wait before a request is allowed
+	RetryAfter time.Duration
+}
+
+// Store counts requests per key. Each call must be atomic, so servers sharing a store enforce
+// a single limit.
+type Store interface {
+	Allow(ctx context.Context, key string, limit Limit) (*Result, error)
+}
+
+// bucketState is a token bucket: the tokens left at the time of the last request
+type bucketState struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket up to now and takes a token if one is available
+func (b *bucketState) take(limit Limit, now time.Time) *Result {
+	capacity := float64(limit.Requests)
+	perNano := capacity / float64(limit.Window)
+
+	if b.last.IsZero() {
+		b.tokens = capacity
+	} else if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+float64(elapsed)*perNano)
+	}
+	b.last = now
+
+	result := &Result{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - b.tokens) / perNano))
+	}
+	result.Remaining = int(b.tokens)
+	result.ResetAfter = time.Duration(math.Ceil((capacity - b.tokens) / perNano))
+	return result
+}
+
+// windowState holds the counts of the current fixed window and the one before it
+type windowState struct {
+	start    time.Time
+	previous int
+	current  int
+}
+
+// count moves the window up to now and counts the request if the estimated number of
+// requests in the last Window is below the limit
+func (w *windowState) count(limit Limit, now time.Time) *Result {
+	start := now.Truncate(limit.Window)
+	if !w.start.Equal(start) {
+		if w.start.Equal(start.Add(-limit.Window)) {
+			w.previous = w.current
+		} else {
+			w.previous = 0
+		}
+		w.current = 0
+		w.start = start
+	}
+
+	elapsed := now.Sub(start)
+	window := float64(limit.Window)
+	estimate := float64(w.previous)*(1-float64(elapsed)/window) + float64(w.current)
+
+	result := &Result{Limit: limit.Requests, ResetAfter: limit.Window - elapsed}
+	if estimate+1 <= float64(limit.Requests) {
+		w.current++
+		estimate++
+		result.Allowed = true
+	} else {
+		result.RetryAfter = slidingRetryAfter(limit, w.previous, w.current, elapsed)
+	}
+	result.Remaining = max(0, int(float64(limit.Requests)-estimate))
+	return result
+}
+
+// slidingRetryAfter returns how long until the previous window's weight has fallen far enough
+// for one more request. When the current window alone is at the limit that is in the next one.
+func slidingRetryAfter(limit Limit, previous, current int, elapsed time.Duration) time.Duration {
+	window := float64(limit.Window)
+	allowed := float64(limit.Requests - 1)
+
+	if float64(current) <= allowed && previous > 0 {
+		return time.Duration(math.Ceil(window*(1-(allowed-float64(current))/float64(previous)))) - elapsed
+	}
+	return limit.Window - elapsed + time.Duration(math.Ceil(window*(1-allowed/float64(current))))
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The scripts mirror bucketState.take and windowState.count. Times are in microseconds from
+// the Redis clock, so servers with skewed clocks still share one limit.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local rate = capacity / window
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(state[1]) or capacity
+local last = tonumber(state[2]) or now
+if now > last then
+  tokens = math.min(capacity, tokens + (now - last) * rate)
+end
+
+local allowed, retry = 0, 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(window / 1000))
+return {allowed, math.floor(tokens), math.ceil((capacity - tokens) / rate), retry}
+`)
+
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local start = now - (now % window)
+
+local state = redis.call('HMGET', KEYS[1], 'start', 'previous', 'current')
+local previous = tonumber(state[2]) or 0
+local current = tonumber(state[3]) or 0
+local last = tonumber(state[1])
+if last ~= start then
+  if last == start - window then previous = current else previous = 0 end
+  current = 0
+end
+
+local elapsed = now - start
+local estimate = previous * (1 - elapsed / window) + current
+local allowed, retry = 0, 0
+if estimate + 1 <= limit then
+  current = current + 1
+  estimate = estimate + 1
+  allowed = 1
+elseif current <= limit - 1 and previous > 0 then
+  retry = math.ceil(window * (1 - (limit - 1 - current) / previous)) - elapsed
+else
+  retry = window - elapsed + math.ceil(window * (1 - (limit - 1) / current))
+end
+
+redis.call('HSET', KEYS[1], 'start', start, 'previous', previous, 'current', current)
+redis.call('PEXPIRE', KEYS[1], math.ceil(2 * window / 1000))
+return {allowed, math.max(0, math.floor(limit - estimate)), window - elapsed, retry}
+`)
+
+// RedisStore keeps limiter state in Redis so every server enforces the same limits. It takes
+// a redis.Scripter, so tests can run it against an in-process Redis fake.
+type RedisStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+// NewRedisStore stores state under keys starting with prefix
+func NewRedisStore(client redis.Scripter, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *RedisStore) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	script := slidingWindowScript
+	if limit.Algorithm == TokenBucket {
+		script = tokenBucketScript
+	}
+
+	values, err := script.Run(ctx, s.client, []string{s.prefix + key}, limit.Requests, limit.Window.Microseconds()).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("unexpected rate limit script result %v", values)
+	}
+
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit.Requests,
+		Remaining:  int(values[1]),
+		ResetAfter: time.Duration(values[2]) * time.Microsecond,
+		RetryAfter: time.Duration(values[3]) * time.Microsecond,
+	}, nil
+}
@@ -0,0 +1,184 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// scriptTolerance allows for the scripts rounding up to whole microseconds where the Go
+// limiters round up to nanoseconds
+const scriptTolerance = 2 * time.Microsecond
+
+// testEpoch is aligned to every window used below, on both the Unix clock the scripts see
+// and the zero time time.Truncate counts from
+var testEpoch = time.Unix(1_700_000_000, 0)
+
+func newTestRedisStore(t *testing.T) (*miniredis.Miniredis, *RedisStore) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return mr, NewRedisStore(client, "rl:")
+}
+
+// TestRedisStoreMatchesMemory replays the same requests through the Lua scripts and the Go
+// limiters and expects the same results at every step
+func TestRedisStoreMatchesMemory(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit Limit
+		// at is when each request arrives, after testEpoch
+		at []time.Duration
+	}{
+		{
+			name:  "token bucket",
+			limit: Limit{Requests: 5, Window: time.Second, Algorithm: TokenBucket},
+			at: []time.Duration{
+				0, 0, 0, 0, 0, 0,
+				50 * time.Millisecond,
+				130 * time.Millisecond,
+				250 * time.Millisecond,
+				250 * time.Millisecond,
+				1700 * time.Millisecond,
+				1700 * time.Millisecond,
+				1733 * time.Millisecond,
+			},
+		},
+		{
+			name:  "sliding window",
+			limit: Limit{Requests: 4, Window: time.Second, Algorithm: SlidingWindow},
+			at: []time.Duration{
+				100 * time.Millisecond,
+				200 * time.Millisecond,
+				300 * time.Millisecond,
+				400 * time.Millisecond,
+				500 * time.Millisecond,
+				1100 * time.Millisecond,
+				1300 * time.Millisecond,
+				1600 * time.Millisecond,
+				1650 * time.Millisecond,
+				1999 * time.Millisecond,
+				3500 * time.Millisecond,
+			},
+		},
+		{
+			name:  "sliding window with a full current window",
+			limit: Limit{Requests: 2, Window: 10 * time.Second, Algorithm: SlidingWindow},
+			at: []time.Duration{
+				time.Second,
+				time.Second,
+				time.Second,
+				9 * time.Second,
+				10 * time.Second,
+				12500 * time.Millisecond,
+				17 * time.Second,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr, store := newTestRedisStore(t)
+			var bucket bucketState
+			var window windowState
+
+			allowed, rejected := 0, 0
+			for i, offset := range tt.at {
+				now := testEpoch.Add(offset)
+				mr.SetTime(now)
+
+				got, err := store.Allow(context.Background(), "key", tt.limit)
+				if err != nil {
+					t.Fatalf("request %d: Allow: %v", i, err)
+				}
+
+				var want *Result
+				if tt.limit.Algorithm == TokenBucket {
+					want = bucket.take(tt.limit, now)
+				} else {
+					want = window.count(tt.limit, now)
+				}
+
+				if got.Allowed != want.Allowed || got.Limit != want.Limit || got.Remaining != want.Remaining ||
+					!within(got.ResetAfter, want.ResetAfter) || !within(got.RetryAfter, want.RetryAfter) {
+					t.Fatalf("request %d at %v: script returned %+v, Go returned %+v", i, offset, *got, *want)
+				}
+
+				if got.Allowed {
+					allowed++
+				} else {
+					rejected++
+				}
+			}
+
+			if allowed == 0 || rejected == 0 {
+				t.Fatalf("sequence should both allow and reject requests, got %d allowed and %d rejected", allowed, rejected)
+			}
+		})
+	}
+}
+
+func TestRedisStoreExpiresIdleKeys(t *testing.T) {
+	tests := []struct {
+		limit Limit
+		ttl   time.Duration
+	}{
+		{Limit{Requests: 3, Window: time.Minute, Algorithm: TokenBucket}, time.Minute},
+		{Limit{Requests: 3, Window: time.Minute, Algorithm: SlidingWindow}, 2 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.limit.Algorithm), func(t *testing.T) {
+			mr, store := newTestRedisStore(t)
+			mr.SetTime(testEpoch)
+
+			if _, err := store.Allow(context.Background(), "key", tt.limit); err != nil {
+				t.Fatalf("Allow: %v", err)
+			}
+
+			if got := mr.TTL("rl:key"); got != tt.ttl {
+				t.Fatalf("TTL = %v, want %v", got, tt.ttl)
+			}
+
+			mr.FastForward(tt.ttl)
+			if mr.Exists("rl:key") {
+				t.Fatal("key still exists after its TTL")
+			}
+		})
+	}
+}
+
+func TestRedisStoreSeparatesKeys(t *testing.T) {
+	_, store := newTestRedisStore(t)
+	limit := Limit{Requests: 1, Window: time.Minute, Algorithm: TokenBucket}
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b"} {
+		result, err := store.Allow(ctx, key, limit)
+		if err != nil {
+			t.Fatalf("Allow(%s): %v", key, err)
+		}
+		if !result.Allowed {
+			t.Fatalf("first request for %s was rejected", key)
+		}
+	}
+
+	result, err := store.Allow(ctx, "a", limit)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if result.Allowed {
+		t.Fatal("second request for a was allowed")
+	}
+}
+
+func within(got, want time.Duration) bool {
+	diff := got - want
+	return diff >= -scriptTolerance && diff <= scriptTolerance
+}
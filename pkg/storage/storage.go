@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when no blob is stored under a key
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStorage stores opaque files under slash-separated keys, e.g. "kyc/42/<uuid>"
+type BlobStorage interface {
+	// Put stores the contents of r under key, replacing any existing blob
+	Put(ctx context.Context, key string, r io.Reader) error
+
+	// Get opens the blob stored under key. The caller must close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the blob stored under key. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+}
@@ -1,13 +1,25 @@
 package validator
 
 import (
+	"reflect"
+
 	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
 )
 
 var validate *validator.Validate
 
 func init() {
 	validate = validator.New()
+
+	// Lets numeric tags such as gt=0 work on decimal amounts
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if d, ok := field.Interface().(decimal.Decimal); ok {
+			f, _ := d.Float64()
+			return f
+		}
+		return nil
+	}, decimal.Decimal{})
 }
 
 func Validate(data interface{}) error {
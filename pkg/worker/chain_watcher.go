@@ -0,0 +1,264 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/caspianex/exchange-backend/internal/service"
+	"github.com/caspianex/exchange-backend/pkg/chain"
+	"github.com/caspianex/exchange-backend/pkg/logger"
+)
+
+// ChainWatcherConfig holds configuration for the chain watcher worker
+type ChainWatcherConfig struct {
+	PollInterval     time.Duration  // How often to poll every network
+	PollTimeout      time.Duration  // Timeout for one poll of all networks
+	Confirmations    map[string]int // Confirmations required per network before crediting
+	MaxBlocksPerPoll int64          // Upper bound on blocks scanned per network per poll
+}
+
+// DefaultChainWatcherConfig returns sensible defaults
+func DefaultChainWatcherConfig() ChainWatcherConfig {
+	return ChainWatcherConfig{
+		PollInterval:     30 * time.Second,
+		PollTimeout:      20 * time.Second,
+		Confirmations:    map[string]int{},
+		MaxBlocksPerPoll: 500,
+	}
+}
+
+// ChainWatcher is a background worker that scans chains for transfers to allocated
+// deposit addresses and credits them once they are deep enough
+type ChainWatcher struct {
+	config         ChainWatcherConfig
+	clients        []chain.ChainClient
+	depositService *service.ChainDepositService
+	log            *logger.Logger
+
+	// State management
+	running     atomic.Bool
+	mu          sync.Mutex
+	lastRunTime time.Time
+	lastError   error
+	runCount    uint64
+	failCount   uint64
+
+	// Lifecycle
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewChainWatcher creates a new chain watcher worker
+func NewChainWatcher(
+	config ChainWatcherConfig,
+	clients []chain.ChainClient,
+	depositService *service.ChainDepositService,
+	log *logger.Logger,
+) *ChainWatcher {
+	return &ChainWatcher{
+		config:         config,
+		clients:        clients,
+		depositService: depositService,
+		log:            log,
+		stopChan:       make(chan struct{}),
+		doneChan:       make(chan struct{}),
+	}
+}
+
+// Start begins polling. It is a no-op when no chain clients are configured.
+func (cw *ChainWatcher) Start(ctx context.Context) {
+	if len(cw.clients) == 0 {
+		cw.log.Info("No chain clients configured, chain watcher disabled")
+		return
+	}
+
+	if !cw.running.CompareAndSwap(false, true) {
+		cw.log.Warn("Chain watcher is already running")
+		return
+	}
+
+	cw.log.Info("Starting chain watcher",
+		"interval", cw.config.PollInterval,
+		"networks", len(cw.clients),
+	)
+
+	go cw.run(ctx)
+}
+
+// Stop gracefully stops the watcher
+func (cw *ChainWatcher) Stop() {
+	if !cw.running.Load() {
+		return
+	}
+
+	cw.log.Info("Stopping chain watcher")
+	close(cw.stopChan)
+
+	select {
+	case <-cw.doneChan:
+		cw.log.Info("Chain watcher stopped gracefully")
+	case <-time.After(10 * time.Second):
+		cw.log.Warn("Chain watcher stop timeout")
+	}
+}
+
+// run is the main worker loop
+func (cw *ChainWatcher) run(ctx context.Context) {
+	defer close(cw.doneChan)
+	defer cw.running.Store(false)
+
+	ticker := time.NewTicker(cw.config.PollInterval)
+	defer ticker.Stop()
+
+	cw.executePoll(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			cw.log.Info("Chain watcher stopped due to context cancellation")
+			return
+
+		case <-cw.stopChan:
+			cw.log.Info("Chain watcher stopped via Stop()")
+			return
+
+		case <-ticker.C:
+			cw.executePoll(ctx)
+		}
+	}
+}
+
+// executePoll polls every network once. A failing network does not hold back the others;
+// it is picked up again on the next tick from its stored cursor.
+func (cw *ChainWatcher) executePoll(parentCtx context.Context) {
+	if !cw.mu.TryLock() {
+		cw.log.Warn("Skipping chain poll - previous poll still in progress")
+		return
+	}
+	defer cw.mu.Unlock()
+
+	atomic.AddUint64(&cw.runCount, 1)
+
+	ctx, cancel := context.WithTimeout(parentCtx, cw.config.PollTimeout)
+	defer cancel()
+
+	var lastErr error
+	for _, client := range cw.clients {
+		if err := cw.pollNetwork(ctx, client); err != nil {
+			lastErr = err
+			cw.log.Error("Chain poll failed", "network", client.Network(), "error", err)
+		}
+	}
+
+	cw.lastRunTime = time.Now()
+	cw.lastError = lastErr
+	if lastErr != nil {
+		atomic.AddUint64(&cw.failCount, 1)
+	}
+}
+
+// pollNetwork books new transfers since the stored cursor, then advances confirmations
+// of every pending deposit on the network
+func (cw *ChainWatcher) pollNetwork(ctx context.Context, client chain.ChainClient) error {
+	network := client.Network()
+
+	tip, err := client.LatestBlock(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	cursor, found, err := cw.depositService.GetCursor(ctx, network)
+	if err != nil {
+		return fmt.Errorf("failed to get cursor: %w", err)
+	}
+	if !found {
+		// Start near the tip instead of replaying the whole chain
+		cursor = max(tip-cw.config.MaxBlocksPerPoll, 0)
+	}
+
+	if tip > cursor {
+		toBlock := min(tip, cursor+cw.config.MaxBlocksPerPoll)
+
+		addresses, err := cw.depositService.GetWatchedAddresses(ctx, network)
+		if err != nil {
+			return fmt.Errorf("failed to get watched addresses: %w", err)
+		}
+
+		if len(addresses) > 0 {
+			transfers, err := client.GetTransfers(ctx, cursor+1, toBlock, addresses)
+			if err != nil {
+				return fmt.Errorf("failed to get transfers: %w", err)
+			}
+
+			for _, transfer := range transfers {
+				tx, err := cw.depositService.RecordTransfer(ctx, transfer)
+				if err != nil {
+					return fmt.Errorf("failed to record transfer %s: %w", transfer.TxHash, err)
+				}
+				if tx != nil {
+					cw.log.Info("Deposit detected",
+						"network", network,
+						"tx_hash", transfer.TxHash,
+						"output", transfer.OutputIndex,
+						"amount", tx.Amount.String(),
+						"block", transfer.BlockHeight,
+					)
+				}
+			}
+		}
+
+		if err := cw.depositService.SetCursor(ctx, network, toBlock); err != nil {
+			return fmt.Errorf("failed to store cursor: %w", err)
+		}
+	}
+
+	pending, err := cw.depositService.GetPendingDeposits(ctx, network)
+	if err != nil {
+		return fmt.Errorf("failed to get pending deposits: %w", err)
+	}
+
+	required := int64(max(cw.config.Confirmations[network], 1))
+	for _, tx := range pending {
+		confirmations := chain.Confirmations(*tx.BlockHeight, tip)
+		credited, err := cw.depositService.ConfirmDeposit(ctx, tx.ID, confirmations, required)
+		if err != nil {
+			return fmt.Errorf("failed to confirm deposit %d: %w", tx.ID, err)
+		}
+		if credited {
+			cw.log.Info("Deposit credited",
+				"network", network,
+				"tx_hash", tx.TxHash,
+				"transaction_id", tx.ID,
+				"confirmations", confirmations,
+			)
+		}
+	}
+
+	return nil
+}
+
+// Health returns the current health status of the worker
+func (cw *ChainWatcher) Health() HealthStatus {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	status := HealthStatus{
+		Running:     cw.running.Load(),
+		LastRunTime: cw.lastRunTime,
+		RunCount:    atomic.LoadUint64(&cw.runCount),
+		FailCount:   atomic.LoadUint64(&cw.failCount),
+	}
+
+	if cw.lastError != nil {
+		status.LastError = cw.lastError.Error()
+	}
+
+	if !cw.lastRunTime.IsZero() {
+		status.Uptime = time.Since(cw.lastRunTime).String()
+	}
+
+	return status
+}
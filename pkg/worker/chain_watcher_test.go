@@ -0,0 +1,225 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caspianex/exchange-backend/const/queries"
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/repository"
+	"github.com/caspianex/exchange-backend/internal/service"
+	"github.com/caspianex/exchange-backend/internal/testdb"
+	"github.com/caspianex/exchange-backend/pkg/cache"
+	"github.com/caspianex/exchange-backend/pkg/chain"
+	"github.com/caspianex/exchange-backend/pkg/database"
+	"github.com/caspianex/exchange-backend/pkg/logger"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+)
+
+// depositFixture is a chain watcher over a MemoryChain, requiring 3 confirmations, and a
+// user with an allocated USDT deposit address
+type depositFixture struct {
+	db             *database.Postgres
+	ledgerService  *service.LedgerService
+	depositService *service.ChainDepositService
+	network        string
+	wallet         *domain.Wallet
+	address        string
+	chain          *chain.MemoryChain
+	watcher        *ChainWatcher
+}
+
+func newDepositFixture(t *testing.T) *depositFixture {
+	t.Helper()
+
+	db := testdb.Open(t)
+	ctx := context.Background()
+	log := logger.New("test")
+	cacheService := cache.NewCacheService(cache.NewMemoryCache(time.Hour, time.Hour), log)
+
+	userRepo := repository.NewUserRepository(db, cacheService)
+	walletRepo := repository.NewWalletRepository(db, cacheService)
+	depositAddressRepo := repository.NewDepositAddressRepository(db)
+	ledgerService := service.NewLedgerService(repository.NewLedgerRepository(db))
+	depositService := service.NewChainDepositService(db, walletRepo, repository.NewTransactionRepository(db),
+		depositAddressRepo, repository.NewChainCursorRepository(db), ledgerService)
+
+	usdt, err := walletRepo.GetCurrencyByCode(ctx, "USDT")
+	if err != nil {
+		t.Fatalf("GetCurrencyByCode: %v", err)
+	}
+
+	user := &domain.User{Email: "deposits@example.com", PasswordHash: "unused", FirstName: "Test", LastName: "User",
+		Role: domain.UserRoleClient, IsActive: true, IsVerified: true}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	wallet := &domain.Wallet{UserID: user.ID, CurrencyID: usdt.ID, Balance: decimal.Zero, Locked: decimal.Zero}
+	if err := walletRepo.Create(ctx, wallet); err != nil {
+		t.Fatalf("create wallet: %v", err)
+	}
+
+	if _, err := depositAddressRepo.AddToPool(ctx, usdt, []string{"TWatchedAddress", "TFreeAddress"}); err != nil {
+		t.Fatalf("AddToPool: %v", err)
+	}
+	var address *domain.DepositAddress
+	err = db.WithTx(ctx, func(tx *sqlx.Tx) error {
+		address, err = depositAddressRepo.AllocateFromPoolTx(ctx, tx, user.ID, usdt.ID)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("AllocateFromPoolTx: %v", err)
+	}
+
+	memoryChain := chain.NewMemoryChain(usdt.Network)
+	config := DefaultChainWatcherConfig()
+	config.Confirmations = map[string]int{usdt.Network: 3}
+
+	return &depositFixture{
+		db:             db,
+		ledgerService:  ledgerService,
+		depositService: depositService,
+		network:        usdt.Network,
+		wallet:         wallet,
+		address:        address.Address,
+		chain:          memoryChain,
+		watcher:        NewChainWatcher(config, []chain.ChainClient{memoryChain}, depositService, log),
+	}
+}
+
+// poll runs one poll of every network and returns its error, if any
+func (f *depositFixture) poll() string {
+	f.watcher.executePoll(context.Background())
+	return f.watcher.Health().LastError
+}
+
+func (f *depositFixture) mustPoll(t *testing.T) {
+	t.Helper()
+	if err := f.poll(); err != "" {
+		t.Fatalf("poll failed: %s", err)
+	}
+}
+
+func (f *depositFixture) assertBalance(t *testing.T, want string) {
+	t.Helper()
+	var got domain.Wallet
+	if err := f.db.GetContext(context.Background(), &got, queries.WalletGetByIDQuery, f.wallet.ID); err != nil {
+		t.Fatalf("read wallet: %v", err)
+	}
+	if !got.Balance.Equal(decimal.RequireFromString(want)) {
+		t.Fatalf("balance = %s, want %s", got.Balance, want)
+	}
+}
+
+func (f *depositFixture) assertDeposits(t *testing.T, status domain.TransactionStatus, want int) {
+	t.Helper()
+	var got int
+	err := f.db.GetContext(context.Background(), &got,
+		`SELECT COUNT(*) FROM transactions WHERE wallet_id = $1 AND type = $2 AND status = $3`,
+		f.wallet.ID, domain.TransactionTypeDeposit, status)
+	if err != nil {
+		t.Fatalf("count deposits: %v", err)
+	}
+	if got != want {
+		t.Fatalf("%d %s deposits, want %d", got, status, want)
+	}
+}
+
+func (f *depositFixture) assertCursor(t *testing.T, want int64) {
+	t.Helper()
+	got, found, err := f.depositService.GetCursor(context.Background(), f.network)
+	if err != nil {
+		t.Fatalf("GetCursor: %v", err)
+	}
+	if !found || got != want {
+		t.Fatalf("cursor = %d (found %v), want %d", got, found, want)
+	}
+}
+
+// TestChainWatcherCreditsConfirmedDepositsOnce sends transfers on a MemoryChain and polls
+// the watcher as the chain grows. Deposits are credited once deep enough, each output of a
+// batch transaction separately, and rescanning the blocks credits nothing again.
+func TestChainWatcherCreditsConfirmedDepositsOnce(t *testing.T) {
+	f := newDepositFixture(t)
+	ctx := context.Background()
+
+	// Transfers to addresses nobody was given are ignored
+	f.chain.Send(f.address, decimal.RequireFromString("25.5"))
+	f.chain.Send("TFreeAddress", decimal.RequireFromString("7"))
+	f.chain.SendBatch(
+		chain.Transfer{Address: f.address, Amount: decimal.RequireFromString("1.25")},
+		chain.Transfer{Address: "TFreeAddress", Amount: decimal.RequireFromString("3")},
+		chain.Transfer{Address: f.address, Amount: decimal.RequireFromString("2")},
+	)
+	f.chain.Mine(1)
+
+	f.mustPoll(t)
+	f.assertDeposits(t, domain.TransactionStatusPending, 3)
+	f.assertBalance(t, "0")
+
+	f.chain.Mine(1)
+	f.mustPoll(t)
+	f.assertDeposits(t, domain.TransactionStatusPending, 3)
+	f.assertBalance(t, "0")
+
+	// The third block is the third confirmation
+	f.chain.Mine(1)
+	f.mustPoll(t)
+	f.assertDeposits(t, domain.TransactionStatusPending, 0)
+	f.assertDeposits(t, domain.TransactionStatusCompleted, 3)
+	f.assertBalance(t, "28.75")
+
+	// Rescan every block, as after a restore of the cursor or a reorg
+	if err := f.depositService.SetCursor(ctx, f.network, 0); err != nil {
+		t.Fatalf("SetCursor: %v", err)
+	}
+	f.chain.Mine(1)
+	f.mustPoll(t)
+	f.mustPoll(t)
+	f.assertDeposits(t, domain.TransactionStatusPending, 0)
+	f.assertDeposits(t, domain.TransactionStatusCompleted, 3)
+	f.assertBalance(t, "28.75")
+
+	mismatches, err := f.ledgerService.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	for _, m := range mismatches {
+		t.Errorf("wallet differs from the ledger: %+v", m)
+	}
+}
+
+// TestChainWatcherKeepsCursorWhenAddressLookupFails checks a transfer whose address cannot
+// be looked up is retried on the next poll rather than skipped for good
+func TestChainWatcherKeepsCursorWhenAddressLookupFails(t *testing.T) {
+	f := newDepositFixture(t)
+	ctx := context.Background()
+
+	if err := f.depositService.SetCursor(ctx, f.network, 0); err != nil {
+		t.Fatalf("SetCursor: %v", err)
+	}
+	f.chain.Send(f.address, decimal.RequireFromString("10"))
+	f.chain.Mine(1)
+
+	// An extra column breaks the SELECT * of the address lookup only; listing the watched
+	// addresses still works, so the poll gets as far as the transfer
+	if _, err := f.db.ExecContext(ctx, `ALTER TABLE deposit_addresses ADD COLUMN broken INTEGER`); err != nil {
+		t.Fatalf("break address lookup: %v", err)
+	}
+
+	if err := f.poll(); err == "" {
+		t.Fatal("poll succeeded although the address lookup failed")
+	}
+	f.assertCursor(t, 0)
+	f.assertDeposits(t, domain.TransactionStatusPending, 0)
+
+	if _, err := f.db.ExecContext(ctx, `ALTER TABLE deposit_addresses DROP COLUMN broken`); err != nil {
+		t.Fatalf("restore address lookup: %v", err)
+	}
+
+	f.mustPoll(t)
+	f.assertCursor(t, 1)
+	f.assertDeposits(t, domain.TransactionStatusPending, 1)
+}
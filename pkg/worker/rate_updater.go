@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -15,10 +14,14 @@ import (
 	"github.com/caspianex/exchange-backend/internal/repository"
 	"github.com/caspianex/exchange-backend/internal/service"
 	"github.com/caspianex/exchange-backend/pkg/logger"
+	"github.com/shopspring/decimal"
 )
 
 const BINANCE_API = `https://api.binance.com/api/v3/ticker/price?symbol=%s`
 
+// rateScale matches the DECIMAL(20,8) precision of exchange_rates.rate
+const rateScale = 8
+
 // RateUpdaterConfig holds configuration for the rate updater worker
 type RateUpdaterConfig struct {
 	UpdateInterval time.Duration // How often to update rates
@@ -37,10 +40,17 @@ func DefaultRateUpdaterConfig() RateUpdaterConfig {
 	}
 }
 
+// ExchangeMatcher settles pending limit exchanges against freshly updated rates.
+// Returns how many exchanges were executed and how many expired.
+type ExchangeMatcher interface {
+	MatchPendingExchanges(ctx context.Context) (int, int, error)
+}
+
 // RateUpdater is a background worker that periodically updates exchange rates
 type RateUpdater struct {
 	config          RateUpdaterConfig
 	exchangeService *service.ExchangeRatesService
+	matcher         ExchangeMatcher
 	log             *logger.Logger
 
 	// State management
@@ -60,11 +70,13 @@ type RateUpdater struct {
 func NewRateUpdater(
 	config RateUpdaterConfig,
 	exchangeService *service.ExchangeRatesService,
+	matcher ExchangeMatcher,
 	log *logger.Logger,
 ) *RateUpdater {
 	return &RateUpdater{
 		config:          config,
 		exchangeService: exchangeService,
+		matcher:         matcher,
 		log:             log,
 		stopChan:        make(chan struct{}),
 		doneChan:        make(chan struct{}),
@@ -139,37 +151,37 @@ type BinanceTickerResponse struct {
 }
 
 // fetchBinancePrice fetches price from Binance API for a given symbol
-func (ru *RateUpdater) fetchBinancePrice(ctx context.Context, symbol string) (float64, error) {
+func (ru *RateUpdater) fetchBinancePrice(ctx context.Context, symbol string) (decimal.Decimal, error) {
 	url := fmt.Sprintf(BINANCE_API, symbol)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return 0, fmt.Errorf("failed to create request: %w", err)
+		return decimal.Zero, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return 0, fmt.Errorf("failed to fetch from Binance: %w", err)
+		return decimal.Zero, fmt.Errorf("failed to fetch from Binance: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("binance API returned status %d", resp.StatusCode)
+		return decimal.Zero, fmt.Errorf("binance API returned status %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read response body: %w", err)
+		return decimal.Zero, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	var ticker BinanceTickerResponse
 	if err := json.Unmarshal(body, &ticker); err != nil {
-		return 0, fmt.Errorf("failed to parse response: %w", err)
+		return decimal.Zero, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	price, err := strconv.ParseFloat(ticker.Price, 64)
+	price, err := decimal.NewFromString(ticker.Price)
 	if err != nil {
-		return 0, fmt.Errorf("failed to parse price: %w", err)
+		return decimal.Zero, fmt.Errorf("failed to parse price: %w", err)
 	}
 
 	return price, nil
@@ -177,7 +189,7 @@ func (ru *RateUpdater) fetchBinancePrice(ctx context.Context, symbol string) (fl
 
 // getBinanceRate fetches exchange rate from Binance
 // First tries direct pair (e.g., BTCETH), if fails, calculates via USDT
-func (ru *RateUpdater) getBinanceRate(ctx context.Context, fromCode, toCode string) (float64, error) {
+func (ru *RateUpdater) getBinanceRate(ctx context.Context, fromCode, toCode string) (decimal.Decimal, error) {
 	// Normalize currency codes to uppercase
 	fromCode = strings.ToUpper(fromCode)
 	toCode = strings.ToUpper(toCode)
@@ -194,10 +206,10 @@ func (ru *RateUpdater) getBinanceRate(ctx context.Context, fromCode, toCode stri
 	reverseSymbol := toCode + fromCode
 	price, err = ru.fetchBinancePrice(ctx, reverseSymbol)
 	if err == nil {
-		if price == 0 {
-			return 0, fmt.Errorf("reverse pair price is zero")
+		if price.IsZero() {
+			return decimal.Zero, fmt.Errorf("reverse pair price is zero")
 		}
-		invertedPrice := 1.0 / price
+		invertedPrice := decimal.NewFromInt(1).DivRound(price, rateScale)
 		ru.log.Debug("Fetched reverse pair from Binance", "symbol", reverseSymbol, "price", price, "inverted", invertedPrice)
 		return invertedPrice, nil
 	}
@@ -209,22 +221,22 @@ func (ru *RateUpdater) getBinanceRate(ctx context.Context, fromCode, toCode stri
 	fromUSDTSymbol := fromCode + "USDT"
 	fromUSDTPrice, err := ru.fetchBinancePrice(ctx, fromUSDTSymbol)
 	if err != nil {
-		return 0, fmt.Errorf("failed to fetch %s: %w", fromUSDTSymbol, err)
+		return decimal.Zero, fmt.Errorf("failed to fetch %s: %w", fromUSDTSymbol, err)
 	}
 
 	// Fetch TO/USDT
 	toUSDTSymbol := toCode + "USDT"
 	toUSDTPrice, err := ru.fetchBinancePrice(ctx, toUSDTSymbol)
 	if err != nil {
-		return 0, fmt.Errorf("failed to fetch %s: %w", toUSDTSymbol, err)
+		return decimal.Zero, fmt.Errorf("failed to fetch %s: %w", toUSDTSymbol, err)
 	}
 
-	if toUSDTPrice == 0 {
-		return 0, fmt.Errorf("to currency USDT price is zero")
+	if toUSDTPrice.IsZero() {
+		return decimal.Zero, fmt.Errorf("to currency USDT price is zero")
 	}
 
 	// Calculate cross rate: (FROM/USDT) / (TO/USDT) = FROM/TO
-	crossRate := fromUSDTPrice / toUSDTPrice
+	crossRate := fromUSDTPrice.DivRound(toUSDTPrice, rateScale)
 
 	return crossRate, nil
 }
@@ -356,6 +368,18 @@ func (ru *RateUpdater) performUpdate(ctx context.Context) error {
 		ru.log.Warn("No rates were fetched successfully from Binance")
 	}
 
+	// 4. Settle limit exchanges against the new rates. Matching failures are logged
+	// rather than returned so they don't trigger a rate refetch.
+	if ru.matcher != nil {
+		executed, expired, err := ru.matcher.MatchPendingExchanges(ctx)
+		if err != nil {
+			ru.log.Error("Failed to match pending exchanges", "error", err)
+		}
+		if executed > 0 || expired > 0 {
+			ru.log.Info("Matched pending exchanges", "executed", executed, "expired", expired)
+		}
+	}
+
 	return nil
 }
 
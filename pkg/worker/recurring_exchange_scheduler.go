@@ -0,0 +1,192 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/caspianex/exchange-backend/internal/domain"
+	"github.com/caspianex/exchange-backend/internal/service"
+	"github.com/caspianex/exchange-backend/pkg/logger"
+)
+
+// RecurringExchangeSchedulerConfig holds configuration for the recurring exchange scheduler
+type RecurringExchangeSchedulerConfig struct {
+	PollInterval time.Duration // How often to look for due schedules
+	PollTimeout  time.Duration // Timeout for one pass over due schedules
+	BatchSize    int           // Upper bound on runs executed per pass
+}
+
+// DefaultRecurringExchangeSchedulerConfig returns sensible defaults
+func DefaultRecurringExchangeSchedulerConfig() RecurringExchangeSchedulerConfig {
+	return RecurringExchangeSchedulerConfig{
+		PollInterval: time.Minute,
+		PollTimeout:  50 * time.Second,
+		BatchSize:    200,
+	}
+}
+
+// RecurringExchangeScheduler is a background worker that executes due recurring exchanges
+type RecurringExchangeScheduler struct {
+	config           RecurringExchangeSchedulerConfig
+	recurringService *service.RecurringExchangeService
+	log              *logger.Logger
+
+	// State management
+	running     atomic.Bool
+	mu          sync.Mutex
+	lastRunTime time.Time
+	lastError   error
+	runCount    uint64
+	failCount   uint64
+
+	// Lifecycle
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewRecurringExchangeScheduler creates a new recurring exchange scheduler worker
+func NewRecurringExchangeScheduler(
+	config RecurringExchangeSchedulerConfig,
+	recurringService *service.RecurringExchangeService,
+	log *logger.Logger,
+) *RecurringExchangeScheduler {
+	return &RecurringExchangeScheduler{
+		config:           config,
+		recurringService: recurringService,
+		log:              log,
+		stopChan:         make(chan struct{}),
+		doneChan:         make(chan struct{}),
+	}
+}
+
+// Start begins polling for due schedules
+func (rs *RecurringExchangeScheduler) Start(ctx context.Context) {
+	if !rs.running.CompareAndSwap(false, true) {
+		rs.log.Warn("Recurring exchange scheduler is already running")
+		return
+	}
+
+	rs.log.Info("Starting recurring exchange scheduler", "interval", rs.config.PollInterval)
+
+	go rs.run(ctx)
+}
+
+// Stop gracefully stops the scheduler
+func (rs *RecurringExchangeScheduler) Stop() {
+	if !rs.running.Load() {
+		return
+	}
+
+	rs.log.Info("Stopping recurring exchange scheduler")
+	close(rs.stopChan)
+
+	select {
+	case <-rs.doneChan:
+		rs.log.Info("Recurring exchange scheduler stopped gracefully")
+	case <-time.After(10 * time.Second):
+		rs.log.Warn("Recurring exchange scheduler stop timeout")
+	}
+}
+
+// run is the main worker loop
+func (rs *RecurringExchangeScheduler) run(ctx context.Context) {
+	defer close(rs.doneChan)
+	defer rs.running.Store(false)
+
+	ticker := time.NewTicker(rs.config.PollInterval)
+	defer ticker.Stop()
+
+	rs.executePass(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			rs.log.Info("Recurring exchange scheduler stopped due to context cancellation")
+			return
+
+		case <-rs.stopChan:
+			rs.log.Info("Recurring exchange scheduler stopped via Stop()")
+			return
+
+		case <-ticker.C:
+			rs.executePass(ctx)
+		}
+	}
+}
+
+// executePass runs every due schedule once. A failing schedule does not hold back the others.
+func (rs *RecurringExchangeScheduler) executePass(parentCtx context.Context) {
+	if !rs.mu.TryLock() {
+		rs.log.Warn("Skipping recurring exchange pass - previous pass still in progress")
+		return
+	}
+	defer rs.mu.Unlock()
+
+	atomic.AddUint64(&rs.runCount, 1)
+
+	ctx, cancel := context.WithTimeout(parentCtx, rs.config.PollTimeout)
+	defer cancel()
+
+	lastErr := rs.runDue(ctx)
+
+	rs.lastRunTime = time.Now()
+	rs.lastError = lastErr
+	if lastErr != nil {
+		atomic.AddUint64(&rs.failCount, 1)
+	}
+}
+
+func (rs *RecurringExchangeScheduler) runDue(ctx context.Context) error {
+	ids, err := rs.recurringService.GetDue(ctx, rs.config.BatchSize)
+	if err != nil {
+		rs.log.Error("Failed to get due recurring exchanges", "error", err)
+		return fmt.Errorf("failed to get due recurring exchanges: %w", err)
+	}
+
+	var lastErr error
+	for _, id := range ids {
+		run, err := rs.recurringService.Run(ctx, id)
+		if err != nil {
+			lastErr = err
+			rs.log.Error("Recurring exchange run failed", "recurring_exchange_id", id, "error", err)
+			continue
+		}
+		if run == nil {
+			continue
+		}
+
+		if run.Status == domain.RecurringExchangeRunStatusSucceeded {
+			rs.log.Info("Recurring exchange executed", "recurring_exchange_id", id, "exchange_id", *run.ExchangeID)
+		} else {
+			rs.log.Warn("Recurring exchange was not executed", "recurring_exchange_id", id, "reason", run.Error)
+		}
+	}
+
+	return lastErr
+}
+
+// Health returns the current health status of the worker
+func (rs *RecurringExchangeScheduler) Health() HealthStatus {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	status := HealthStatus{
+		Running:     rs.running.Load(),
+		LastRunTime: rs.lastRunTime,
+		RunCount:    atomic.LoadUint64(&rs.runCount),
+		FailCount:   atomic.LoadUint64(&rs.failCount),
+	}
+
+	if rs.lastError != nil {
+		status.LastError = rs.lastError.Error()
+	}
+
+	if !rs.lastRunTime.IsZero() {
+		status.Uptime = time.Since(rs.lastRunTime).String()
+	}
+
+	return status
+}